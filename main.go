@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -36,11 +38,6 @@ func main() {
 	}
 	loadEnv(".env."+os.Getenv("ENV")+".local", ".env."+os.Getenv("ENV"), ".env.local", ".env")
 
-	db, err := leveldb.OpenFile("signals-cache.db", nil)
-	if err != nil {
-		log.Fatalf("failed to open signals-cache.db: %v", err)
-	}
-
 	generations := 24
 	if g, ok := os.LookupEnv("SIGNALS_GENERATIONS"); ok {
 		if g, err := strconv.ParseInt(g, 10, 64); err != nil {
@@ -73,6 +70,18 @@ func main() {
 		instrument = i
 	}
 
+	// the registry commands don't open the cache, which live trading holds
+	// open, so models can be promoted and rolled back while it runs
+	if len(os.Args) >= 2 && os.Args[1] == "models" {
+		Models(instrument, os.Args[2:])
+		return
+	}
+
+	db, err := leveldb.OpenFile("signals-cache.db", nil)
+	if err != nil {
+		log.Fatalf("failed to open signals-cache.db: %v", err)
+	}
+
 	tp, sl := model.TakeProfit(), model.StopLoss()
 	leverage := model.Leverage()
 	tm := model.TradeMultiplier()
@@ -82,7 +91,7 @@ func main() {
 			Optimize(db, instrument)
 			return
 		} else if os.Args[1] == "train" {
-			Train(db, instrument)
+			Train(db, instrument, os.Args[2:])
 			return
 		} else {
 			log.Fatalf("unknown command: %s", os.Args[1])
@@ -110,82 +119,151 @@ func main() {
 	if _, err := candles.GetCandles(db, pw, instrument, candles.Network(model.Network()), now.AddDate(-1, 0, 0), now); err != nil {
 		log.Fatalf("error fetching candles: %v", err)
 	}
+	if _, err := model.GetReferences(db, pw, params, now.AddDate(-1, 0, 0), now); err != nil {
+		log.Fatalf("error fetching candles: %v", err)
+	}
 
-	if m, err := model.NewEnsembleModel(context.Background(), db, instrument, params, generationsDuration, generations); err != nil {
-		log.Fatalf("error instantiating ensemble model: %v", err)
+	// saved or promoted models are loaded instead of training an ensemble
+	var m *model.EnsembleModel
+	r := openRegistry()
+	promoted, promotion, err := loadPromoted(db, r, instrument)
+	if err != nil {
+		log.Fatalf("error loading promoted models: %v", err)
+	}
+	ctx, stopTraining := context.WithCancel(context.Background())
+	if filenames, ok := os.LookupEnv("SIGNALS_MODELS"); ok && filenames != "" {
+		m, err = model.LoadEnsembleModel(db, strings.Split(filenames, ","))
+		if err != nil {
+			log.Fatalf("error loading ensemble model: %v", err)
+		}
+		for _, loaded := range m.Models {
+			if loaded.Instrument != instrument {
+				log.Fatalf("error loading ensemble model: model trained on %s, trading %s", loaded.Instrument, instrument)
+			}
+		}
+		if tp, sl, leverage, tm, cooldown, err = orderParams(m.Models); err != nil {
+			log.Fatalf("error loading ensemble model: %v", err)
+		}
+	} else if promoted != nil {
+		log.Printf("running promoted models %s", strings.Join(promotion.IDs, ","))
+		if tp, sl, leverage, tm, cooldown, err = orderParams(promoted); err != nil {
+			log.Fatalf("error loading promoted models: %v", err)
+		}
+		m = &model.EnsembleModel{}
+		if err := m.SetModels(promoted); err != nil {
+			log.Fatalf("error loading promoted models: %v", err)
+		}
 	} else {
+		m, err = model.NewEnsembleModel(ctx, db, instrument, params, generationsDuration, generations)
+		if err != nil {
+			log.Fatalf("error instantiating ensemble model: %v", err)
+		}
+	}
 
-		for {
-			nextTime := time.Now().Add(1 * time.Minute).Truncate(time.Minute)
-			<-time.After(time.Until(nextTime))
-			if strategy, votes, err := m.Predict(nil, nextTime); err != nil {
-				log.Println(err)
-				continue
+	for {
+		nextTime := time.Now().Add(1 * time.Minute).Truncate(time.Minute)
+		<-time.After(time.Until(nextTime))
+
+		// follow promotions and rollbacks made since starting, replacing
+		// saved or trained models once models are promoted
+		if latest, ok, err := r.Promoted(instrument); err != nil {
+			log.Println(err)
+		} else if ok && !slices.Equal(latest.IDs, promotion.IDs) {
+			if models, latest, err := loadPromoted(db, r, instrument); err != nil {
+				log.Printf("error loading promoted models: %v", err)
+			} else if ptp, psl, pleverage, ptm, pcooldown, err := orderParams(models); err != nil {
+				log.Printf("error loading promoted models: %v", err)
 			} else {
-				switch strategy {
-				case model.StrategyHold:
-					log.Printf("strategy: HOLD %s", votes)
-				case model.StrategyLong:
-					log.Printf("strategy: LONG %s", votes)
-				case model.StrategyShort:
-					log.Printf("strategy: SHORT %s", votes)
+				e := &model.EnsembleModel{}
+				if err := e.SetModels(models); err != nil {
+					log.Printf("error loading promoted models: %v", err)
+				} else {
+					log.Printf("running promoted models %s", strings.Join(latest.IDs, ","))
+					stopTraining()
+					m = e
+					promotion = latest
+					tp, sl, leverage, tm, cooldown = ptp, psl, pleverage, ptm, pcooldown
 				}
+			}
+		}
 
-				if hasPositions, positions, err := trade.CheckPositions(context.Background(), instrument); err != nil {
-					log.Println(err)
-					continue
-				} else if hasPositions {
-					for _, position := range positions.Data {
-						if position.InstrumentID == instrument {
-							if upnl, err := strconv.ParseFloat(position.UnrealisedPnL, 64); err != nil {
-								log.Printf("error converting upnl %s to float: %v", position.UnrealisedPnL, err)
-							} else {
-								log.Printf("%s: %s %sx PX %s/%s UPnL %0.02f", instrument, strings.ToUpper(position.PositionSide), position.Leverage, position.Position, position.AveragePrice, upnl)
-							}
+		if strategy, votes, err := m.Predict(nil, nextTime); err != nil {
+			log.Println(err)
+			continue
+		} else {
+			switch strategy {
+			case model.StrategyHold:
+				log.Printf("strategy: HOLD %s", votes)
+			case model.StrategyLong:
+				log.Printf("strategy: LONG %s", votes)
+			case model.StrategyShort:
+				log.Printf("strategy: SHORT %s", votes)
+			}
+
+			if hasPositions, positions, err := trade.CheckPositions(context.Background(), instrument); err != nil {
+				log.Println(err)
+				continue
+			} else if hasPositions {
+				for _, position := range positions.Data {
+					if position.InstrumentID == instrument {
+						if upnl, err := strconv.ParseFloat(position.UnrealisedPnL, 64); err != nil {
+							log.Printf("error converting upnl %s to float: %v", position.UnrealisedPnL, err)
+						} else {
+							log.Printf("%s: %s %sx PX %s/%s UPnL %0.02f", instrument, strings.ToUpper(position.PositionSide), position.Leverage, position.Position, position.AveragePrice, upnl)
 						}
 					}
-				} else if equity, err := trade.GetEquity(context.Background()); err != nil {
-					log.Println(err)
-					continue
-				} else {
-					if votes[model.StrategyLong] > votes[model.StrategyShort] && positions.HasShort(instrument) {
-						for _, position := range positions.Short(instrument) {
-							log.Printf("closing position as more votes for long than short\n%s", position)
-							if err := trade.ClosePosition(instrument, position.Margin, position.PositionSide); err != nil {
-								log.Println(err)
-							}
+				}
+			} else if equity, err := trade.GetEquity(context.Background()); err != nil {
+				log.Println(err)
+				continue
+			} else {
+				if votes[model.StrategyLong] > votes[model.StrategyShort] && positions.HasShort(instrument) {
+					for _, position := range positions.Short(instrument) {
+						log.Printf("closing position as more votes for long than short\n%s", position)
+						if err := trade.ClosePosition(instrument, position.Margin, position.PositionSide); err != nil {
+							log.Println(err)
 						}
 					}
+				}
 
-					if votes[model.StrategyShort] > votes[model.StrategyLong] && positions.HasLong(instrument) {
-						for _, position := range positions.Long(instrument) {
-							log.Printf("closing position as more votes for short than long\n%s", position)
-							if err := trade.ClosePosition(instrument, position.Margin, position.PositionSide); err != nil {
-								log.Println(err)
-							}
+				if votes[model.StrategyShort] > votes[model.StrategyLong] && positions.HasLong(instrument) {
+					for _, position := range positions.Long(instrument) {
+						log.Printf("closing position as more votes for short than long\n%s", position)
+						if err := trade.ClosePosition(instrument, position.Margin, position.PositionSide); err != nil {
+							log.Println(err)
 						}
 					}
+				}
 
-					if notBefore.Before(time.Now()) {
-						switch strategy {
-						case model.StrategyLong:
-							if order, err := trade.PlaceOrder(context.Background(), instrument, true, equity, tp/tm, sl*tm, leverage); err != nil {
-								log.Println(err)
-								continue
-							} else {
-								log.Printf("placed LONG market order: %s %s", order.Instrument, order.OrderID)
-								notBefore = time.Now().Add(cooldown)
-								log.Printf("cooling down, next trade %s", notBefore)
-							}
-						case model.StrategyShort:
-							if order, err := trade.PlaceOrder(context.Background(), instrument, false, equity, tp/tm, sl*tm, leverage); err != nil {
-								log.Println(err)
-								continue
-							} else {
-								log.Printf("placed SHORT market order: %s %s", order.Instrument, order.OrderID)
-								notBefore = time.Now().Add(cooldown)
-								log.Printf("cooling down, next trade %s", notBefore)
-							}
+				// volatility barriers scale the take profit and stop loss of each
+				// trade, as they did in training and backtests
+				scale := 1.0
+				if strategy != model.StrategyHold {
+					if scale, err = m.BarrierScale(nil, nextTime); err != nil {
+						log.Println(err)
+						continue
+					}
+				}
+
+				if notBefore.Before(time.Now()) {
+					switch strategy {
+					case model.StrategyLong:
+						if order, err := trade.PlaceOrder(context.Background(), instrument, true, equity, scale*tp/tm, scale*sl*tm, leverage); err != nil {
+							log.Println(err)
+							continue
+						} else {
+							log.Printf("placed LONG market order: %s %s", order.Instrument, order.OrderID)
+							notBefore = time.Now().Add(cooldown)
+							log.Printf("cooling down, next trade %s", notBefore)
+						}
+					case model.StrategyShort:
+						if order, err := trade.PlaceOrder(context.Background(), instrument, false, equity, scale*tp/tm, scale*sl*tm, leverage); err != nil {
+							log.Println(err)
+							continue
+						} else {
+							log.Printf("placed SHORT market order: %s %s", order.Instrument, order.OrderID)
+							notBefore = time.Now().Add(cooldown)
+							log.Printf("cooling down, next trade %s", notBefore)
 						}
 					}
 				}
@@ -194,7 +272,13 @@ func main() {
 	}
 }
 
-func Train(db *leveldb.DB, instrument string) {
+func Train(db *leveldb.DB, instrument string, args []string) {
+	flags := flag.NewFlagSet("train", flag.ExitOnError)
+	out := flags.String("out", "", "save the trained model to this file")
+	register := flags.Bool("register", false, "add the trained model to the model registry")
+	tags := flags.String("tags", "", "comma separated tags for the registered model")
+	flags.Parse(args)
+
 	params := model.NewModelParamsFromDefaults()
 	params.Write(os.Stdout, "Model Config", false)
 
@@ -221,6 +305,23 @@ func Train(db *leveldb.DB, instrument string) {
 		}
 
 		m.Metrics.Write(os.Stdout)
+
+		if *out != "" {
+			if err := m.SaveFile(*out); err != nil {
+				log.Fatalf("error saving model: %v", err)
+			}
+			log.Printf("saved model to %s", *out)
+		}
+
+		if *register {
+			entry, err := openRegistry().Add(m, slices.DeleteFunc(strings.Split(*tags, ","), func(tag string) bool {
+				return tag == ""
+			}))
+			if err != nil {
+				log.Fatalf("error registering model: %v", err)
+			}
+			log.Printf("registered model %s", entry.ID)
+		}
 	}
 }
 
@@ -242,6 +343,9 @@ func Optimize(db *leveldb.DB, instrument string) {
 	if _, err := candles.GetCandles(db, pw, instrument, candles.Network(model.Network()), now.AddDate(-1, 0, 0), now); err != nil {
 		log.Fatalf("error fetching candles: %v", err)
 	}
+	if _, err := model.GetReferences(db, pw, model.NewModelParamsFromDefaults(), now.AddDate(-1, 0, 0), now); err != nil {
+		log.Fatalf("error fetching candles: %v", err)
+	}
 
 	populationSize := 50
 	if v, ok := os.LookupEnv("SIGNALS_OPTIMIZER_POPULATION_SIZE"); ok {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grexie/signals/pkg/model"
+	"github.com/grexie/signals/pkg/registry"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func openRegistry() *registry.Registry {
+	dir := "signals-models"
+	if d, ok := os.LookupEnv("SIGNALS_REGISTRY"); ok {
+		dir = d
+	}
+
+	r, err := registry.Open(dir)
+	if err != nil {
+		log.Fatalf("failed to open model registry %s: %v", dir, err)
+	}
+	return r
+}
+
+// loadPromoted loads the models promoted for the instrument, returning nil
+// if there are none
+func loadPromoted(db *leveldb.DB, r *registry.Registry, instrument string) ([]*model.Model, registry.Promotion, error) {
+	promotion, ok, err := r.Promoted(instrument)
+	if err != nil || !ok {
+		return nil, promotion, err
+	}
+
+	models := []*model.Model{}
+	for _, id := range promotion.IDs {
+		m, err := r.Load(db, id)
+		if err != nil {
+			return nil, promotion, err
+		}
+		models = append(models, m)
+	}
+	return models, promotion, nil
+}
+
+// orderParams returns the take profit, stop loss, leverage, trade
+// multiplier and cooldown the models were backtested with, for orders to
+// trade as the models were evaluated rather than with the environment's
+// settings
+func orderParams(models []*model.Model) (tp, sl, leverage, tm float64, cooldown time.Duration, err error) {
+	p := models[0].Params()
+	for _, m := range models[1:] {
+		q := m.Params()
+		if q.TakeProfit != p.TakeProfit || q.StopLoss != p.StopLoss || q.Leverage != p.Leverage || q.TradeMultiplier != p.TradeMultiplier ||
+			q.Cooldown != p.Cooldown || q.VolatilityBarriers != p.VolatilityBarriers || q.BarrierVolatilityWindow != p.BarrierVolatilityWindow {
+			return 0, 0, 0, 0, 0, fmt.Errorf("models trade with different take profit, stop loss, leverage, trade multiplier, cooldown or volatility barriers")
+		}
+	}
+	return p.TakeProfit * p.Leverage, p.StopLoss * p.Leverage, p.Leverage, p.TradeMultiplier, p.Cooldown, nil
+}
+
+func Models(instrument string, args []string) {
+	usage := "usage: signals models list|show <id>|promote <id>...|rollback|diff <id> <id>"
+	if len(args) == 0 {
+		log.Fatal(usage)
+	}
+
+	r := openRegistry()
+	promotion, _, err := r.Promoted(instrument)
+	if err != nil {
+		log.Fatalf("error reading promotions: %v", err)
+	}
+
+	switch args[0] {
+	case "list":
+		entries, err := r.List()
+		if err != nil {
+			log.Fatalf("error listing models: %v", err)
+		}
+		entries = slices.DeleteFunc(entries, func(entry registry.Entry) bool {
+			return entry.Instrument != instrument
+		})
+		registry.WriteList(os.Stdout, entries, promotion.IDs)
+
+	case "show":
+		if len(args) != 2 {
+			log.Fatal(usage)
+		}
+		entry, err := r.Get(args[1])
+		if err != nil {
+			log.Fatalf("error reading model: %v", err)
+		}
+		registry.WriteEntry(os.Stdout, entry)
+
+	case "promote":
+		if len(args) < 2 {
+			log.Fatal(usage)
+		}
+		promotion, err := r.Promote(args[1:])
+		if err != nil {
+			log.Fatalf("error promoting models: %v", err)
+		}
+		fmt.Printf("promoted %s\n", strings.Join(promotion.IDs, ","))
+
+	case "rollback":
+		promotion, err := r.Rollback(instrument)
+		if err != nil {
+			log.Fatalf("error rolling back: %v", err)
+		}
+		fmt.Printf("rolled back to %s, promoted %s\n", strings.Join(promotion.IDs, ","), promotion.Created.Local().Format("2006-01-02 15:04:05"))
+
+	case "diff":
+		if len(args) != 3 {
+			log.Fatal(usage)
+		}
+		a, err := r.Get(args[1])
+		if err != nil {
+			log.Fatalf("error reading model: %v", err)
+		}
+		b, err := r.Get(args[2])
+		if err != nil {
+			log.Fatalf("error reading model: %v", err)
+		}
+		registry.WriteDiff(os.Stdout, a, b)
+
+	default:
+		log.Fatalf("unknown models command: %s\n%s", args[0], usage)
+	}
+}
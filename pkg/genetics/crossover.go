@@ -67,5 +67,77 @@ func crossover(parent1, parent2 Strategy) Strategy {
 		RSIUpperBound:              selectValue(parent1.RSIUpperBound, parent2.RSIUpperBound),
 		RSILowerBound:              selectValue(parent1.RSILowerBound, parent2.RSILowerBound),
 		RSISlope:                   selectValue(parent1.RSISlope, parent2.RSISlope),
+
+		ADXPeriod:                 selectValue(parent1.ADXPeriod, parent2.ADXPeriod),
+		ParabolicSARStep:          selectValue(parent1.ParabolicSARStep, parent2.ParabolicSARStep),
+		ParabolicSARMaxStep:       selectValue(parent1.ParabolicSARMaxStep, parent2.ParabolicSARMaxStep),
+		IchimokuConversionPeriod:  selectValue(parent1.IchimokuConversionPeriod, parent2.IchimokuConversionPeriod),
+		IchimokuBasePeriod:        selectValue(parent1.IchimokuBasePeriod, parent2.IchimokuBasePeriod),
+		IchimokuSpanPeriod:        selectValue(parent1.IchimokuSpanPeriod, parent2.IchimokuSpanPeriod),
+		SuperTrendPeriod:          selectValue(parent1.SuperTrendPeriod, parent2.SuperTrendPeriod),
+		SuperTrendMultiplier:      selectValue(parent1.SuperTrendMultiplier, parent2.SuperTrendMultiplier),
+		KeltnerChannelsPeriod:     selectValue(parent1.KeltnerChannelsPeriod, parent2.KeltnerChannelsPeriod),
+		KeltnerChannelsMultiplier: selectValue(parent1.KeltnerChannelsMultiplier, parent2.KeltnerChannelsMultiplier),
+		DonchianChannelsPeriod:    selectValue(parent1.DonchianChannelsPeriod, parent2.DonchianChannelsPeriod),
+
+		SessionVWAPMultiplier:  selectValue(parent1.SessionVWAPMultiplier, parent2.SessionVWAPMultiplier),
+		VolumeProfileWindow:    selectValue(parent1.VolumeProfileWindow, parent2.VolumeProfileWindow),
+		VolumeProfileBinWidth:  selectValue(parent1.VolumeProfileBinWidth, parent2.VolumeProfileBinWidth),
+		VolumeProfileValueArea: selectValue(parent1.VolumeProfileValueArea, parent2.VolumeProfileValueArea),
+
+		StatisticsWindow:       selectValue(parent1.StatisticsWindow, parent2.StatisticsWindow),
+		AutocorrelationLag:     selectValue(parent1.AutocorrelationLag, parent2.AutocorrelationLag),
+		SampleEntropyDimension: selectValue(parent1.SampleEntropyDimension, parent2.SampleEntropyDimension),
+		SampleEntropyTolerance: selectValue(parent1.SampleEntropyTolerance, parent2.SampleEntropyTolerance),
+
+		ReferenceWindow: selectValue(parent1.ReferenceWindow, parent2.ReferenceWindow),
+		ReferenceLag:    selectValue(parent1.ReferenceLag, parent2.ReferenceLag),
+
+		RegimeWindow:              selectValue(parent1.RegimeWindow, parent2.RegimeWindow),
+		RegimeLookback:            selectValue(parent1.RegimeLookback, parent2.RegimeLookback),
+		RegimeTrendThreshold:      selectValue(parent1.RegimeTrendThreshold, parent2.RegimeTrendThreshold),
+		RegimeVolatilityThreshold: selectValue(parent1.RegimeVolatilityThreshold, parent2.RegimeVolatilityThreshold),
+
+		FracDiffWindow: selectValue(parent1.FracDiffWindow, parent2.FracDiffWindow),
+
+		SwingStrength:      selectValue(parent1.SwingStrength, parent2.SwingStrength),
+		DivergenceLookback: selectValue(parent1.DivergenceLookback, parent2.DivergenceLookback),
+
+		SupportResistanceLookback:  selectValue(parent1.SupportResistanceLookback, parent2.SupportResistanceLookback),
+		SupportResistanceTolerance: selectValue(parent1.SupportResistanceTolerance, parent2.SupportResistanceTolerance),
+		SupportResistanceTouches:   selectValue(parent1.SupportResistanceTouches, parent2.SupportResistanceTouches),
+
+		Architecture:   selectChoice(parent1.Architecture, parent2.Architecture),
+		SequenceLength: selectValue(parent1.SequenceLength, parent2.SequenceLength),
+		BPTTSteps:      selectValue(parent1.BPTTSteps, parent2.BPTTSteps),
+
+		TCNKernelSize: selectValue(parent1.TCNKernelSize, parent2.TCNKernelSize),
+
+		GBDTTrees:     selectValue(parent1.GBDTTrees, parent2.GBDTTrees),
+		GBDTDepth:     selectValue(parent1.GBDTDepth, parent2.GBDTDepth),
+		GBDTLearnRate: selectValue(parent1.GBDTLearnRate, parent2.GBDTLearnRate),
+		GBDTSubsample: selectValue(parent1.GBDTSubsample, parent2.GBDTSubsample),
+		GBDTL2:        selectValue(parent1.GBDTL2, parent2.GBDTL2),
+
+		HiddenLayers:     selectValue(parent1.HiddenLayers, parent2.HiddenLayers),
+		HiddenLayerDecay: selectValue(parent1.HiddenLayerDecay, parent2.HiddenLayerDecay),
+		Activation:       selectChoice(parent1.Activation, parent2.Activation),
+		Normalization:    selectChoice(parent1.Normalization, parent2.Normalization),
+		Residual:         selectChoice(parent1.Residual, parent2.Residual),
+
+		ClassImbalance:     selectChoice(parent1.ClassImbalance, parent2.ClassImbalance),
+		FocalGamma:         selectValue(parent1.FocalGamma, parent2.FocalGamma),
+		ImbalanceNeighbors: selectValue(parent1.ImbalanceNeighbors, parent2.ImbalanceNeighbors),
+
+		BarrierVolatilityWindow: selectValue(parent1.BarrierVolatilityWindow, parent2.BarrierVolatilityWindow),
+	}
+}
+
+// selectChoice inherits a gene that's chosen rather than scaled from either
+// parent
+func selectChoice[T any](a, b T) T {
+	if rand.Float64() < 0.5 {
+		return a
 	}
+	return b
 }
@@ -103,6 +103,106 @@ func WriteCSVHeader(writer *csv.Writer) error {
 		"SIGNALS_RSI_UPPER_BOUND (Best Strategy)",
 		"SIGNALS_RSI_LOWER_BOUND (Best Strategy)",
 		"SIGNALS_RSI_SLOPE (Best Strategy)",
+
+		"SIGNALS_RSI_SMOOTHING (Best Strategy)",
+		"SIGNALS_MACD_SMOOTHING (Best Strategy)",
+		"SIGNALS_ATR_SMOOTHING (Best Strategy)",
+		"SIGNALS_BOLLINGER_BANDS_SMOOTHING (Best Strategy)",
+
+		"SIGNALS_ADX_ENABLED (Best Strategy)",
+		"SIGNALS_ADX_PERIOD (Best Strategy)",
+		"SIGNALS_PARABOLIC_SAR_ENABLED (Best Strategy)",
+		"SIGNALS_PARABOLIC_SAR_STEP (Best Strategy)",
+		"SIGNALS_PARABOLIC_SAR_MAX_STEP (Best Strategy)",
+		"SIGNALS_ICHIMOKU_ENABLED (Best Strategy)",
+		"SIGNALS_ICHIMOKU_CONVERSION_PERIOD (Best Strategy)",
+		"SIGNALS_ICHIMOKU_BASE_PERIOD (Best Strategy)",
+		"SIGNALS_ICHIMOKU_SPAN_PERIOD (Best Strategy)",
+		"SIGNALS_SUPERTREND_ENABLED (Best Strategy)",
+		"SIGNALS_SUPERTREND_PERIOD (Best Strategy)",
+		"SIGNALS_SUPERTREND_MULTIPLIER (Best Strategy)",
+		"SIGNALS_KELTNER_CHANNELS_ENABLED (Best Strategy)",
+		"SIGNALS_KELTNER_CHANNELS_PERIOD (Best Strategy)",
+		"SIGNALS_KELTNER_CHANNELS_MULTIPLIER (Best Strategy)",
+		"SIGNALS_DONCHIAN_CHANNELS_ENABLED (Best Strategy)",
+		"SIGNALS_DONCHIAN_CHANNELS_PERIOD (Best Strategy)",
+
+		"SIGNALS_SESSION_VWAP_ENABLED (Best Strategy)",
+		"SIGNALS_SESSION_VWAP_ANCHOR (Best Strategy)",
+		"SIGNALS_SESSION_VWAP_MULTIPLIER (Best Strategy)",
+		"SIGNALS_VOLUME_PROFILE_ENABLED (Best Strategy)",
+		"SIGNALS_VOLUME_PROFILE_WINDOW (Best Strategy)",
+		"SIGNALS_VOLUME_PROFILE_BIN_WIDTH (Best Strategy)",
+		"SIGNALS_VOLUME_PROFILE_VALUE_AREA (Best Strategy)",
+
+		"SIGNALS_CANDLE_PATTERNS (Best Strategy)",
+
+		"SIGNALS_INVALID_ROWS (Best Strategy)",
+
+		"SIGNALS_STATISTICS_ENABLED (Best Strategy)",
+		"SIGNALS_STATISTICS_WINDOW (Best Strategy)",
+		"SIGNALS_AUTOCORRELATION_LAG (Best Strategy)",
+		"SIGNALS_SAMPLE_ENTROPY_DIMENSION (Best Strategy)",
+		"SIGNALS_SAMPLE_ENTROPY_TOLERANCE (Best Strategy)",
+
+		"SIGNALS_REFERENCE_INSTRUMENTS (Best Strategy)",
+		"SIGNALS_REFERENCE_WINDOW (Best Strategy)",
+		"SIGNALS_REFERENCE_LAG (Best Strategy)",
+
+		"SIGNALS_CALENDAR_ENABLED (Best Strategy)",
+		"SIGNALS_FUNDING_SCHEDULE (Best Strategy)",
+
+		"SIGNALS_REGIME_ENABLED (Best Strategy)",
+		"SIGNALS_REGIME_WINDOW (Best Strategy)",
+		"SIGNALS_REGIME_LOOKBACK (Best Strategy)",
+		"SIGNALS_REGIME_TREND_THRESHOLD (Best Strategy)",
+		"SIGNALS_REGIME_VOLATILITY_THRESHOLD (Best Strategy)",
+		"SIGNALS_REGIME_BLACKLIST (Best Strategy)",
+
+		"SIGNALS_FRACDIFF_ENABLED (Best Strategy)",
+		"SIGNALS_FRACDIFF_ORDER (Best Strategy)",
+		"SIGNALS_FRACDIFF_WINDOW (Best Strategy)",
+
+		"SIGNALS_DIVERGENCE_ENABLED (Best Strategy)",
+		"SIGNALS_DIVERGENCE_CONFIRMATION (Best Strategy)",
+		"SIGNALS_SWING_STRENGTH (Best Strategy)",
+		"SIGNALS_DIVERGENCE_LOOKBACK (Best Strategy)",
+
+		"SIGNALS_LEVELS_ENABLED (Best Strategy)",
+		"SIGNALS_PIVOT_METHOD (Best Strategy)",
+		"SIGNALS_PIVOT_ANCHOR (Best Strategy)",
+		"SIGNALS_SUPPORT_RESISTANCE_LOOKBACK (Best Strategy)",
+		"SIGNALS_SUPPORT_RESISTANCE_TOLERANCE (Best Strategy)",
+		"SIGNALS_SUPPORT_RESISTANCE_TOUCHES (Best Strategy)",
+		"SIGNALS_TRADE_LEVELS (Best Strategy)",
+
+		"SIGNALS_TIMEFRAME_FEATURES (Best Strategy)",
+
+		"SIGNALS_ARCHITECTURE (Best Strategy)",
+		"SIGNALS_SEQUENCE_LENGTH (Best Strategy)",
+		"SIGNALS_BPTT_STEPS (Best Strategy)",
+
+		"SIGNALS_TCN_KERNEL_SIZE (Best Strategy)",
+
+		"SIGNALS_GBDT_TREES (Best Strategy)",
+		"SIGNALS_GBDT_DEPTH (Best Strategy)",
+		"SIGNALS_GBDT_LEARN_RATE (Best Strategy)",
+		"SIGNALS_GBDT_SUBSAMPLE (Best Strategy)",
+		"SIGNALS_GBDT_L2 (Best Strategy)",
+
+		"SIGNALS_HIDDEN_LAYERS (Best Strategy)",
+		"SIGNALS_HIDDEN_LAYER_DECAY (Best Strategy)",
+		"SIGNALS_ACTIVATION (Best Strategy)",
+		"SIGNALS_NORMALIZATION (Best Strategy)",
+		"SIGNALS_RESIDUAL (Best Strategy)",
+
+		"SIGNALS_CLASS_IMBALANCE (Best Strategy)",
+		"SIGNALS_FOCAL_GAMMA (Best Strategy)",
+		"SIGNALS_IMBALANCE_NEIGHBORS (Best Strategy)",
+
+		"SIGNALS_LABELING (Best Strategy)",
+		"SIGNALS_VOLATILITY_BARRIERS (Best Strategy)",
+		"SIGNALS_BARRIER_VOLATILITY_WINDOW (Best Strategy)",
 	}
 
 	if err := writer.Write(header); err != nil {
@@ -193,6 +293,106 @@ func WriteCSVRow(writer *csv.Writer, generation int, started time.Time, ended ti
 		fmt.Sprintf("%0.02f", params.RSIUpperBound),
 		fmt.Sprintf("%0.02f", params.RSILowerBound),
 		fmt.Sprintf("%d", params.RSISlope),
+
+		string(params.RSISmoothing),
+		string(params.MACDSmoothing),
+		string(params.ATRSmoothing),
+		string(params.BollingerBandsSmoothing),
+
+		fmt.Sprintf("%t", params.ADXEnabled),
+		fmt.Sprintf("%d", params.ADXPeriod),
+		fmt.Sprintf("%t", params.ParabolicSAREnabled),
+		fmt.Sprintf("%0.04f", params.ParabolicSARStep),
+		fmt.Sprintf("%0.04f", params.ParabolicSARMaxStep),
+		fmt.Sprintf("%t", params.IchimokuEnabled),
+		fmt.Sprintf("%d", params.IchimokuConversionPeriod),
+		fmt.Sprintf("%d", params.IchimokuBasePeriod),
+		fmt.Sprintf("%d", params.IchimokuSpanPeriod),
+		fmt.Sprintf("%t", params.SuperTrendEnabled),
+		fmt.Sprintf("%d", params.SuperTrendPeriod),
+		fmt.Sprintf("%0.02f", params.SuperTrendMultiplier),
+		fmt.Sprintf("%t", params.KeltnerChannelsEnabled),
+		fmt.Sprintf("%d", params.KeltnerChannelsPeriod),
+		fmt.Sprintf("%0.02f", params.KeltnerChannelsMultiplier),
+		fmt.Sprintf("%t", params.DonchianChannelsEnabled),
+		fmt.Sprintf("%d", params.DonchianChannelsPeriod),
+
+		fmt.Sprintf("%t", params.SessionVWAPEnabled),
+		params.SessionVWAPAnchor.String(),
+		fmt.Sprintf("%0.02f", params.SessionVWAPMultiplier),
+		fmt.Sprintf("%t", params.VolumeProfileEnabled),
+		fmt.Sprintf("%d", params.VolumeProfileWindow),
+		fmt.Sprintf("%0.04f", params.VolumeProfileBinWidth),
+		fmt.Sprintf("%0.02f", params.VolumeProfileValueArea),
+
+		params.CandlePatterns.String(),
+
+		string(params.InvalidRows),
+
+		fmt.Sprintf("%t", params.StatisticsEnabled),
+		fmt.Sprintf("%d", params.StatisticsWindow),
+		fmt.Sprintf("%d", params.AutocorrelationLag),
+		fmt.Sprintf("%d", params.SampleEntropyDimension),
+		fmt.Sprintf("%0.02f", params.SampleEntropyTolerance),
+
+		params.ReferenceInstruments.String(),
+		fmt.Sprintf("%d", params.ReferenceWindow),
+		fmt.Sprintf("%d", params.ReferenceLag),
+
+		fmt.Sprintf("%t", params.CalendarEnabled),
+		params.FundingSchedule.String(),
+
+		fmt.Sprintf("%t", params.RegimeEnabled),
+		fmt.Sprintf("%d", params.RegimeWindow),
+		fmt.Sprintf("%d", params.RegimeLookback),
+		fmt.Sprintf("%0.02f", params.RegimeTrendThreshold),
+		fmt.Sprintf("%0.02f", params.RegimeVolatilityThreshold),
+		params.RegimeBlacklist.String(),
+
+		fmt.Sprintf("%t", params.FracDiffEnabled),
+		fmt.Sprintf("%0.02f", params.FracDiffOrder),
+		fmt.Sprintf("%d", params.FracDiffWindow),
+
+		fmt.Sprintf("%t", params.DivergenceEnabled),
+		fmt.Sprintf("%t", params.DivergenceConfirmation),
+		fmt.Sprintf("%d", params.SwingStrength),
+		fmt.Sprintf("%d", params.DivergenceLookback),
+
+		fmt.Sprintf("%t", params.LevelsEnabled),
+		params.PivotMethod.String(),
+		params.PivotAnchor.String(),
+		fmt.Sprintf("%d", params.SupportResistanceLookback),
+		fmt.Sprintf("%0.04f", params.SupportResistanceTolerance),
+		fmt.Sprintf("%d", params.SupportResistanceTouches),
+		string(params.TradeLevels),
+
+		params.TimeframeFeatures.String(),
+
+		string(params.Architecture),
+		fmt.Sprintf("%d", params.SequenceLength),
+		fmt.Sprintf("%d", params.BPTTSteps),
+
+		fmt.Sprintf("%d", params.TCNKernelSize),
+
+		fmt.Sprintf("%d", params.GBDTTrees),
+		fmt.Sprintf("%d", params.GBDTDepth),
+		fmt.Sprintf("%0.03f", params.GBDTLearnRate),
+		fmt.Sprintf("%0.02f", params.GBDTSubsample),
+		fmt.Sprintf("%0.02f", params.GBDTL2),
+
+		fmt.Sprintf("%d", params.HiddenLayers),
+		fmt.Sprintf("%0.02f", params.HiddenLayerDecay),
+		string(params.Activation),
+		string(params.Normalization),
+		fmt.Sprintf("%t", params.Residual),
+
+		string(params.ClassImbalance),
+		fmt.Sprintf("%0.02f", params.FocalGamma),
+		fmt.Sprintf("%d", params.ImbalanceNeighbors),
+
+		string(params.Labeling),
+		fmt.Sprintf("%t", params.VolatilityBarriers),
+		fmt.Sprintf("%d", params.BarrierVolatilityWindow),
 	}
 
 	if err := writer.Write(row); err != nil {
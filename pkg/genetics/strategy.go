@@ -48,6 +48,71 @@ type Strategy struct {
 	RSILowerBound              float64
 	RSISlope                   float64
 
+	ADXPeriod                 float64
+	ParabolicSARStep          float64
+	ParabolicSARMaxStep       float64
+	IchimokuConversionPeriod  float64
+	IchimokuBasePeriod        float64
+	IchimokuSpanPeriod        float64
+	SuperTrendPeriod          float64
+	SuperTrendMultiplier      float64
+	KeltnerChannelsPeriod     float64
+	KeltnerChannelsMultiplier float64
+	DonchianChannelsPeriod    float64
+
+	SessionVWAPMultiplier  float64
+	VolumeProfileWindow    float64
+	VolumeProfileBinWidth  float64
+	VolumeProfileValueArea float64
+
+	StatisticsWindow       float64
+	AutocorrelationLag     float64
+	SampleEntropyDimension float64
+	SampleEntropyTolerance float64
+
+	ReferenceWindow float64
+	ReferenceLag    float64
+
+	RegimeWindow              float64
+	RegimeLookback            float64
+	RegimeTrendThreshold      float64
+	RegimeVolatilityThreshold float64
+
+	FracDiffWindow float64
+
+	SwingStrength      float64
+	DivergenceLookback float64
+
+	SupportResistanceLookback  float64
+	SupportResistanceTolerance float64
+	SupportResistanceTouches   float64
+
+	// Architecture, Activation, Normalization, Residual and ClassImbalance
+	// are chosen rather than scaled like the other genes
+	Architecture   model.ModelArchitecture
+	SequenceLength float64
+	BPTTSteps      float64
+
+	TCNKernelSize float64
+
+	GBDTTrees     float64
+	GBDTDepth     float64
+	GBDTLearnRate float64
+	GBDTSubsample float64
+	GBDTL2        float64
+
+	HiddenLayers     float64
+	HiddenLayerDecay float64
+	Activation       model.ActivationFunction
+	Normalization    model.LayerNormalization
+	Residual         bool
+
+	ClassImbalance     model.ImbalanceStrategy
+	FocalGamma         float64
+	ImbalanceNeighbors float64
+
+	BarrierVolatilityWindow float64
+
 	BatchSizeLog2       float64
 	HiddenLayerSizeLog2 float64
 	L2Penalty           float64
@@ -107,6 +172,69 @@ func newStrategy(instrument string) Strategy {
 		RSILowerBound:              model.BoundRSILowerBound(float64(model.RSILowerBound())),
 		RSISlope:                   model.BoundRSISlopeFloat64(float64(model.RSISlope())),
 
+		ADXPeriod:                 model.BoundADXPeriodFloat64(float64(model.ADXPeriod())),
+		ParabolicSARStep:          model.BoundParabolicSARStep(model.ParabolicSARStep()),
+		ParabolicSARMaxStep:       model.BoundParabolicSARMaxStep(model.ParabolicSARMaxStep()),
+		IchimokuConversionPeriod:  model.BoundIchimokuConversionPeriodFloat64(float64(model.IchimokuConversionPeriod())),
+		IchimokuBasePeriod:        model.BoundIchimokuBasePeriodFloat64(float64(model.IchimokuBasePeriod())),
+		IchimokuSpanPeriod:        model.BoundIchimokuSpanPeriodFloat64(float64(model.IchimokuSpanPeriod())),
+		SuperTrendPeriod:          model.BoundSuperTrendPeriodFloat64(float64(model.SuperTrendPeriod())),
+		SuperTrendMultiplier:      model.BoundSuperTrendMultiplier(model.SuperTrendMultiplier()),
+		KeltnerChannelsPeriod:     model.BoundKeltnerChannelsPeriodFloat64(float64(model.KeltnerChannelsPeriod())),
+		KeltnerChannelsMultiplier: model.BoundKeltnerChannelsMultiplier(model.KeltnerChannelsMultiplier()),
+		DonchianChannelsPeriod:    model.BoundDonchianChannelsPeriodFloat64(float64(model.DonchianChannelsPeriod())),
+
+		SessionVWAPMultiplier:  model.BoundSessionVWAPMultiplier(model.SessionVWAPMultiplier()),
+		VolumeProfileWindow:    model.BoundVolumeProfileWindowFloat64(float64(model.VolumeProfileWindow())),
+		VolumeProfileBinWidth:  model.BoundVolumeProfileBinWidth(model.VolumeProfileBinWidth()),
+		VolumeProfileValueArea: model.BoundVolumeProfileValueArea(model.VolumeProfileValueArea()),
+
+		StatisticsWindow:       model.BoundStatisticsWindowFloat64(float64(model.StatisticsWindow())),
+		AutocorrelationLag:     model.BoundAutocorrelationLagFloat64(float64(model.AutocorrelationLag())),
+		SampleEntropyDimension: model.BoundSampleEntropyDimensionFloat64(float64(model.SampleEntropyDimension())),
+		SampleEntropyTolerance: model.BoundSampleEntropyTolerance(model.SampleEntropyTolerance()),
+
+		ReferenceWindow: model.BoundReferenceWindowFloat64(float64(model.ReferenceWindow())),
+		ReferenceLag:    model.BoundReferenceLagFloat64(float64(model.ReferenceLag())),
+
+		RegimeWindow:              model.BoundRegimeWindowFloat64(float64(model.RegimeWindow())),
+		RegimeLookback:            model.BoundRegimeLookbackFloat64(float64(model.RegimeLookback())),
+		RegimeTrendThreshold:      model.BoundRegimeTrendThreshold(model.RegimeTrendThreshold()),
+		RegimeVolatilityThreshold: model.BoundRegimeVolatilityThreshold(model.RegimeVolatilityThreshold()),
+
+		FracDiffWindow: model.BoundFracDiffWindowFloat64(float64(model.FracDiffWindow())),
+
+		SwingStrength:      model.BoundSwingStrengthFloat64(float64(model.SwingStrength())),
+		DivergenceLookback: model.BoundDivergenceLookbackFloat64(float64(model.DivergenceLookback())),
+
+		SupportResistanceLookback:  model.BoundSupportResistanceLookbackFloat64(float64(model.SupportResistanceLookback())),
+		SupportResistanceTolerance: model.BoundSupportResistanceTolerance(model.SupportResistanceTolerance()),
+		SupportResistanceTouches:   model.BoundSupportResistanceTouchesFloat64(float64(model.SupportResistanceTouches())),
+
+		Architecture:   model.Architecture(),
+		SequenceLength: model.BoundSequenceLengthFloat64(float64(model.SequenceLength())),
+		BPTTSteps:      model.BoundBPTTStepsFloat64(float64(model.BPTTSteps())),
+
+		TCNKernelSize: model.BoundTCNKernelSizeFloat64(float64(model.TCNKernelSize())),
+
+		GBDTTrees:     model.BoundGBDTTreesFloat64(float64(model.GBDTTrees())),
+		GBDTDepth:     model.BoundGBDTDepthFloat64(float64(model.GBDTDepth())),
+		GBDTLearnRate: model.BoundGBDTLearnRate(model.GBDTLearnRate()),
+		GBDTSubsample: model.BoundGBDTSubsample(model.GBDTSubsample()),
+		GBDTL2:        model.BoundGBDTL2(model.GBDTL2()),
+
+		HiddenLayers:     model.BoundHiddenLayersFloat64(float64(model.HiddenLayers())),
+		HiddenLayerDecay: model.BoundHiddenLayerDecay(model.HiddenLayerDecay()),
+		Activation:       model.Activation(),
+		Normalization:    model.Normalization(),
+		Residual:         model.Residual(),
+
+		ClassImbalance:     model.ClassImbalance(),
+		FocalGamma:         model.BoundFocalGamma(model.FocalGamma()),
+		ImbalanceNeighbors: model.BoundImbalanceNeighborsFloat64(float64(model.ImbalanceNeighbors())),
+
+		BarrierVolatilityWindow: model.BoundBarrierVolatilityWindowFloat64(float64(model.BarrierVolatilityWindow())),
+
 		L2Penalty:   model.BoundL2Penalty(model.L2Penalty()),
 		DropoutRate: model.BoundDropoutRate(model.DropoutRate()),
 		LearnRate:   model.BoundLearnRate(model.LearnRate()),
@@ -114,6 +242,15 @@ func newStrategy(instrument string) Strategy {
 	}
 }
 
+// mutateChoice switches a gene that's chosen rather than scaled to a random
+// choice with a chance of percent%
+func mutateChoice[T any](value T, choices []T, percent float64) T {
+	if rand.Float64()*100 < percent {
+		return choices[rand.IntN(len(choices))]
+	}
+	return value
+}
+
 func randomizeStrategy(s *Strategy, percent float64) {
 	s.WindowSize = model.BoundWindowSizeFloat64(s.WindowSize * randPercent(percent))
 	s.Candles = model.BoundCandlesFloat64(s.Candles * randPercent(percent))
@@ -153,6 +290,69 @@ func randomizeStrategy(s *Strategy, percent float64) {
 	s.RSILowerBound = model.BoundRSILowerBound(s.RSILowerBound * randPercent(percent))
 	s.RSISlope = model.BoundRSISlopeFloat64(s.RSISlope * randPercent(percent))
 
+	s.ADXPeriod = model.BoundADXPeriodFloat64(s.ADXPeriod * randPercent(percent))
+	s.ParabolicSARStep = model.BoundParabolicSARStep(s.ParabolicSARStep * randPercent(percent))
+	s.ParabolicSARMaxStep = model.BoundParabolicSARMaxStep(s.ParabolicSARMaxStep * randPercent(percent))
+	s.IchimokuConversionPeriod = model.BoundIchimokuConversionPeriodFloat64(s.IchimokuConversionPeriod * randPercent(percent))
+	s.IchimokuBasePeriod = model.BoundIchimokuBasePeriodFloat64(s.IchimokuBasePeriod * randPercent(percent))
+	s.IchimokuSpanPeriod = model.BoundIchimokuSpanPeriodFloat64(s.IchimokuSpanPeriod * randPercent(percent))
+	s.SuperTrendPeriod = model.BoundSuperTrendPeriodFloat64(s.SuperTrendPeriod * randPercent(percent))
+	s.SuperTrendMultiplier = model.BoundSuperTrendMultiplier(s.SuperTrendMultiplier * randPercent(percent))
+	s.KeltnerChannelsPeriod = model.BoundKeltnerChannelsPeriodFloat64(s.KeltnerChannelsPeriod * randPercent(percent))
+	s.KeltnerChannelsMultiplier = model.BoundKeltnerChannelsMultiplier(s.KeltnerChannelsMultiplier * randPercent(percent))
+	s.DonchianChannelsPeriod = model.BoundDonchianChannelsPeriodFloat64(s.DonchianChannelsPeriod * randPercent(percent))
+
+	s.SessionVWAPMultiplier = model.BoundSessionVWAPMultiplier(s.SessionVWAPMultiplier * randPercent(percent))
+	s.VolumeProfileWindow = model.BoundVolumeProfileWindowFloat64(s.VolumeProfileWindow * randPercent(percent))
+	s.VolumeProfileBinWidth = model.BoundVolumeProfileBinWidth(s.VolumeProfileBinWidth * randPercent(percent))
+	s.VolumeProfileValueArea = model.BoundVolumeProfileValueArea(s.VolumeProfileValueArea * randPercent(percent))
+
+	s.StatisticsWindow = model.BoundStatisticsWindowFloat64(s.StatisticsWindow * randPercent(percent))
+	s.AutocorrelationLag = model.BoundAutocorrelationLagFloat64(s.AutocorrelationLag * randPercent(percent))
+	s.SampleEntropyDimension = model.BoundSampleEntropyDimensionFloat64(s.SampleEntropyDimension * randPercent(percent))
+	s.SampleEntropyTolerance = model.BoundSampleEntropyTolerance(s.SampleEntropyTolerance * randPercent(percent))
+
+	s.ReferenceWindow = model.BoundReferenceWindowFloat64(s.ReferenceWindow * randPercent(percent))
+	s.ReferenceLag = model.BoundReferenceLagFloat64(s.ReferenceLag * randPercent(percent))
+
+	s.RegimeWindow = model.BoundRegimeWindowFloat64(s.RegimeWindow * randPercent(percent))
+	s.RegimeLookback = model.BoundRegimeLookbackFloat64(s.RegimeLookback * randPercent(percent))
+	s.RegimeTrendThreshold = model.BoundRegimeTrendThreshold(s.RegimeTrendThreshold * randPercent(percent))
+	s.RegimeVolatilityThreshold = model.BoundRegimeVolatilityThreshold(s.RegimeVolatilityThreshold * randPercent(percent))
+
+	s.FracDiffWindow = model.BoundFracDiffWindowFloat64(s.FracDiffWindow * randPercent(percent))
+
+	s.SwingStrength = model.BoundSwingStrengthFloat64(s.SwingStrength * randPercent(percent))
+	s.DivergenceLookback = model.BoundDivergenceLookbackFloat64(s.DivergenceLookback * randPercent(percent))
+
+	s.SupportResistanceLookback = model.BoundSupportResistanceLookbackFloat64(s.SupportResistanceLookback * randPercent(percent))
+	s.SupportResistanceTolerance = model.BoundSupportResistanceTolerance(s.SupportResistanceTolerance * randPercent(percent))
+	s.SupportResistanceTouches = model.BoundSupportResistanceTouchesFloat64(s.SupportResistanceTouches * randPercent(percent))
+
+	s.Architecture = mutateChoice(s.Architecture, model.Architectures, percent)
+	s.SequenceLength = model.BoundSequenceLengthFloat64(s.SequenceLength * randPercent(percent))
+	s.BPTTSteps = model.BoundBPTTStepsFloat64(s.BPTTSteps * randPercent(percent))
+
+	s.TCNKernelSize = model.BoundTCNKernelSizeFloat64(s.TCNKernelSize * randPercent(percent))
+
+	s.GBDTTrees = model.BoundGBDTTreesFloat64(s.GBDTTrees * randPercent(percent))
+	s.GBDTDepth = model.BoundGBDTDepthFloat64(s.GBDTDepth * randPercent(percent))
+	s.GBDTLearnRate = model.BoundGBDTLearnRate(s.GBDTLearnRate * randPercent(percent))
+	s.GBDTSubsample = model.BoundGBDTSubsample(s.GBDTSubsample * randPercent(percent))
+	s.GBDTL2 = model.BoundGBDTL2(s.GBDTL2 * randPercent(percent))
+
+	s.HiddenLayers = model.BoundHiddenLayersFloat64(s.HiddenLayers * randPercent(percent))
+	s.HiddenLayerDecay = model.BoundHiddenLayerDecay(s.HiddenLayerDecay * randPercent(percent))
+	s.Activation = mutateChoice(s.Activation, model.Activations, percent)
+	s.Normalization = mutateChoice(s.Normalization, model.Normalizations, percent)
+	s.Residual = mutateChoice(s.Residual, []bool{false, true}, percent)
+
+	s.ClassImbalance = mutateChoice(s.ClassImbalance, model.Imbalances, percent)
+	s.FocalGamma = model.BoundFocalGamma(s.FocalGamma * randPercent(percent))
+	s.ImbalanceNeighbors = model.BoundImbalanceNeighborsFloat64(s.ImbalanceNeighbors * randPercent(percent))
+
+	s.BarrierVolatilityWindow = model.BoundBarrierVolatilityWindowFloat64(s.BarrierVolatilityWindow * randPercent(percent))
+
 	s.BatchSizeLog2 = model.BoundBatchSizeLog2Float64(s.BatchSizeLog2 * randPercent(percent))
 	s.HiddenLayerSizeLog2 = model.BoundHiddenLayerSizeLog2Float64(s.HiddenLayerSizeLog2 * randPercent(percent))
 	s.L2Penalty = model.BoundL2Penalty(s.L2Penalty * randPercent(percent))
@@ -211,5 +411,105 @@ func StrategyToParams(s Strategy) model.ModelParams {
 		RSIUpperBound:              s.RSIUpperBound,
 		RSILowerBound:              s.RSILowerBound,
 		RSISlope:                   int(s.RSISlope),
+
+		RSISmoothing:            model.RSISmoothing(),
+		MACDSmoothing:           model.MACDSmoothing(),
+		ATRSmoothing:            model.ATRSmoothing(),
+		BollingerBandsSmoothing: model.BollingerBandsSmoothing(),
+
+		ADXEnabled:                model.ADXEnabled(),
+		ADXPeriod:                 int(s.ADXPeriod),
+		ParabolicSAREnabled:       model.ParabolicSAREnabled(),
+		ParabolicSARStep:          s.ParabolicSARStep,
+		ParabolicSARMaxStep:       s.ParabolicSARMaxStep,
+		IchimokuEnabled:           model.IchimokuEnabled(),
+		IchimokuConversionPeriod:  int(s.IchimokuConversionPeriod),
+		IchimokuBasePeriod:        int(s.IchimokuBasePeriod),
+		IchimokuSpanPeriod:        int(s.IchimokuSpanPeriod),
+		SuperTrendEnabled:         model.SuperTrendEnabled(),
+		SuperTrendPeriod:          int(s.SuperTrendPeriod),
+		SuperTrendMultiplier:      s.SuperTrendMultiplier,
+		KeltnerChannelsEnabled:    model.KeltnerChannelsEnabled(),
+		KeltnerChannelsPeriod:     int(s.KeltnerChannelsPeriod),
+		KeltnerChannelsMultiplier: s.KeltnerChannelsMultiplier,
+		DonchianChannelsEnabled:   model.DonchianChannelsEnabled(),
+		DonchianChannelsPeriod:    int(s.DonchianChannelsPeriod),
+
+		SessionVWAPEnabled:     model.SessionVWAPEnabled(),
+		SessionVWAPAnchor:      model.SessionVWAPAnchor(),
+		SessionVWAPMultiplier:  s.SessionVWAPMultiplier,
+		VolumeProfileEnabled:   model.VolumeProfileEnabled(),
+		VolumeProfileWindow:    int(s.VolumeProfileWindow),
+		VolumeProfileBinWidth:  s.VolumeProfileBinWidth,
+		VolumeProfileValueArea: s.VolumeProfileValueArea,
+
+		CandlePatterns: model.CandlePatterns(),
+
+		InvalidRows: model.InvalidRows(),
+
+		StatisticsEnabled:      model.StatisticsEnabled(),
+		StatisticsWindow:       int(s.StatisticsWindow),
+		AutocorrelationLag:     int(s.AutocorrelationLag),
+		SampleEntropyDimension: int(s.SampleEntropyDimension),
+		SampleEntropyTolerance: s.SampleEntropyTolerance,
+
+		ReferenceInstruments: model.ReferenceInstruments(),
+		ReferenceWindow:      int(s.ReferenceWindow),
+		ReferenceLag:         int(s.ReferenceLag),
+
+		CalendarEnabled: model.CalendarEnabled(),
+		FundingSchedule: model.FundingSchedule(),
+
+		RegimeEnabled:             model.RegimeEnabled(),
+		RegimeWindow:              int(s.RegimeWindow),
+		RegimeLookback:            int(s.RegimeLookback),
+		RegimeTrendThreshold:      s.RegimeTrendThreshold,
+		RegimeVolatilityThreshold: s.RegimeVolatilityThreshold,
+		RegimeBlacklist:           model.RegimeBlacklist(),
+
+		FracDiffEnabled: model.FracDiffEnabled(),
+		FracDiffOrder:   model.FracDiffOrder(),
+		FracDiffWindow:  int(s.FracDiffWindow),
+
+		DivergenceEnabled:      model.DivergenceEnabled(),
+		DivergenceConfirmation: model.DivergenceConfirmation(),
+		SwingStrength:          int(s.SwingStrength),
+		DivergenceLookback:     int(s.DivergenceLookback),
+
+		LevelsEnabled:              model.LevelsEnabled(),
+		PivotMethod:                model.PivotMethod(),
+		PivotAnchor:                model.PivotAnchor(),
+		SupportResistanceLookback:  int(s.SupportResistanceLookback),
+		SupportResistanceTolerance: s.SupportResistanceTolerance,
+		SupportResistanceTouches:   int(s.SupportResistanceTouches),
+		TradeLevels:                model.TradeLevels(),
+
+		TimeframeFeatures: model.TimeframeFeatures(),
+
+		Architecture:   s.Architecture,
+		SequenceLength: int(s.SequenceLength),
+		BPTTSteps:      int(s.BPTTSteps),
+
+		TCNKernelSize: int(s.TCNKernelSize),
+
+		GBDTTrees:     int(s.GBDTTrees),
+		GBDTDepth:     int(s.GBDTDepth),
+		GBDTLearnRate: s.GBDTLearnRate,
+		GBDTSubsample: s.GBDTSubsample,
+		GBDTL2:        s.GBDTL2,
+
+		HiddenLayers:     int(s.HiddenLayers),
+		HiddenLayerDecay: s.HiddenLayerDecay,
+		Activation:       s.Activation,
+		Normalization:    s.Normalization,
+		Residual:         s.Residual,
+
+		ClassImbalance:     s.ClassImbalance,
+		FocalGamma:         s.FocalGamma,
+		ImbalanceNeighbors: int(s.ImbalanceNeighbors),
+
+		Labeling:                model.Labeling(),
+		VolatilityBarriers:      model.VolatilityBarriers(),
+		BarrierVolatilityWindow: int(s.BarrierVolatilityWindow),
 	}
 }
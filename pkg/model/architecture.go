@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"gorgonia.org/tensor"
+)
+
+// ModelArchitecture is the kind of classifier the model trains
+type ModelArchitecture string
+
+const (
+	// ArchitectureMLP is a feed forward network over the features of the
+	// latest candle
+	ArchitectureMLP ModelArchitecture = "mlp"
+	// ArchitectureLSTM is a recurrent network with LSTM cells over the
+	// features of the last SequenceLength candles
+	ArchitectureLSTM ModelArchitecture = "lstm"
+	// ArchitectureGRU is a recurrent network with GRU cells over the
+	// features of the last SequenceLength candles
+	ArchitectureGRU ModelArchitecture = "gru"
+	// ArchitectureTCN is a temporal convolutional network of dilated causal
+	// convolutions over the features of the last SequenceLength candles,
+	// with the candles' OHLCV as extra channels
+	ArchitectureTCN ModelArchitecture = "tcn"
+	// ArchitectureGBDT is a gradient boosted ensemble of decision trees over
+	// the features of the latest candle
+	ArchitectureGBDT ModelArchitecture = "gbdt"
+)
+
+// Architectures lists every architecture, for the optimizer to choose from
+var Architectures = []ModelArchitecture{ArchitectureMLP, ArchitectureLSTM, ArchitectureGRU, ArchitectureTCN, ArchitectureGBDT}
+
+func ParseArchitecture(s string) (ModelArchitecture, error) {
+	v := ModelArchitecture(strings.ToLower(s))
+	if slices.Contains(Architectures, v) {
+		return v, nil
+	}
+	names := make([]string, len(Architectures))
+	for i, a := range Architectures {
+		names[i] = string(a)
+	}
+	return "", fmt.Errorf("unknown architecture %q, expected one of %s", s, strings.Join(names, ", "))
+}
+
+// classifier is what an architecture implements for Train to fit it and a
+// Predictor to run its weights, so the rest of the model doesn't need to know
+// which one it has
+type classifier interface {
+	// sequential says whether a sample is the rows of the last
+	// SequenceLength candles rather than the row of the latest
+	sequential() bool
+	// train fits the classifier to the samples, returning its weights
+	train(tracker *progress.Tracker, params ModelParams, features [][]float64, labels []float64, epochs int) ([]tensor.Tensor, error)
+	// predictor prepares the weights to predict batches of samples
+	predictor(params ModelParams, weights []tensor.Tensor) batchPredictor
+}
+
+var classifiers = map[ModelArchitecture]classifier{
+	ArchitectureMLP:  mlpClassifier{},
+	ArchitectureLSTM: recurrentClassifier{ArchitectureLSTM},
+	ArchitectureGRU:  recurrentClassifier{ArchitectureGRU},
+	ArchitectureTCN:  tcnClassifier{},
+	ArchitectureGBDT: gbdtClassifier{},
+}
+
+// classifier returns the architecture's implementation, the MLP if it's
+// unset
+func (a ModelArchitecture) classifier() classifier {
+	if c, ok := classifiers[a]; ok {
+		return c
+	}
+	return classifiers[ArchitectureMLP]
+}
+
+// sequenceLength is the number of candles whose features make up a sample,
+// oldest first
+func sequenceLength(params ModelParams) int {
+	if params.Architecture.classifier().sequential() {
+		return params.SequenceLength
+	}
+	return 1
+}
+
+// sequenceRowSize is the number of features in each row of a sequence
+func sequenceRowSize(params ModelParams, inputSize int) (int, error) {
+	rowSize := inputSize / params.SequenceLength
+	if rowSize*params.SequenceLength != inputSize {
+		return 0, fmt.Errorf("input of %d features isn't a sequence of %d rows", inputSize, params.SequenceLength)
+	}
+	return rowSize, nil
+}
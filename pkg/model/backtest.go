@@ -1,12 +1,13 @@
 package model
 
 import (
-	"log"
 	"math"
 	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/grexie/signals/pkg/candles"
+	"github.com/grexie/signals/pkg/ta"
 	"github.com/jedib0t/go-pretty/v6/progress"
 	"gonum.org/v1/gonum/stat"
 )
@@ -17,6 +18,7 @@ type BacktestMetrics struct {
 	SharpeRatio  float64
 	SortinoRatio float64
 	Trades       float64
+	Regimes      RegimeBreakdown
 }
 
 func (m *Model) CalculateCandlesForBacktest(params ModelParams, start time.Time, end time.Time) int {
@@ -24,22 +26,50 @@ func (m *Model) CalculateCandlesForBacktest(params ModelParams, start time.Time,
 }
 
 func (m *Model) Backtest(pw progress.Writer, iterate func(), instrument string, params ModelParams, start time.Time, end time.Time) (BacktestMetrics, error) {
-	candles, err := candles.GetCandles(m.db, pw, instrument, candles.Network(Network()), start.Add(-time.Duration(params.WindowSize)*time.Minute), end)
+	from := start.Add(-time.Duration(max(FeatureWarmup(params), regimeWarmup(params), tradeLevelsWarmup(params), barrierWarmup(params))) * time.Minute)
+	candles, err := candles.GetCandles(m.db, pw, instrument, candles.Network(Network()), from, end)
+	if err != nil {
+		return BacktestMetrics{}, err
+	}
+	references, err := GetReferences(m.db, pw, params, from, end)
 	if err != nil {
 		return BacktestMetrics{}, err
 	}
 
-	features := PrepareForPrediction(candles, params)
+	features := PrepareForPrediction(candles, references, params)
+	regimes := classifyRegimes(candles, params)
+	levels := tradeLevels(candles, params)
+	scales := barrierScales(candles, params)
+	breakdown := NewRegimeBreakdown()
 	trader := NewPaperTrader(10000, params.StopLoss, params.TakeProfit, params.Commission/2, Leverage(), params.Cooldown)
 
-	for i := params.WindowSize; i < len(candles); i++ {
+	// trade from start, the candles before it only warm up the indicators
+	first := sort.Search(len(candles), func(i int) bool {
+		return !candles[i].Timestamp.Before(start)
+	})
+	first = max(first, params.WindowSize)
+
+	// the whole window at once rather than a candle at a time
+	preds, err := m.predictor.PredictBatch(features[first-params.WindowSize:])
+	if err != nil {
+		return BacktestMetrics{}, err
+	}
+
+	for i := first; i < len(candles); i++ {
+		trader.Regime = regimes[i]
+		if levels != nil {
+			trader.Support, trader.Resistance = ta.NearestLevels(candles[i].Close, levels[i])
+		}
+		if scales != nil {
+			trader.BarrierScale = scales[i]
+		}
+		breakdown[regimes[i]].Candles++
 		trader.Iterate(candles[i], func(c Candle) Strategy {
-			pred, err := Predict(m.weights, features[i-params.WindowSize])
-			if err != nil {
-				log.Println("prediction error:", err)
+			if params.RegimeBlacklist.Contains(regimes[i]) {
 				return StrategyHold
 			}
 
+			pred := preds[i-first]
 			if pred[1] >= params.MinTradeProbability && pred[2] < params.MinTradeProbability {
 				return StrategyLong
 			} else if pred[2] >= params.MinTradeProbability && pred[1] < params.MinTradeProbability {
@@ -53,6 +83,8 @@ func (m *Model) Backtest(pw progress.Writer, iterate func(), instrument string,
 		}
 	}
 
+	breakdown.AddTrades(trader.ClosedTrades)
+
 	days := float64(end.Sub(start).Hours() / 24)
 	return BacktestMetrics{
 		PnL:          (math.Pow(1.0+trader.PnL()/100.0, 1.0/days) - 1) * 100,
@@ -60,6 +92,7 @@ func (m *Model) Backtest(pw progress.Writer, iterate func(), instrument string,
 		SharpeRatio:  trader.SharpeRatio(0),
 		SortinoRatio: trader.SortinoRatio(0),
 		Trades:       float64(len(trader.ClosedTrades)) / days,
+		Regimes:      breakdown,
 	}, nil
 }
 
@@ -73,6 +106,8 @@ type DeepBacktestMetrics struct {
 	Min    BacktestMetrics
 	Max    BacktestMetrics
 	StdDev BacktestMetrics
+	// Regimes sums the breakdown of every backtest
+	Regimes RegimeBreakdown
 }
 
 func NewDeepBacktestMetrics(metrics []BacktestMetrics) DeepBacktestMetrics {
@@ -82,7 +117,7 @@ func NewDeepBacktestMetrics(metrics []BacktestMetrics) DeepBacktestMetrics {
 	sortinoRatio := make([]float64, len(metrics))
 	trades := make([]float64, len(metrics))
 
-	out := DeepBacktestMetrics{}
+	out := DeepBacktestMetrics{Regimes: NewRegimeBreakdown()}
 
 	for i, r := range metrics {
 		pnl[i] = r.PnL
@@ -90,6 +125,9 @@ func NewDeepBacktestMetrics(metrics []BacktestMetrics) DeepBacktestMetrics {
 		sharpeRatio[i] = r.SharpeRatio
 		sortinoRatio[i] = r.SortinoRatio
 		trades[i] = r.Trades
+		if r.Regimes != nil {
+			out.Regimes.Add(r.Regimes)
+		}
 
 		if i == 0 {
 			out.Min.PnL = pnl[i]
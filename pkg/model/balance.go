@@ -1,62 +1,345 @@
 package model
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ImbalanceStrategy is how training makes up for LONG and SHORT labels being
+// much rarer than HOLD. Resampling strategies add samples of the minority
+// classes until each class has as many as the majority, while loss
+// strategies keep the samples as they are and weight each one's loss.
+type ImbalanceStrategy string
 
-	"github.com/jedib0t/go-pretty/v6/progress"
+const (
+	// ImbalanceNone trains on the samples as they are
+	ImbalanceNone ImbalanceStrategy = "none"
+	// ImbalanceOversample copies random samples of the minority classes with
+	// 1% multiplicative noise
+	ImbalanceOversample ImbalanceStrategy = "oversample"
+	// ImbalanceWeighted weights each sample's cross entropy inversely to the
+	// frequency of its class
+	ImbalanceWeighted ImbalanceStrategy = "weighted"
+	// ImbalanceFocal weights each sample's cross entropy by its class like
+	// ImbalanceWeighted, and scales it by (1-p)^FocalGamma of its class's
+	// probability, focusing on the samples the model gets wrong
+	ImbalanceFocal ImbalanceStrategy = "focal"
+	// ImbalanceSMOTE interpolates between random samples of the minority
+	// classes and their ImbalanceNeighbors nearest neighbours of the same
+	// class
+	ImbalanceSMOTE ImbalanceStrategy = "smote"
+	// ImbalanceADASYN interpolates like SMOTE, generating more samples from
+	// those with more neighbours of other classes, which are harder to learn
+	ImbalanceADASYN ImbalanceStrategy = "adasyn"
 )
 
-func balanceClasses(pw progress.Writer, features [][]float64, labels []float64) ([][]float64, []float64) {
-	// Group samples by class
-	classSamples := make(map[int][]int)
+// Imbalances lists every imbalance strategy, for the optimizer to choose from
+var Imbalances = []ImbalanceStrategy{ImbalanceNone, ImbalanceOversample, ImbalanceWeighted, ImbalanceFocal, ImbalanceSMOTE, ImbalanceADASYN}
+
+func ParseImbalance(s string) (ImbalanceStrategy, error) {
+	v := ImbalanceStrategy(strings.ToLower(s))
+	if slices.Contains(Imbalances, v) {
+		return v, nil
+	}
+	names := make([]string, len(Imbalances))
+	for i, s := range Imbalances {
+		names[i] = string(s)
+	}
+	return "", fmt.Errorf("unknown class imbalance strategy %q, expected one of %s", s, strings.Join(names, ", "))
+}
+
+// Rebalance resamples the samples with the params' imbalance strategy,
+// returning them unchanged for strategies that weight the loss instead. The
+// new samples follow the originals.
+func Rebalance(params ModelParams, features [][]float64, labels []float64) ([][]float64, []float64) {
+	switch params.ClassImbalance {
+	case ImbalanceOversample:
+		return oversample(features, labels)
+	case ImbalanceSMOTE:
+		return smote(params, features, labels, false)
+	case ImbalanceADASYN:
+		return smote(params, features, labels, true)
+	default:
+		return features, labels
+	}
+}
+
+// splitValidation holds out validationSize random samples for validation,
+// then rebalances the rest, so early stopping never sees samples made from
+// training samples. The new samples follow the originals in the returned
+// features and labels, and the training indices include them in random
+// order.
+func splitValidation(params ModelParams, features [][]float64, labels []float64, validationSize int) ([][]float64, []float64, []int, []int) {
+	indices := rand.Perm(len(features))
+	trainIndices, validIndices := indices[validationSize:], indices[:validationSize]
+
+	trainFeatures := make([][]float64, len(trainIndices))
+	trainLabels := make([]float64, len(trainIndices))
+	for j, i := range trainIndices {
+		trainFeatures[j], trainLabels[j] = features[i], labels[i]
+	}
+	balancedFeatures, balancedLabels := Rebalance(params, trainFeatures, trainLabels)
+
+	features = append(slices.Clone(features), balancedFeatures[len(trainFeatures):]...)
+	labels = append(slices.Clone(labels), balancedLabels[len(trainLabels):]...)
+	for i := len(indices); i < len(features); i++ {
+		trainIndices = append(trainIndices, i)
+	}
+	rand.Shuffle(len(trainIndices), func(i, j int) {
+		trainIndices[i], trainIndices[j] = trainIndices[j], trainIndices[i]
+	})
+	return features, labels, trainIndices, validIndices
+}
+
+// classSamples groups the indices of the samples by class, returning them
+// with the size of the largest class
+func classSamples(labels []float64) (map[int][]int, int) {
+	samples := make(map[int][]int)
 	for i, label := range labels {
 		class := int(label)
-		classSamples[class] = append(classSamples[class], i)
+		samples[class] = append(samples[class], i)
 	}
 
-	// Find majority class size
 	majoritySize := 0
-	for _, samples := range classSamples {
-		if len(samples) > majoritySize {
-			majoritySize = len(samples)
-		}
+	for _, s := range samples {
+		majoritySize = max(majoritySize, len(s))
 	}
+	return samples, majoritySize
+}
 
-	// Balance dataset through augmentation
-	balancedFeatures := make([][]float64, 0)
-	balancedLabels := make([]float64, 0)
+// sortedClasses returns the classes in order, so resampling doesn't depend
+// on the order of the map
+func sortedClasses(samples map[int][]int) []int {
+	classes := make([]int, 0, len(samples))
+	for class := range samples {
+		classes = append(classes, class)
+	}
+	sort.Ints(classes)
+	return classes
+}
+
+func oversample(features [][]float64, labels []float64) ([][]float64, []float64) {
+	samples, majoritySize := classSamples(labels)
+
+	balancedFeatures := slices.Clone(features)
+	balancedLabels := slices.Clone(labels)
 
-	for class, samples := range classSamples {
-		currentSamples := len(samples)
+	for _, class := range sortedClasses(samples) {
+		indices := samples[class]
+		for range majoritySize - len(indices) {
+			// Select a random sample to augment
+			original := features[indices[rand.Intn(len(indices))]]
+
+			// Add noise to features (1% random noise)
+			augmented := make([]float64, len(original))
+			for j, v := range original {
+				noise := (rand.Float64()*2 - 1) * 0.01
+				augmented[j] = v * (1 + noise)
+			}
 
-		// Add original samples
-		for _, idx := range samples {
-			balancedFeatures = append(balancedFeatures, features[idx])
+			balancedFeatures = append(balancedFeatures, augmented)
 			balancedLabels = append(balancedLabels, float64(class))
 		}
+	}
+
+	return balancedFeatures, balancedLabels
+}
 
-		// Add augmented samples if needed
-		if currentSamples < majoritySize {
-			numAugmented := majoritySize - currentSamples
-			for i := 0; i < numAugmented; i++ {
-				// Select a random sample to augment
-				originalIdx := samples[rand.Intn(len(samples))]
+// smote generates samples of each minority class on the line between one of
+// its samples and one of that sample's nearest neighbours in the class.
+// SMOTE chooses the samples uniformly, ADASYN in proportion to how many of
+// their nearest neighbours over every class are of another class.
+func smote(params ModelParams, features [][]float64, labels []float64, adaptive bool) ([][]float64, []float64) {
+	samples, majoritySize := classSamples(labels)
+	scale := featureScale(features)
+	k := max(params.ImbalanceNeighbors, 1)
 
-				// Create augmented feature vector
-				augmented := make([]float64, len(features[originalIdx]))
-				copy(augmented, features[originalIdx])
+	balancedFeatures := slices.Clone(features)
+	balancedLabels := slices.Clone(labels)
 
-				// Add noise to features (1% random noise)
-				for j := range augmented {
-					noise := (rand.Float64()*2 - 1) * 0.01
-					augmented[j] *= (1 + noise)
+	for _, class := range sortedClasses(samples) {
+		indices := samples[class]
+		count := majoritySize - len(indices)
+		if count == 0 || len(indices) < 2 {
+			continue
+		}
+
+		neighbours := nearestNeighbours(features, scale, indices, indices, k)
+
+		// the cumulative share of the samples to generate from each sample
+		cumulative := make([]float64, len(indices))
+		uniform := !adaptive
+		if adaptive {
+			all := make([]int, len(features))
+			for i := range all {
+				all[i] = i
+			}
+			total := 0.0
+			for i, nn := range nearestNeighbours(features, scale, indices, all, k) {
+				for _, j := range nn {
+					if int(labels[j]) != class {
+						total++
+					}
 				}
+				cumulative[i] = total
+			}
+			// the class isn't mixed with the others, so generate uniformly
+			uniform = total == 0
+		}
+		if uniform {
+			for i := range cumulative {
+				cumulative[i] = float64(i + 1)
+			}
+		}
+		total := cumulative[len(cumulative)-1]
+
+		for range count {
+			i := sort.SearchFloat64s(cumulative, rand.Float64()*total)
+			i = min(i, len(indices)-1)
+			base := features[indices[i]]
+			neighbour := features[neighbours[i][rand.Intn(len(neighbours[i]))]]
 
-				balancedFeatures = append(balancedFeatures, augmented)
-				balancedLabels = append(balancedLabels, float64(class))
+			gap := rand.Float64()
+			synthetic := make([]float64, len(base))
+			for j := range synthetic {
+				synthetic[j] = base[j] + gap*(neighbour[j]-base[j])
 			}
+
+			balancedFeatures = append(balancedFeatures, synthetic)
+			balancedLabels = append(balancedLabels, float64(class))
 		}
 	}
 
 	return balancedFeatures, balancedLabels
 }
+
+// featureScale is the reciprocal of each feature's standard deviation, so
+// features with large values don't dominate distances
+func featureScale(features [][]float64) []float64 {
+	width := len(features[0])
+	mean := make([]float64, width)
+	for _, row := range features {
+		for j, v := range row {
+			mean[j] += v
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(len(features))
+	}
+
+	scale := make([]float64, width)
+	for _, row := range features {
+		for j, v := range row {
+			scale[j] += (v - mean[j]) * (v - mean[j])
+		}
+	}
+	for j := range scale {
+		if std := math.Sqrt(scale[j] / float64(len(features))); std > 0 {
+			scale[j] = 1 / std
+		} else {
+			scale[j] = 0
+		}
+	}
+	return scale
+}
+
+// nearestNeighbours returns the indices of the k candidates nearest each of
+// the queries, other than the query itself, searching the queries in
+// parallel
+func nearestNeighbours(features [][]float64, scale []float64, queries, candidates []int, k int) [][]int {
+	neighbours := make([][]int, len(queries))
+	workers := runtime.NumCPU()
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			type neighbour struct {
+				index    int
+				distance float64
+			}
+			nearest := make([]neighbour, 0, k+1)
+
+			for q := w; q < len(queries); q += workers {
+				query := features[queries[q]]
+				nearest = nearest[:0]
+				for _, c := range candidates {
+					if c == queries[q] {
+						continue
+					}
+					distance := 0.0
+					for j, v := range features[c] {
+						d := (v - query[j]) * scale[j]
+						distance += d * d
+					}
+					if len(nearest) == k && distance >= nearest[k-1].distance {
+						continue
+					}
+					// insert in order, dropping the furthest beyond k
+					i := sort.Search(len(nearest), func(i int) bool { return nearest[i].distance > distance })
+					if len(nearest) < k {
+						nearest = append(nearest, neighbour{})
+					}
+					copy(nearest[i+1:], nearest[i:])
+					nearest[i] = neighbour{c, distance}
+				}
+
+				neighbours[q] = make([]int, len(nearest))
+				for i, n := range nearest {
+					neighbours[q][i] = n.index
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return neighbours
+}
+
+// classWeights is the weight of each class's samples in the loss: inversely
+// proportional to the class's frequency among the training samples for
+// ImbalanceWeighted and ImbalanceFocal, so each class contributes equally,
+// and one otherwise
+func classWeights(params ModelParams, labels []float64, trainIndices []int) []float64 {
+	weights := make([]float64, outputSize)
+	for k := range weights {
+		weights[k] = 1
+	}
+	if params.ClassImbalance != ImbalanceWeighted && params.ClassImbalance != ImbalanceFocal {
+		return weights
+	}
+
+	counts := make([]float64, outputSize)
+	for _, i := range trainIndices {
+		counts[int(labels[i])]++
+	}
+	present := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			present++
+		}
+	}
+	for k, c := range counts {
+		if c > 0 {
+			weights[k] = float64(len(trainIndices)) / (present * c)
+		}
+	}
+	return weights
+}
+
+// focalFactor is how much of a sample's loss focal loss keeps, given the
+// probability of its class
+func focalFactor(params ModelParams, p float64) float64 {
+	if params.ClassImbalance != ImbalanceFocal {
+		return 1
+	}
+	return math.Pow(1-p, params.FocalGamma)
+}
@@ -316,3 +316,309 @@ func BoundHiddenLayerSizeLog2(v int) int {
 func BoundHiddenLayerSizeLog2Float64(v float64) float64 {
 	return math.Max(3, math.Min(8, v))
 }
+
+// Trend Indicators
+func BoundADXPeriod(v int) int {
+	return int(math.Max(7, math.Min(50, float64(v)))) // Default: 14
+}
+
+func BoundADXPeriodFloat64(v float64) float64 {
+	return math.Max(7, math.Min(50, v))
+}
+
+func BoundParabolicSARStep(v float64) float64 {
+	return math.Max(0.005, math.Min(0.05, v)) // Default: 0.02
+}
+
+func BoundParabolicSARMaxStep(v float64) float64 {
+	return math.Max(0.1, math.Min(0.5, v)) // Default: 0.2
+}
+
+func BoundIchimokuConversionPeriod(v int) int {
+	return int(math.Max(5, math.Min(20, float64(v)))) // Default: 9
+}
+
+func BoundIchimokuConversionPeriodFloat64(v float64) float64 {
+	return math.Max(5, math.Min(20, v))
+}
+
+func BoundIchimokuBasePeriod(v int) int {
+	return int(math.Max(20, math.Min(60, float64(v)))) // Default: 26
+}
+
+func BoundIchimokuBasePeriodFloat64(v float64) float64 {
+	return math.Max(20, math.Min(60, v))
+}
+
+func BoundIchimokuSpanPeriod(v int) int {
+	return int(math.Max(40, math.Min(120, float64(v)))) // Default: 52
+}
+
+func BoundIchimokuSpanPeriodFloat64(v float64) float64 {
+	return math.Max(40, math.Min(120, v))
+}
+
+func BoundSuperTrendPeriod(v int) int {
+	return int(math.Max(5, math.Min(30, float64(v)))) // Default: 10
+}
+
+func BoundSuperTrendPeriodFloat64(v float64) float64 {
+	return math.Max(5, math.Min(30, v))
+}
+
+func BoundSuperTrendMultiplier(v float64) float64 {
+	return math.Max(1, math.Min(5, v)) // Default: 3.0
+}
+
+func BoundKeltnerChannelsPeriod(v int) int {
+	return int(math.Max(10, math.Min(50, float64(v)))) // Default: 20
+}
+
+func BoundKeltnerChannelsPeriodFloat64(v float64) float64 {
+	return math.Max(10, math.Min(50, v))
+}
+
+func BoundKeltnerChannelsMultiplier(v float64) float64 {
+	return math.Max(1, math.Min(3, v)) // Default: 2.0
+}
+
+func BoundDonchianChannelsPeriod(v int) int {
+	return int(math.Max(10, math.Min(100, float64(v)))) // Default: 20
+}
+
+func BoundDonchianChannelsPeriodFloat64(v float64) float64 {
+	return math.Max(10, math.Min(100, v))
+}
+
+// Volume Profile
+func BoundSessionVWAPMultiplier(v float64) float64 {
+	return math.Max(1, math.Min(3, v)) // Default: 2.0
+}
+
+func BoundVolumeProfileWindow(v int) int {
+	return int(math.Max(240, math.Min(4320, float64(v)))) // Default: 1440
+}
+
+func BoundVolumeProfileWindowFloat64(v float64) float64 {
+	return math.Max(240, math.Min(4320, v))
+}
+
+func BoundVolumeProfileBinWidth(v float64) float64 {
+	return math.Max(0.0002, math.Min(0.005, v)) // Default: 0.001
+}
+
+func BoundVolumeProfileValueArea(v float64) float64 {
+	return math.Max(0.5, math.Min(0.9, v)) // Default: 0.7
+}
+
+// Statistics
+func BoundStatisticsWindow(v int) int {
+	return int(math.Max(20, math.Min(240, float64(v)))) // Default: 60
+}
+
+func BoundStatisticsWindowFloat64(v float64) float64 {
+	return math.Max(20, math.Min(240, v))
+}
+
+func BoundAutocorrelationLag(v int) int {
+	return int(math.Max(1, math.Min(10, float64(v)))) // Default: 1
+}
+
+func BoundAutocorrelationLagFloat64(v float64) float64 {
+	return math.Max(1, math.Min(10, v))
+}
+
+func BoundSampleEntropyDimension(v int) int {
+	return int(math.Max(1, math.Min(3, float64(v)))) // Default: 2
+}
+
+func BoundSampleEntropyDimensionFloat64(v float64) float64 {
+	return math.Max(1, math.Min(3, v))
+}
+
+func BoundSampleEntropyTolerance(v float64) float64 {
+	return math.Max(0.1, math.Min(0.5, v)) // Default: 0.2
+}
+
+// Reference Instruments
+func BoundReferenceWindow(v int) int {
+	return int(math.Max(15, math.Min(240, float64(v)))) // Default: 60
+}
+
+func BoundReferenceWindowFloat64(v float64) float64 {
+	return math.Max(15, math.Min(240, v))
+}
+
+func BoundReferenceLag(v int) int {
+	return int(math.Max(1, math.Min(15, float64(v)))) // Default: 1
+}
+
+func BoundReferenceLagFloat64(v float64) float64 {
+	return math.Max(1, math.Min(15, v))
+}
+
+// Regimes
+func BoundRegimeWindow(v int) int {
+	return int(math.Max(20, math.Min(240, float64(v)))) // Default: 60
+}
+
+func BoundRegimeWindowFloat64(v float64) float64 {
+	return math.Max(20, math.Min(240, v))
+}
+
+func BoundRegimeLookback(v int) int {
+	return int(math.Max(240, math.Min(4320, float64(v)))) // Default: 1440
+}
+
+func BoundRegimeLookbackFloat64(v float64) float64 {
+	return math.Max(240, math.Min(4320, v))
+}
+
+func BoundRegimeTrendThreshold(v float64) float64 {
+	return math.Max(0.1, math.Min(0.6, v)) // Default: 0.3
+}
+
+func BoundRegimeVolatilityThreshold(v float64) float64 {
+	return math.Max(0.5, math.Min(3, v)) // Default: 1.5
+}
+
+// Fractional Differencing
+func BoundFracDiffOrder(v float64) float64 {
+	return math.Max(0, math.Min(1, v)) // Default: 0
+}
+
+func BoundFracDiffWindow(v int) int {
+	return int(math.Max(50, math.Min(1000, float64(v)))) // Default: 200
+}
+
+func BoundFracDiffWindowFloat64(v float64) float64 {
+	return math.Max(50, math.Min(1000, v))
+}
+
+// Divergences
+func BoundSwingStrength(v int) int {
+	return int(math.Max(2, math.Min(10, float64(v)))) // Default: 3
+}
+
+func BoundSwingStrengthFloat64(v float64) float64 {
+	return math.Max(2, math.Min(10, v))
+}
+
+func BoundDivergenceLookback(v int) int {
+	return int(math.Max(20, math.Min(240, float64(v)))) // Default: 60
+}
+
+func BoundDivergenceLookbackFloat64(v float64) float64 {
+	return math.Max(20, math.Min(240, v))
+}
+
+// Levels
+func BoundSupportResistanceLookback(v int) int {
+	return int(math.Max(240, math.Min(4320, float64(v)))) // Default: 1440
+}
+
+func BoundSupportResistanceLookbackFloat64(v float64) float64 {
+	return math.Max(240, math.Min(4320, v))
+}
+
+func BoundSupportResistanceTolerance(v float64) float64 {
+	return math.Max(0.0005, math.Min(0.01, v)) // Default: 0.002
+}
+
+func BoundSupportResistanceTouches(v int) int {
+	return int(math.Max(1, math.Min(5, float64(v)))) // Default: 2
+}
+
+func BoundSupportResistanceTouchesFloat64(v float64) float64 {
+	return math.Max(1, math.Min(5, v))
+}
+
+// Architecture
+func BoundSequenceLength(v int) int {
+	return int(math.Max(5, math.Min(120, float64(v)))) // Default: 30
+}
+
+func BoundSequenceLengthFloat64(v float64) float64 {
+	return math.Max(5, math.Min(120, v))
+}
+
+func BoundBPTTSteps(v int) int {
+	return int(math.Max(2, math.Min(60, float64(v)))) // Default: 10
+}
+
+func BoundBPTTStepsFloat64(v float64) float64 {
+	return math.Max(2, math.Min(60, v))
+}
+
+// TCN
+func BoundTCNKernelSize(v int) int {
+	return int(math.Max(2, math.Min(5, float64(v)))) // Default: 3
+}
+
+func BoundTCNKernelSizeFloat64(v float64) float64 {
+	return math.Max(2, math.Min(5, v))
+}
+
+// GBDT
+func BoundGBDTTrees(v int) int {
+	return int(math.Max(10, math.Min(1000, float64(v)))) // Default: 200
+}
+
+func BoundGBDTTreesFloat64(v float64) float64 {
+	return math.Max(10, math.Min(1000, v))
+}
+
+func BoundGBDTDepth(v int) int {
+	return int(math.Max(2, math.Min(10, float64(v)))) // Default: 4
+}
+
+func BoundGBDTDepthFloat64(v float64) float64 {
+	return math.Max(2, math.Min(10, v))
+}
+
+func BoundGBDTLearnRate(v float64) float64 {
+	return math.Max(0.01, math.Min(0.5, v)) // Default: 0.1
+}
+
+func BoundGBDTSubsample(v float64) float64 {
+	return math.Max(0.1, math.Min(1, v)) // Default: 0.8
+}
+
+func BoundGBDTL2(v float64) float64 {
+	return math.Max(0, math.Min(100, v)) // Default: 1
+}
+
+// Network
+func BoundHiddenLayers(v int) int {
+	return int(math.Max(1, math.Min(6, float64(v)))) // Default: 3
+}
+
+func BoundHiddenLayersFloat64(v float64) float64 {
+	return math.Max(1, math.Min(6, v))
+}
+
+func BoundHiddenLayerDecay(v float64) float64 {
+	return math.Max(0.25, math.Min(1, v)) // Default: 0.5
+}
+
+// Class imbalance
+func BoundFocalGamma(v float64) float64 {
+	return math.Max(1, math.Min(5, v)) // Default: 2
+}
+
+func BoundImbalanceNeighbors(v int) int {
+	return int(math.Max(1, math.Min(20, float64(v)))) // Default: 5
+}
+
+func BoundImbalanceNeighborsFloat64(v float64) float64 {
+	return math.Max(1, math.Min(20, v))
+}
+
+// Labeling
+func BoundBarrierVolatilityWindow(v int) int {
+	return int(math.Max(10, math.Min(1000, float64(v)))) // Default: 60
+}
+
+func BoundBarrierVolatilityWindowFloat64(v float64) float64 {
+	return math.Max(10, math.Min(1000, v))
+}
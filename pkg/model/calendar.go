@@ -0,0 +1,43 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Optional calendar feature group from each candle's timestamp in UTC. The
+// hour of the day and day of the week are encoded as sine/cosine pairs so
+// 23:59 sits next to 00:00 and Sunday next to Monday. The time to the next
+// funding is in minutes, scaled by the minutes in SIGNALS_FUNDING_SCHEDULE.
+var calendarFeatures = featureSpec{
+	name:    "calendar",
+	enabled: func(p ModelParams) bool { return p.CalendarEnabled },
+	warmup:  func(ModelParams) int { return 0 },
+	compute: func(in *featureInput) []featureSeries {
+		schedule := in.params.FundingSchedule
+		day := func(i int) float64 {
+			t := in.candles[i].Timestamp.UTC()
+			return float64(t.Hour()*60+t.Minute()) / (24 * 60)
+		}
+		week := func(i int) float64 {
+			// weeks start on Monday
+			weekday := (int(in.candles[i].Timestamp.UTC().Weekday()) + 6) % 7
+			return (float64(weekday) + day(i)) / 7
+		}
+
+		return []featureSeries{
+			ranged("hour_sin", in.series(func(i int) float64 { return math.Sin(2 * math.Pi * day(i)) }), -1, 1),
+			ranged("hour_cos", in.series(func(i int) float64 { return math.Cos(2 * math.Pi * day(i)) }), -1, 1),
+			ranged("weekday_sin", in.series(func(i int) float64 { return math.Sin(2 * math.Pi * week(i)) }), -1, 1),
+			ranged("weekday_cos", in.series(func(i int) float64 { return math.Cos(2 * math.Pi * week(i)) }), -1, 1),
+			ranged("funding_minutes", in.series(func(i int) float64 {
+				t := in.candles[i].Timestamp
+				return schedule.Start(t).Add(schedule.Period).Sub(t).Minutes()
+			}), 0, schedule.Period.Minutes()),
+			raw("weekend", in.series(func(i int) float64 {
+				weekday := in.candles[i].Timestamp.UTC().Weekday()
+				return boolToFloat(weekday == time.Saturday || weekday == time.Sunday)
+			})),
+		}
+	},
+}
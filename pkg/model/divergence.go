@@ -0,0 +1,101 @@
+package model
+
+import "github.com/grexie/signals/pkg/ta"
+
+// divergenceIndicator is an indicator checked for divergences from price
+type divergenceIndicator struct {
+	name    string
+	warmup  func(p ModelParams) int
+	compute func(in *featureInput) []float64
+}
+
+var divergenceIndicators = []divergenceIndicator{
+	{
+		name:   "rsi",
+		warmup: func(p ModelParams) int { return smoothingWarmup(p.LongRSILength, p.RSISmoothing) },
+		compute: func(in *featureInput) []float64 {
+			return ta.RSI(in.closes, in.params.LongRSILength, in.params.RSISmoothing)
+		},
+	},
+	{
+		name: "macd",
+		warmup: func(p ModelParams) int {
+			return smoothingWarmup(p.LongMACDWindowLength, p.MACDSmoothing)
+		},
+		compute: func(in *featureInput) []float64 {
+			p := in.params
+			macd, _ := ta.MACD(in.closes, p.ShortMACDWindowLength, p.LongMACDWindowLength, p.MACDSignalWindow, p.MACDSmoothing)
+			return macd
+		},
+	},
+	{
+		// only differences between swings are compared, so OBV's arbitrary
+		// starting point doesn't matter
+		name:   "obv",
+		warmup: func(ModelParams) int { return 1 },
+		compute: func(in *featureInput) []float64 {
+			return ta.OBV(in.closes, in.volumes)
+		},
+	},
+	{
+		name:   "mfi",
+		warmup: func(p ModelParams) int { return p.MoneyFlowIndexPeriod + 1 },
+		compute: func(in *featureInput) []float64 {
+			return ta.MoneyFlowIndex(in.highs, in.lows, in.closes, in.volumes, in.params.MoneyFlowIndexPeriod)
+		},
+	},
+}
+
+// divergences calculates the divergences between price and each indicator
+func divergences(in *featureInput) []ta.Divergences {
+	out := make([]ta.Divergences, len(divergenceIndicators))
+	for j, indicator := range divergenceIndicators {
+		out[j] = ta.Divergence(in.highs, in.lows, indicator.compute(in), in.params.SwingStrength, in.params.DivergenceLookback)
+	}
+	return out
+}
+
+// A divergence signal depends on its swing, up to lookback candles before
+// it, the previous swing, up to lookback candles before that, and the
+// candles either side of both swings
+func divergenceWarmup(p ModelParams) int {
+	warmup := 0
+	for _, indicator := range divergenceIndicators {
+		warmup = max(warmup, indicator.warmup(p))
+	}
+	return warmup + 2*p.DivergenceLookback + 2*p.SwingStrength + 1
+}
+
+// Optional graded divergences between price and RSI, MACD, OBV and MFI
+var divergenceFeatures = featureSpec{
+	name:    "divergence",
+	enabled: func(p ModelParams) bool { return p.DivergenceEnabled },
+	warmup:  divergenceWarmup,
+	compute: func(in *featureInput) []featureSeries {
+		series := []featureSeries{}
+		for j, d := range divergences(in) {
+			name := divergenceIndicators[j].name
+			series = append(series,
+				raw(name+".regular_bullish", d.RegularBullish),
+				raw(name+".regular_bearish", d.RegularBearish),
+				raw(name+".hidden_bullish", d.HiddenBullish),
+				raw(name+".hidden_bearish", d.HiddenBearish),
+			)
+		}
+		return series
+	},
+}
+
+// divergenceConfirmations returns whether any indicator has a bullish or
+// bearish divergence at each candle, for confirming labels
+func divergenceConfirmations(candles []Candle, params ModelParams) (bullish, bearish []bool) {
+	in := newFeatureInput(candles, nil, params)
+	bullish, bearish = make([]bool, len(candles)), make([]bool, len(candles))
+	for _, d := range divergences(in) {
+		for i := range candles {
+			bullish[i] = bullish[i] || d.RegularBullish[i] > 0 || d.HiddenBullish[i] > 0
+			bearish[i] = bearish[i] || d.RegularBearish[i] > 0 || d.HiddenBearish[i] > 0
+		}
+	}
+	return bullish, bearish
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/grexie/signals/pkg/ta"
 	"github.com/jedib0t/go-pretty/v6/progress"
 	"github.com/syndtr/goleveldb/leveldb"
 )
@@ -43,6 +44,9 @@ func NewEnsembleModel(ctx context.Context, db *leveldb.DB, instrument string, pa
 			if i == 0 {
 				continue
 			}
+			if ctx.Err() != nil {
+				return
+			}
 			log.Printf("training model: generation %d", i+1)
 			timestamp := now.Add(time.Duration(i-count-1) * frequency)
 			e.AddModel(ctx, db, instrument, params, frequency, timestamp)
@@ -81,6 +85,47 @@ func NewEnsembleModel(ctx context.Context, db *leveldb.DB, instrument string, pa
 	return e, nil
 }
 
+// LoadEnsembleModel creates an ensemble from saved models instead of
+// training them. The ensemble doesn't retrain, so its models stay as they
+// were saved.
+func LoadEnsembleModel(db *leveldb.DB, filenames []string) (*EnsembleModel, error) {
+	models := []*Model{}
+	for _, filename := range filenames {
+		m, err := LoadFile(db, filename)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("loaded model %s trained on %s to %s", filename, m.From.Format(time.RFC3339), m.To.Format(time.RFC3339))
+		models = append(models, m)
+	}
+
+	e := &EnsembleModel{}
+	if err := e.SetModels(models); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// SetModels replaces the ensemble's models, such as when different saved
+// models are promoted
+func (e *EnsembleModel) SetModels(models []*Model) error {
+	if len(models) == 0 {
+		return fmt.Errorf("no models to load")
+	}
+
+	timestamps := make([]time.Time, len(models))
+	for i, m := range models {
+		timestamps[i] = m.To
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.Models = append([]*Model{}, models...)
+	e.Timestamps = timestamps
+	return nil
+}
+
 func (e *EnsembleModel) EvictModel(index int) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
@@ -126,6 +171,21 @@ func (e *EnsembleModel) AddModel(ctx context.Context, db *leveldb.DB, instrument
 	}
 }
 
+// BarrierScale is how much wider than their take profit and stop loss the
+// barriers of a trade opened at now are. The ensemble's models trade with
+// the same barriers, so the first model's scale is the ensemble's.
+func (e *EnsembleModel) BarrierScale(pw progress.Writer, now time.Time) (float64, error) {
+	e.mutex.Lock()
+	if len(e.Models) == 0 {
+		e.mutex.Unlock()
+		return 1, fmt.Errorf("no models in the ensemble")
+	}
+	m := e.Models[0]
+	e.mutex.Unlock()
+
+	return m.BarrierScale(pw, now)
+}
+
 type StrategyVotes map[Strategy]float64
 
 func (e *EnsembleModel) Predict(pw progress.Writer, now time.Time) (Strategy, StrategyVotes, error) {
@@ -135,15 +195,37 @@ func (e *EnsembleModel) Predict(pw progress.Writer, now time.Time) (Strategy, St
 
 	votes := NewStrategyVotes()
 
-	feature := []float64(nil)
+	// models share features and regimes unless they were loaded with
+	// different params
+	features := map[string][]float64{}
+	regimes := map[string]ta.Regime{}
 	for _, m := range models {
-		f, prediction, err := m.Predict(pw, feature, now)
+		key := fmt.Sprint(m.params)
+		weight := 6*(math.Tanh(m.Metrics.Backtest.Mean.SharpeRatio/3)+1) + 12*(math.Tanh(m.Metrics.Backtest.Mean.SortinoRatio/3)+1)
+
+		// a model stands aside in the regimes it was trained not to trade
+		// in, voting all its weight to hold
+		if len(m.params.RegimeBlacklist) > 0 {
+			regime, ok := regimes[key]
+			if !ok {
+				var err error
+				if regime, err = m.Regime(pw, now); err != nil {
+					return StrategyHold, votes, err
+				}
+				regimes[key] = regime
+			}
+			if m.params.RegimeBlacklist.Contains(regime) {
+				votes.Vote(StrategyHold, weight)
+				continue
+			}
+		}
+
+		f, prediction, err := m.Predict(pw, features[key], now)
 		if err != nil {
 			return StrategyHold, votes, err
 		}
-		feature = f
+		features[key] = f
 
-		weight := 6*(math.Tanh(m.Metrics.Backtest.Mean.SharpeRatio/3)+1) + 12*(math.Tanh(m.Metrics.Backtest.Mean.SortinoRatio/3)+1)
 		for s, v := range prediction {
 			votes.Vote(s, v*weight)
 		}
@@ -0,0 +1,115 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/model"
+	"github.com/grexie/signals/pkg/ta"
+	"github.com/jedib0t/go-pretty/v6/progress"
+)
+
+// Each model stands aside in its own blacklisted regimes, whatever its
+// place in the ensemble
+func TestEnsembleRegimeBlacklist(t *testing.T) {
+	trading := model.NewModelParamsFromDefaults()
+	blacklisted := trading
+	blacklisted.RegimeBlacklist = ta.RegimeSet(ta.Regimes)
+
+	candles := randomCandles(max(model.FeatureWarmup(trading), blacklisted.RegimeWindow+blacklisted.RegimeLookback)+100, 1)
+	db := candleDB(t, candles)
+	now := candles[len(candles)-1].Timestamp.Add(time.Minute)
+
+	weights := testWeights(t, trading)
+	trader, err := model.NewModelFromWeights(db, testInstrument, trading, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	standing, err := model.NewModelFromWeights(db, testInstrument, blacklisted, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, prediction, err := trader.Predict(progress.NewWriter(), nil, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without backtest metrics every model has a weight of 18
+	const weight = 18
+	for name, test := range map[string]struct {
+		models []*model.Model
+		votes  model.StrategyVotes
+	}{
+		"blacklisted": {
+			[]*model.Model{standing},
+			model.StrategyVotes{model.StrategyHold: weight, model.StrategyLong: 0, model.StrategyShort: 0},
+		},
+		"blacklisted first": {
+			[]*model.Model{standing, trader},
+			model.StrategyVotes{
+				model.StrategyHold:  weight + prediction[model.StrategyHold]*weight,
+				model.StrategyLong:  prediction[model.StrategyLong] * weight,
+				model.StrategyShort: prediction[model.StrategyShort] * weight,
+			},
+		},
+		"blacklisted last": {
+			[]*model.Model{trader, standing},
+			model.StrategyVotes{
+				model.StrategyHold:  prediction[model.StrategyHold]*weight + weight,
+				model.StrategyLong:  prediction[model.StrategyLong] * weight,
+				model.StrategyShort: prediction[model.StrategyShort] * weight,
+			},
+		},
+	} {
+		e := &model.EnsembleModel{}
+		if err := e.SetModels(test.models); err != nil {
+			t.Fatal(err)
+		}
+		_, votes, err := e.Predict(nil, now)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for s, v := range test.votes {
+			if diff := votes[s] - v; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("%s: votes %v, expected %v", name, votes, test.votes)
+				break
+			}
+		}
+	}
+}
+
+func TestRegimeBreakdown(t *testing.T) {
+	trade := func(regime ta.Regime, ret float64) model.Trade {
+		return model.Trade{Regime: regime, PercentageReturn: &ret}
+	}
+	b := model.NewRegimeBreakdown()
+	b.AddTrades([]model.Trade{
+		trade(ta.RegimeTrendingUp, 0.02),
+		trade(ta.RegimeTrendingUp, -0.01),
+		trade(ta.RegimeRanging, 0.01),
+		// still open, so not counted
+		{Regime: ta.RegimeRanging},
+	})
+
+	up := b[ta.RegimeTrendingUp]
+	if up.Trades != 2 || up.Wins != 1 || up.WinRate() != 50 || up.MeanReturn() != 0.5 {
+		t.Fatalf("trending up %+v, win rate %v, mean return %v", up, up.WinRate(), up.MeanReturn())
+	}
+	if ranging := b[ta.RegimeRanging]; ranging.Trades != 1 || ranging.Wins != 1 || ranging.Return != 1 {
+		t.Fatalf("ranging %+v", ranging)
+	}
+	if down := b[ta.RegimeTrendingDown]; down.Trades != 0 || down.WinRate() != 0 || down.MeanReturn() != 0 {
+		t.Fatalf("trending down %+v", down)
+	}
+
+	total := model.NewRegimeBreakdown()
+	b[ta.RegimeRanging].Candles = 10
+	total.Add(b)
+	total.Add(b)
+	if ranging := total[ta.RegimeRanging]; ranging.Candles != 20 || ranging.Trades != 2 || ranging.Wins != 2 || ranging.Return != 2 {
+		t.Fatalf("summed ranging %+v", ranging)
+	}
+	if up := total[ta.RegimeTrendingUp]; up.Trades != 4 || up.Wins != 2 {
+		t.Fatalf("summed trending up %+v", up)
+	}
+}
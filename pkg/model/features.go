@@ -0,0 +1,380 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+// normalizationKind says how a feature's raw series is scaled into a model
+// input
+type normalizationKind int
+
+const (
+	// normalizeWindow min-max scales the value against the trailing
+	// WindowSize+1 values of its series, clamped to 0-1
+	normalizeWindow normalizationKind = iota
+	// normalizeScale divides the value by a constant
+	normalizeScale
+	// normalizeRange min-max scales the value against a fixed range, without
+	// clamping
+	normalizeRange
+	// normalizeNone uses the value as is
+	normalizeNone
+)
+
+type normalization struct {
+	kind     normalizationKind
+	min, max float64
+}
+
+func (n normalization) String() string {
+	switch n.kind {
+	case normalizeWindow:
+		return "window"
+	case normalizeScale:
+		return fmt.Sprintf("scale(%g)", n.max)
+	case normalizeRange:
+		return fmt.Sprintf("range(%g,%g)", n.min, n.max)
+	default:
+		return "none"
+	}
+}
+
+// featureSeries is the raw series of a single feature, one value per candle
+type featureSeries struct {
+	name          string
+	values        []float64
+	normalization normalization
+}
+
+func windowed(name string, values []float64) featureSeries {
+	return featureSeries{name, values, normalization{kind: normalizeWindow}}
+}
+
+func scaled(name string, values []float64, scale float64) featureSeries {
+	return featureSeries{name, values, normalization{kind: normalizeScale, max: scale}}
+}
+
+func ranged(name string, values []float64, min, max float64) featureSeries {
+	return featureSeries{name, values, normalization{kind: normalizeRange, min: min, max: max}}
+}
+
+func raw(name string, values []float64) featureSeries {
+	return featureSeries{name, values, normalization{kind: normalizeNone}}
+}
+
+// featureInput holds the candles and their columns, with the candles of any
+// reference instruments, shared by every feature group
+type featureInput struct {
+	params     ModelParams
+	candles    []Candle
+	references References
+	opens      []float64
+	highs      []float64
+	lows       []float64
+	closes     []float64
+	volumes    []float64
+}
+
+func newFeatureInput(candles []Candle, references References, params ModelParams) *featureInput {
+	in := &featureInput{
+		params:     params,
+		candles:    candles,
+		references: references,
+		opens:      make([]float64, len(candles)),
+		highs:      make([]float64, len(candles)),
+		lows:       make([]float64, len(candles)),
+		closes:     make([]float64, len(candles)),
+		volumes:    make([]float64, len(candles)),
+	}
+	for i, candle := range candles {
+		in.opens[i] = candle.Open
+		in.highs[i] = candle.High
+		in.lows[i] = candle.Low
+		in.closes[i] = candle.Close
+		in.volumes[i] = candle.Volume
+	}
+	return in
+}
+
+// series derives a series from each candle index
+func (in *featureInput) series(f func(i int) float64) []float64 {
+	out := make([]float64, len(in.candles))
+	for i := range out {
+		out[i] = f(i)
+	}
+	return out
+}
+
+// featureSpec declares a group of features computed together. warmup is the
+// number of candles before the group's values no longer depend on where the
+// candles start, so serving needs warmup + WindowSize candles of history to
+// reproduce what the model saw in training. Recursive smoothers and
+// path-dependent indicators never fully forget their start, so their warm-up
+// covers convergence to well below the precision the model can see.
+type featureSpec struct {
+	name    string
+	enabled func(params ModelParams) bool
+	warmup  func(params ModelParams) int
+	compute func(in *featureInput) []featureSeries
+}
+
+// featureSpecs is the registry of feature groups, in model input order
+var featureSpecs = []featureSpec{
+	baseFeatures,
+	volumeFeatures,
+	momentumFeatures,
+	volatilityFeatures,
+	priceChangeFeatures,
+	patternFeatures,
+	accelerationFeatures,
+	adxFeatures,
+	parabolicSARFeatures,
+	ichimokuFeatures,
+	superTrendFeatures,
+	keltnerChannelsFeatures,
+	donchianChannelsFeatures,
+	sessionVWAPFeatures,
+	volumeProfileFeatures,
+	candlePatternFeatures,
+	statisticsFeatures,
+	referenceFeatures,
+	calendarFeatures,
+	regimeFeatures,
+	divergenceFeatures,
+	pivotFeatures,
+	supportResistanceFeatures,
+	ohlcvFeatures,
+	timeframeFeatures,
+}
+
+func always(ModelParams) bool {
+	return true
+}
+
+// smoothingWarmup is the warm-up of an average over window values, with
+// recursive averages given thirty windows to converge
+func smoothingWarmup(window int, smoothing ta.Smoothing) int {
+	switch smoothing {
+	case ta.SmoothingSimple, ta.SmoothingWeighted, "":
+		return window + 1
+	case ta.SmoothingHull:
+		return 2 * window
+	default:
+		return 30 * window
+	}
+}
+
+// sessionWarmup is the number of 1m candles in a session
+func sessionWarmup(anchor ta.SessionAnchor) int {
+	return int(anchor.Period / time.Minute)
+}
+
+func enabledFeatureSpecs(params ModelParams) []featureSpec {
+	specs := []featureSpec{}
+	for _, spec := range featureSpecs {
+		if spec.enabled(params) {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// FeatureWarmup returns the number of candles needed before the last one for
+// its features to match those calculated over a longer history
+func FeatureWarmup(params ModelParams) int {
+	warmup := 0
+	for _, spec := range enabledFeatureSpecs(params) {
+		warmup = max(warmup, spec.warmup(params))
+	}
+	return warmup + params.WindowSize + sequenceLength(params) - 1
+}
+
+// featureSet is the calculated raw series of every enabled feature
+type featureSet struct {
+	params ModelParams
+	series []featureSeries
+	byName map[string]featureSeries
+
+	// masks holds each series' validity, and invalid the running count of
+	// invalid values before each index so windows are checked in O(1)
+	masks   []ta.Series
+	invalid [][]int
+}
+
+// calculateFeatures runs every enabled feature group over the candles,
+// calling progress after each group if it's not nil
+func calculateFeatures(candles []Candle, references References, params ModelParams, progress func(group string)) *featureSet {
+	in := newFeatureInput(candles, references, params)
+	set := &featureSet{params: params, byName: map[string]featureSeries{}}
+
+	for _, spec := range enabledFeatureSpecs(params) {
+		for _, s := range spec.compute(in) {
+			if len(s.values) != len(candles) {
+				panic(fmt.Sprintf("feature %s.%s has %d values for %d candles", spec.name, s.name, len(s.values), len(candles)))
+			}
+			s.name = spec.name + "." + s.name
+			set.series = append(set.series, s)
+			set.byName[s.name] = s
+
+			mask := ta.NewSeries(s.values, spec.warmup(params))
+			invalid := make([]int, len(candles)+1)
+			for i, valid := range mask.Valid {
+				invalid[i+1] = invalid[i]
+				if !valid {
+					invalid[i+1]++
+				}
+			}
+			set.masks = append(set.masks, mask)
+			set.invalid = append(set.invalid, invalid)
+		}
+		if progress != nil {
+			progress(spec.name)
+		}
+	}
+
+	return set
+}
+
+// raw returns the unnormalized series of the named feature
+func (f *featureSet) raw(name string) []float64 {
+	s, ok := f.byName[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown feature %s", name))
+	}
+	return s.values
+}
+
+// row returns the normalized features of candle i, which must be at least
+// WindowSize, and whether each is valid. A feature normalized over its
+// window is only valid if the whole window is.
+func (f *featureSet) row(i int) ([]float64, []bool) {
+	row := make([]float64, len(f.series))
+	valid := make([]bool, len(f.series))
+	for j, s := range f.series {
+		v := s.values[i]
+		switch s.normalization.kind {
+		case normalizeWindow:
+			row[j] = normalizeValue(v, s.values[i-f.params.WindowSize:i+1])
+			valid[j] = f.invalid[j][i+1] == f.invalid[j][i-f.params.WindowSize]
+		case normalizeScale:
+			row[j] = v / s.normalization.max
+			valid[j] = f.masks[j].Valid[i]
+		case normalizeRange:
+			row[j] = normalizeValue(v, []float64{s.normalization.min, s.normalization.max})
+			valid[j] = f.masks[j].Valid[i]
+		default:
+			row[j] = v
+			valid[j] = f.masks[j].Valid[i]
+		}
+	}
+	return row, valid
+}
+
+// InvalidRowPolicy says what Prepare does with rows that have invalid
+// features
+type InvalidRowPolicy string
+
+const (
+	// InvalidRowsDrop leaves the row out of the training data
+	InvalidRowsDrop InvalidRowPolicy = "drop"
+	// InvalidRowsImpute carries forward the feature's last valid value, or
+	// 0.5, the middle of the normalized range, if there isn't one yet
+	InvalidRowsImpute InvalidRowPolicy = "impute"
+)
+
+func ParseInvalidRowPolicy(s string) (InvalidRowPolicy, error) {
+	switch v := InvalidRowPolicy(strings.ToLower(s)); v {
+	case InvalidRowsDrop, InvalidRowsImpute:
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown invalid rows policy %q, expected drop or impute", s)
+}
+
+// imputer fills invalid features with the last valid value of each
+type imputer struct {
+	last []float64
+}
+
+func newImputer(features int) *imputer {
+	last := make([]float64, features)
+	for j := range last {
+		last[j] = 0.5
+	}
+	return &imputer{last: last}
+}
+
+// Apply imputes the invalid features of row in place, returning whether the
+// row was already valid
+func (m *imputer) Apply(row []float64, valid []bool) bool {
+	ok := true
+	for j := range row {
+		if valid[j] {
+			m.last[j] = row[j]
+		} else {
+			row[j] = m.last[j]
+			ok = false
+		}
+	}
+	return ok
+}
+
+// rows returns the features of candles from start up to end with invalid
+// features imputed, so there's a row for every candle
+func (f *featureSet) rows(start, end int) [][]float64 {
+	rows := make([][]float64, 0, max(end-start, 0))
+	imputer := newImputer(len(f.series))
+	for i := start; i < end; i++ {
+		row, valid := f.row(i)
+		imputer.Apply(row, valid)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// sequences joins the rows of each candle and the length-1 candles before
+// it, oldest first, into one sample. The samples share one backing array,
+// and the first rows, without enough rows before them, repeat the first.
+func sequences(rows [][]float64, length int) [][]float64 {
+	if length == 1 || len(rows) == 0 {
+		return rows
+	}
+
+	width := len(rows[0])
+	flat := make([]float64, 0, (len(rows)+length-1)*width)
+	for range length - 1 {
+		flat = append(flat, rows[0]...)
+	}
+	for _, row := range rows {
+		flat = append(flat, row...)
+	}
+
+	out := make([][]float64, len(rows))
+	for i := range rows {
+		out[i] = flat[i*width : (i+length)*width : (i+length)*width]
+	}
+	return out
+}
+
+// FeatureNames returns the name and normalization of every model input
+func FeatureNames(params ModelParams) []string {
+	names := []string{}
+	in := newFeatureInput(nil, nil, params)
+	for _, spec := range enabledFeatureSpecs(params) {
+		for _, s := range spec.compute(in) {
+			names = append(names, fmt.Sprintf("%s.%s:%s", spec.name, s.name, s.normalization))
+		}
+	}
+	return names
+}
+
+// FeatureSchemaHash identifies the layout of the model inputs
+func FeatureSchemaHash(params ModelParams) string {
+	sum := sha256.Sum256([]byte(strings.Join(FeatureNames(params), "\n")))
+	return hex.EncodeToString(sum[:])
+}
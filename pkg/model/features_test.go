@@ -0,0 +1,341 @@
+package model_test
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/model"
+	"github.com/grexie/signals/pkg/ta"
+	"github.com/jedib0t/go-pretty/v6/progress"
+)
+
+// randomCandles generates a reproducible random walk of 1m candles
+func randomCandles(n int, seed int64) []model.Candle {
+	r := rand.New(rand.NewSource(seed))
+	out := make([]model.Candle, n)
+	price := 0.3
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range out {
+		open := price
+		price *= 1 + (r.Float64()*2-1)*0.002
+		out[i] = model.Candle{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Open:      open,
+			High:      math.Max(open, price) * (1 + r.Float64()*0.001),
+			Low:       math.Min(open, price) * (1 - r.Float64()*0.001),
+			Close:     price,
+			Volume:    1000 + r.Float64()*5000,
+		}
+	}
+	return out
+}
+
+func allFeatures(params model.ModelParams) model.ModelParams {
+	params.ADXEnabled = true
+	params.ParabolicSAREnabled = true
+	params.IchimokuEnabled = true
+	params.SuperTrendEnabled = true
+	params.KeltnerChannelsEnabled = true
+	params.DonchianChannelsEnabled = true
+	params.SessionVWAPEnabled = true
+	params.VolumeProfileEnabled = true
+	params.CandlePatterns = ta.CandlePatternSet(ta.CandlePatterns)
+	params.StatisticsEnabled = true
+	params.ReferenceInstruments = model.Instruments{"BTC-USDT-SWAP"}
+	params.CalendarEnabled = true
+	params.RegimeEnabled = true
+	params.FracDiffEnabled = true
+	params.FracDiffOrder = 0.4
+	params.DivergenceEnabled = true
+	params.LevelsEnabled = true
+	params.TimeframeFeatures, _ = model.ParseTimeframes("15m:momentum,1h:base.rsi_long")
+	return params
+}
+
+// Serving calculates features from FeatureWarmup candles of history, while
+// training sees the whole range. Both must give the model the same inputs,
+// other than the base VWAP, which is cumulative from the first candle; the
+// session VWAP group is its anchored equivalent.
+func TestFeatureParity(t *testing.T) {
+	defaults := model.NewModelParamsFromDefaults()
+	sequence := defaults
+	sequence.Architecture = model.ArchitectureLSTM
+	sequence.SequenceLength = 5
+	tcn := sequence
+	tcn.Architecture = model.ArchitectureTCN
+
+	for name, params := range map[string]model.ModelParams{
+		"defaults": defaults,
+		"all":      allFeatures(defaults),
+		"sequence": sequence,
+		"tcn":      tcn,
+	} {
+		warmup := model.FeatureWarmup(params)
+		candles := randomCandles(warmup+3000, 1)
+		// a reference with gaps, which carry its last close forward
+		reference := []model.Candle{}
+		for i, c := range randomCandles(len(candles), 3) {
+			if i%50 != 7 {
+				reference = append(reference, c)
+			}
+		}
+		references := model.References{"BTC-USDT-SWAP": reference}
+		training := model.PrepareForPrediction(candles, references, params)
+
+		names := model.FeatureNames(params)
+		if len(training[0])%len(names) != 0 {
+			t.Fatalf("%s: %d feature names for %d features", name, len(names), len(training[0]))
+		}
+
+		for i := warmup; i < len(candles); i += 97 {
+			serving := model.PrepareForPrediction(candles[i-warmup:i+1], references, params)
+			got := serving[len(serving)-1]
+			want := training[i-params.WindowSize]
+
+			for j := range want {
+				if strings.HasPrefix(names[j%len(names)], "base.vwap:") {
+					continue
+				}
+				if math.IsNaN(want[j]) && math.IsNaN(got[j]) {
+					continue
+				}
+				if math.Abs(got[j]-want[j]) > 1e-9*math.Max(1, math.Abs(want[j])) {
+					t.Fatalf("%s: candle %d feature %s got %v, want %v", name, i, names[j%len(names)], got[j], want[j])
+				}
+			}
+		}
+	}
+}
+
+func TestFeatureSchemaHash(t *testing.T) {
+	defaults := model.NewModelParamsFromDefaults()
+	if model.FeatureSchemaHash(defaults) == model.FeatureSchemaHash(allFeatures(defaults)) {
+		t.Fatalf("enabling feature groups should change the schema hash")
+	}
+	if model.FeatureSchemaHash(defaults) != model.FeatureSchemaHash(model.NewModelParamsFromDefaults()) {
+		t.Fatalf("schema hash should be stable")
+	}
+}
+
+func TestPrepareDropsInvalidRows(t *testing.T) {
+	params := model.NewModelParamsFromDefaults()
+	candles := randomCandles(model.FeatureWarmup(params)+500, 2)
+	pw := progress.NewWriter()
+
+	// a broken volume invalidates the volume features from then on
+	broken := slices.Clone(candles)
+	k := model.FeatureWarmup(params) + 200
+	broken[k].Volume = math.Inf(1)
+
+	rows := map[model.InvalidRowPolicy]int{}
+	for _, policy := range []model.InvalidRowPolicy{model.InvalidRowsDrop, model.InvalidRowsImpute} {
+		params.InvalidRows = policy
+		features, labels := model.Prepare(pw, broken, nil, params)
+		if len(features) == 0 || len(features) != len(labels) {
+			t.Fatalf("%s: %d rows, %d labels", policy, len(features), len(labels))
+		}
+		for i, row := range features {
+			for j, v := range row {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					t.Fatalf("%s: row %d feature %s is %v", policy, i, model.FeatureNames(params)[j], v)
+				}
+			}
+		}
+		rows[policy] = len(features)
+	}
+	if rows[model.InvalidRowsDrop] >= rows[model.InvalidRowsImpute] {
+		t.Errorf("dropped %d rows and imputed %d, expected invalid rows to be dropped", rows[model.InvalidRowsDrop], rows[model.InvalidRowsImpute])
+	}
+
+	// the volume is invalid while it's in the window it's normalized over,
+	// so its last valid value carries forward until it leaves
+	column := slices.Index(model.FeatureNames(params), "volume.volume:window")
+	want := model.PrepareForPrediction(candles, nil, params)
+	got := model.PrepareForPrediction(broken, nil, params)
+	first := k - params.WindowSize
+	for r := first; r < len(got); r++ {
+		expected := want[r][column]
+		if r <= first+params.WindowSize {
+			expected = got[first-1][column]
+		}
+		if got[r][column] != expected {
+			t.Fatalf("row %d volume is %v, want %v", r, got[r][column], expected)
+		}
+	}
+}
+
+func TestTripleBarrierLabeler(t *testing.T) {
+	params := model.NewModelParamsFromDefaults()
+	params.TakeProfit = 0.02
+	params.StopLoss = 0.01
+	params.Candles = 3
+
+	// flat candles at 100 with the given highs and lows after the first
+	path := func(highs, lows []float64) []model.Candle {
+		candles := []model.Candle{{Open: 100, High: 100, Low: 100, Close: 100}}
+		for i := range highs {
+			candles = append(candles, model.Candle{Open: 100, High: highs[i], Low: lows[i], Close: 100})
+		}
+		return candles
+	}
+
+	for _, test := range []struct {
+		name        string
+		highs, lows []float64
+		want        model.Strategy
+	}{
+		{"take profit above", []float64{101, 102.5, 100}, []float64{99.5, 99.5, 99.5}, model.StrategyLong},
+		{"take profit below", []float64{100.5, 100.5, 100}, []float64{99.5, 97.5, 99.5}, model.StrategyShort},
+		{"both stopped", []float64{100.5, 102.5, 100}, []float64{98.9, 99.5, 99.5}, model.StrategyHold},
+		{"stop loss and take profit in one candle", []float64{102.5, 100, 100}, []float64{98.9, 100, 100}, model.StrategyHold},
+		{"both in one candle", []float64{102.5, 100, 100}, []float64{97.5, 100, 100}, model.StrategyHold},
+		{"time barrier", []float64{101, 101, 101, 103}, []float64{99.5, 99.5, 99.5, 99.5}, model.StrategyHold},
+	} {
+		if got := model.NewTripleBarrierLabeler(path(test.highs, test.lows), params).Label(0); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// the range halves after candle 300 and quadruples after 400, so the
+	// barriers are narrower than fixed ones at 350 and wider at 450, scaled
+	// by the volatility of the candles up to them
+	params.VolatilityBarriers = true
+	params.BarrierVolatilityWindow = 5
+	candles := make([]model.Candle, 500)
+	for i := range candles {
+		r := 0.002
+		switch {
+		case i >= 400:
+			r = 0.004
+		case i >= 300:
+			r = 0.001
+		}
+		candles[i] = model.Candle{Open: 100, High: 100 * (1 + r), Low: 100 * (1 - r), Close: 100}
+	}
+	candles[351].High = 101.6
+	candles[451].High = 102.2
+
+	fixed := params
+	fixed.VolatilityBarriers = false
+	for _, test := range []struct {
+		i           int
+		fixed, want model.Strategy
+	}{
+		{350, model.StrategyHold, model.StrategyLong},
+		{450, model.StrategyLong, model.StrategyHold},
+	} {
+		if got := model.NewTripleBarrierLabeler(candles, fixed).Label(test.i); got != test.fixed {
+			t.Errorf("candle %d with fixed barriers: got %v, want %v", test.i, got, test.fixed)
+		}
+		if got := model.NewTripleBarrierLabeler(candles, params).Label(test.i); got != test.want {
+			t.Errorf("candle %d with volatility barriers: got %v, want %v", test.i, got, test.want)
+		}
+		// the candles after the time barrier don't change the label
+		if got := model.NewTripleBarrierLabeler(candles[:test.i+params.Candles+1], params).Label(test.i); got != test.want {
+			t.Errorf("candle %d with only the candles to its time barrier: got %v, want %v", test.i, got, test.want)
+		}
+	}
+}
+
+func TestCalendarFeatures(t *testing.T) {
+	params := model.NewModelParamsFromDefaults()
+	params.CalendarEnabled = true
+
+	candles := randomCandles(6000, 1)
+	rows := model.PrepareForPrediction(candles, nil, params)
+	names := model.FeatureNames(params)
+
+	for _, test := range []struct {
+		at   time.Time
+		want map[string]float64
+	}{
+		{
+			// Saturday at 06:00 UTC, two hours before funding
+			time.Date(2025, 1, 4, 6, 0, 0, 0, time.UTC),
+			map[string]float64{
+				// a quarter of the way through the day, normalized from -1
+				// to 1
+				"calendar.hour_sin:range(-1,1)": 1,
+				"calendar.hour_cos:range(-1,1)": 0.5,
+				// three quarters of the way through the week from Monday
+				"calendar.weekday_sin:range(-1,1)": 0,
+				"calendar.weekday_cos:range(-1,1)": 0.5,
+				// 120 of the 480 minutes between fundings
+				"calendar.funding_minutes:range(0,480)": 0.25,
+				"calendar.weekend:none":                 1,
+			},
+		},
+		{
+			// Wednesday at 12:00 UTC, four hours before funding
+			time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			map[string]float64{
+				"calendar.hour_sin:range(-1,1)":         0.5,
+				"calendar.hour_cos:range(-1,1)":         0,
+				"calendar.funding_minutes:range(0,480)": 0.5,
+				"calendar.weekend:none":                 0,
+			},
+		},
+	} {
+		i := int(test.at.Sub(candles[0].Timestamp) / time.Minute)
+		row := rows[i-params.WindowSize]
+		for name, want := range test.want {
+			j := slices.Index(names, name)
+			if j < 0 {
+				t.Fatalf("no feature %s in %v", name, names)
+			}
+			if math.Abs(row[j]-want) > 1e-9 {
+				t.Errorf("%v: %s is %v, want %v", test.at, name, row[j], want)
+			}
+		}
+	}
+}
+
+func TestParseTimeframes(t *testing.T) {
+	got, err := model.ParseTimeframes(" 15m:base.rsi_long, 4H:volatility,none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "15m:base.rsi_long,4h:volatility" {
+		t.Fatalf("parsed %s", got)
+	}
+	if pattern, err := model.ParseTimeframes("1h:candle_pattern.morning_star"); err != nil || len(pattern) != 1 {
+		t.Fatalf("pattern feature: %v %v", pattern, err)
+	}
+
+	for _, s := range []string{"15m", "1m:base", "90s:base", "15m:nosuch", "15m:base.nosuch", "15m:timeframe", "1h:reference"} {
+		if _, err := model.ParseTimeframes(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+// Serving can't find the fractional differencing order on its own candles,
+// as it would differ from the order training found
+func TestUnresolvedFracDiffOrder(t *testing.T) {
+	params := model.NewModelParamsFromDefaults()
+	params.FracDiffEnabled = true
+	params.FracDiffOrder = 0
+	candles := randomCandles(model.FeatureWarmup(params)+100, 1)
+
+	if len(model.FeatureNames(params)) == 0 {
+		t.Fatal("no feature names")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("calculated features with an unresolved order")
+			}
+		}()
+		model.PrepareForPrediction(candles, nil, params)
+	}()
+
+	resolved := model.ResolveFracDiffOrder(candles, params)
+	if resolved.FracDiffOrder <= 0 {
+		t.Fatalf("resolved order %v", resolved.FracDiffOrder)
+	}
+	model.PrepareForPrediction(candles, nil, resolved)
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+const (
+	// lagged differences in the ADF test for the fractional differencing
+	// order
+	fracDiffADFLags = 1
+	// resolution of the search for the fractional differencing order
+	fracDiffStep = 0.05
+)
+
+// ResolveFracDiffOrder sets an automatic fractional differencing order to
+// the smallest that makes the log closes stationary. The order depends on
+// the candles, so it's found once on the training candles and kept in the
+// model's params for serving.
+func ResolveFracDiffOrder(candles []Candle, params ModelParams) ModelParams {
+	if !params.FracDiffEnabled || params.FracDiffOrder > 0 {
+		return params
+	}
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	params.FracDiffOrder = ta.MinFracDiffOrder(logPrices(closes), params.FracDiffWindow, fracDiffADFLags, fracDiffStep)
+	return params
+}
+
+func fracDiffWarmup(params ModelParams) int {
+	if !params.FracDiffEnabled {
+		return 0
+	}
+	return 2 * params.FracDiffWindow
+}
+
+// priceLevel returns a price-level feature, min-max scaled over the window,
+// or with SIGNALS_FRACDIFF_ENABLED the z-score of its fractionally
+// differentiated log, which is stationary but keeps its memory
+func priceLevel(in *featureInput, name string, values []float64) featureSeries {
+	p := in.params
+	if !p.FracDiffEnabled {
+		return windowed(name, values)
+	}
+	if p.FracDiffOrder <= 0 && len(values) > 0 {
+		// resolving it here would find another order on the serving
+		// candles than training did. Only listing the feature names, which
+		// calculates no values, can leave it unresolved.
+		panic(fmt.Sprintf("fractional differencing order of %s isn't resolved, see ResolveFracDiffOrder", name))
+	}
+	// skip the values still warming up, whose jump from zero would swamp the
+	// z-scores
+	first := len(values)
+	for i, v := range values {
+		if v > 0 {
+			first = i
+			break
+		}
+	}
+	out := make([]float64, len(values))
+	diffed := ta.FracDiff(logPrices(values[first:]), p.FracDiffOrder, p.FracDiffWindow)
+	copy(out[first:], ta.ZScore(diffed, p.FracDiffWindow))
+	return ranged(name, out, -3, 3)
+}
+
+// logPrices returns the log of each price, zero while it's warming up
+func logPrices(prices []float64) []float64 {
+	out := make([]float64, len(prices))
+	for i, p := range prices {
+		if p > 0 {
+			out[i] = math.Log(p)
+		}
+	}
+	return out
+}
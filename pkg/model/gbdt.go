@@ -0,0 +1,415 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"gorgonia.org/tensor"
+)
+
+const (
+	// gbdtBins is the most bins a feature's values are bucketed into when
+	// searching for splits
+	gbdtBins = 64
+	// gbdtMinLeaf is the fewest training samples a leaf can have
+	gbdtMinLeaf = 20
+	// gbdtPatience is the number of rounds the validation loss can go
+	// without improving before training stops
+	gbdtPatience = 10
+)
+
+// The GBDT weights are the log prior of each class as a 1x3 matrix, then a
+// tree per class for each boosting round. A tree is a matrix with a row for
+// each node, the root first, whose columns are below. Leaves have a feature
+// of -1.
+const (
+	gbdtFeature = iota
+	gbdtThreshold
+	gbdtLeft
+	gbdtRight
+	gbdtValue
+	gbdtGain
+	gbdtColumns
+)
+
+// gbdtClassifier is a gradient boosted ensemble of decision trees over the
+// features of the latest candle. Each round fits a tree per class to the
+// gradient and hessian of the softmax cross entropy on a subsample of the
+// training samples, searching for splits over histograms of the features.
+// Class weights scale each sample's gradient and hessian, as does focal
+// loss's (1-p)^FocalGamma, held constant over each round.
+type gbdtClassifier struct{}
+
+func (gbdtClassifier) sequential() bool {
+	return false
+}
+
+// train boosts for up to GBDTTrees rounds rather than epochs, stopping early
+// on a validation set like the networks
+func (gbdtClassifier) train(tracker *progress.Tracker, params ModelParams, features [][]float64, labels []float64, _ int) ([]tensor.Tensor, error) {
+	tracker.UpdateTotal(int64(params.GBDTTrees))
+
+	tracker.Message = fmt.Sprintf("Rebalancing classes (%s)", params.ClassImbalance)
+	features, labels, trainIndices, validIndices := splitValidation(params, features, labels, len(features)/10)
+	if len(trainIndices) < 2*gbdtMinLeaf {
+		return nil, fmt.Errorf("need at least %d samples to train trees, got %d", 2*gbdtMinLeaf, len(trainIndices))
+	}
+
+	counts := make([]float64, outputSize)
+	for _, i := range trainIndices {
+		counts[int(labels[i])]++
+	}
+	prior := make([]float64, outputSize)
+	for k := range prior {
+		prior[k] = math.Log((counts[k] + 1) / (float64(len(trainIndices)) + outputSize))
+	}
+	weights := []tensor.Tensor{tensor.New(tensor.WithShape(1, outputSize), tensor.WithBacking(slices.Clone(prior)))}
+
+	scores := make([][]float64, len(features))
+	for i := range scores {
+		scores[i] = slices.Clone(prior)
+	}
+
+	classWeights := classWeights(params, labels, trainIndices)
+
+	bins := newGBDTBins(features, trainIndices)
+	g := make([][]float64, outputSize)
+	h := make([][]float64, outputSize)
+	for k := range outputSize {
+		g[k] = make([]float64, len(features))
+		h[k] = make([]float64, len(features))
+	}
+
+	bestLoss := math.Inf(1)
+	bestRounds := 0
+	for round := range params.GBDTTrees {
+		for _, i := range trainIndices {
+			p := softmax(scores[i])
+			label := int(labels[i])
+			w := classWeights[label] * focalFactor(params, p[label])
+			for k := range outputSize {
+				g[k][i] = w * (p[k] - boolToFloat(label == k))
+				h[k][i] = w * math.Max(p[k]*(1-p[k]), 1e-6)
+			}
+		}
+
+		sample := trainIndices
+		if params.GBDTSubsample < 1 {
+			sample = make([]int, 0, len(trainIndices))
+			for _, i := range trainIndices {
+				if rand.Float64() < params.GBDTSubsample {
+					sample = append(sample, i)
+				}
+			}
+		}
+
+		for k := range outputSize {
+			tree := bins.tree(params, sample, g[k], h[k])
+			weights = append(weights, tree)
+			for i, f := range features {
+				scores[i][k] += gbdtScore(tree, f)
+			}
+		}
+
+		validLoss := 0.0
+		for _, i := range validIndices {
+			label := int(labels[i])
+			p := softmax(scores[i])[label]
+			validLoss -= classWeights[label] * focalFactor(params, p) * math.Log(math.Max(p, 1e-15))
+		}
+		validLoss /= float64(len(validIndices))
+
+		if validLoss < bestLoss {
+			bestLoss = validLoss
+			bestRounds = round + 1
+		}
+
+		tracker.Message = fmt.Sprintf("Training - VL: %.6f", validLoss)
+		tracker.SetValue(int64(round + 1))
+
+		if round+1-bestRounds >= gbdtPatience {
+			break
+		}
+	}
+
+	return weights[:1+bestRounds*outputSize], nil
+}
+
+func (gbdtClassifier) predictor(_ ModelParams, weights []tensor.Tensor) batchPredictor {
+	prior := weights[0].Data().([]float64)
+	width := 0
+	for _, tree := range weights[1:] {
+		nodes := tree.Data().([]float64)
+		for node := 0; node < len(nodes); node += gbdtColumns {
+			width = max(width, int(nodes[node+gbdtFeature])+1)
+		}
+	}
+
+	return func(features [][]float64) ([][]float64, error) {
+		out := make([][]float64, len(features))
+		for i, input := range features {
+			if len(input) < width {
+				return nil, fmt.Errorf("sample has %d features, the trees split on %d", len(input), width)
+			}
+			scores := slices.Clone(prior)
+			for t, tree := range weights[1:] {
+				scores[t%outputSize] += gbdtScore(tree, input)
+			}
+			out[i] = softmax(scores)
+		}
+		return out, nil
+	}
+}
+
+// validateGBDT checks the weights are a prior and whole rounds of trees
+// whose children follow their parents, so following a sample down a tree
+// ends at a leaf
+func validateGBDT(weights []tensor.Tensor) error {
+	if len(weights) == 0 || weights[0].Shape().TotalSize() != outputSize {
+		return fmt.Errorf("gbdt weights don't start with a prior of %d classes", outputSize)
+	}
+	if (len(weights)-1)%outputSize != 0 {
+		return fmt.Errorf("gbdt has %d trees, not a tree per class for each round", len(weights)-1)
+	}
+	for t, tree := range weights[1:] {
+		shape := tree.Shape()
+		if len(shape) != 2 || shape[0] == 0 || shape[1] != gbdtColumns {
+			return fmt.Errorf("gbdt tree %d has shape %v, expected nodes x %d", t, shape, gbdtColumns)
+		}
+		nodes := tree.Data().([]float64)
+		for node := range shape[0] {
+			row := nodes[node*gbdtColumns : (node+1)*gbdtColumns]
+			if row[gbdtFeature] < 0 {
+				continue
+			}
+			for _, child := range []float64{row[gbdtLeft], row[gbdtRight]} {
+				if int(child) <= node || int(child) >= shape[0] {
+					return fmt.Errorf("gbdt tree %d node %d has child %v outside the tree", t, node, child)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// gbdtScore follows the sample down the tree, returning its leaf's value
+func gbdtScore(tree tensor.Tensor, input []float64) float64 {
+	nodes := tree.Data().([]float64)
+	node := 0
+	for {
+		row := nodes[node*gbdtColumns : (node+1)*gbdtColumns]
+		if row[gbdtFeature] < 0 {
+			return row[gbdtValue]
+		}
+		if input[int(row[gbdtFeature])] <= row[gbdtThreshold] {
+			node = int(row[gbdtLeft])
+		} else {
+			node = int(row[gbdtRight])
+		}
+	}
+}
+
+// gbdtBinned is every sample's features bucketed into quantile bins of the
+// training samples. A value is in the first bin whose upper edge it doesn't
+// exceed, so splitting after bin b is the same as comparing the value to
+// edges[b].
+type gbdtBinned struct {
+	edges [][]float64
+	bins  [][]uint8
+}
+
+func newGBDTBins(features [][]float64, trainIndices []int) *gbdtBinned {
+	width := len(features[0])
+	b := &gbdtBinned{
+		edges: make([][]float64, width),
+		bins:  make([][]uint8, width),
+	}
+
+	var wg sync.WaitGroup
+	for f := range width {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			values := make([]float64, len(trainIndices))
+			for j, i := range trainIndices {
+				values[j] = features[i][f]
+			}
+			sort.Float64s(values)
+
+			edges := []float64{}
+			for q := 1; q < gbdtBins; q++ {
+				edge := values[q*len(values)/gbdtBins]
+				if len(edges) == 0 || edge > edges[len(edges)-1] {
+					edges = append(edges, edge)
+				}
+			}
+
+			bins := make([]uint8, len(features))
+			for i, row := range features {
+				bins[i] = uint8(sort.SearchFloat64s(edges, row[f]))
+			}
+			b.edges[f], b.bins[f] = edges, bins
+		}()
+	}
+	wg.Wait()
+
+	return b
+}
+
+type gbdtSplit struct {
+	feature int
+	bin     int
+	gain    float64
+}
+
+// bestSplit finds the split of the samples with the highest gain, searching
+// the features in parallel
+func (b *gbdtBinned) bestSplit(rows []int, g, h []float64, lambda float64) gbdtSplit {
+	sumG, sumH := 0.0, 0.0
+	for _, i := range rows {
+		sumG += g[i]
+		sumH += h[i]
+	}
+	parent := sumG * sumG / (sumH + lambda)
+
+	splits := make([]gbdtSplit, len(b.bins))
+	var wg sync.WaitGroup
+	for f, bins := range b.bins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var histG, histH [gbdtBins]float64
+			var histN [gbdtBins]int
+			for _, i := range rows {
+				histG[bins[i]] += g[i]
+				histH[bins[i]] += h[i]
+				histN[bins[i]]++
+			}
+
+			best := gbdtSplit{feature: f}
+			leftG, leftH, leftN := 0.0, 0.0, 0
+			for bin := range b.edges[f] {
+				leftG += histG[bin]
+				leftH += histH[bin]
+				leftN += histN[bin]
+				if leftN < gbdtMinLeaf {
+					continue
+				}
+				if len(rows)-leftN < gbdtMinLeaf {
+					break
+				}
+				rightG, rightH := sumG-leftG, sumH-leftH
+				gain := (leftG*leftG/(leftH+lambda) + rightG*rightG/(rightH+lambda) - parent) / 2
+				if gain > best.gain {
+					best.bin, best.gain = bin, gain
+				}
+			}
+			splits[f] = best
+		}()
+	}
+	wg.Wait()
+
+	best := gbdtSplit{feature: -1}
+	for _, split := range splits {
+		if split.gain > best.gain {
+			best = split
+		}
+	}
+	return best
+}
+
+// tree grows a tree to GBDTDepth over the samples, fit to the gradients g
+// and hessians h of one class
+func (b *gbdtBinned) tree(params ModelParams, rows []int, g, h []float64) tensor.Tensor {
+	nodes := [][gbdtColumns]float64{}
+
+	var grow func(rows []int, depth int) int
+	grow = func(rows []int, depth int) int {
+		sumG, sumH := 0.0, 0.0
+		for _, i := range rows {
+			sumG += g[i]
+			sumH += h[i]
+		}
+
+		index := len(nodes)
+		nodes = append(nodes, [gbdtColumns]float64{
+			gbdtFeature: -1,
+			gbdtValue:   -params.GBDTLearnRate * sumG / (sumH + params.GBDTL2),
+		})
+		if depth == params.GBDTDepth || len(rows) < 2*gbdtMinLeaf {
+			return index
+		}
+
+		split := b.bestSplit(rows, g, h, params.GBDTL2)
+		if split.feature < 0 {
+			return index
+		}
+
+		left, right := []int{}, []int{}
+		for _, i := range rows {
+			if int(b.bins[split.feature][i]) <= split.bin {
+				left = append(left, i)
+			} else {
+				right = append(right, i)
+			}
+		}
+
+		nodes[index][gbdtFeature] = float64(split.feature)
+		nodes[index][gbdtThreshold] = b.edges[split.feature][split.bin]
+		nodes[index][gbdtGain] = split.gain
+		nodes[index][gbdtLeft] = float64(grow(left, depth+1))
+		nodes[index][gbdtRight] = float64(grow(right, depth+1))
+		return index
+	}
+	grow(rows, 0)
+
+	data := make([]float64, 0, len(nodes)*gbdtColumns)
+	for _, node := range nodes {
+		data = append(data, node[:]...)
+	}
+	return tensor.New(tensor.WithShape(len(nodes), gbdtColumns), tensor.WithBacking(data))
+}
+
+// FeatureImportance is a feature's share of the total gain of a tree model's
+// splits
+type FeatureImportance struct {
+	Name string
+	Gain float64
+}
+
+// featureImportance returns the features of a tree model by their share of
+// the gain, highest first, or nil for a network
+func featureImportance(params ModelParams, weights []tensor.Tensor) []FeatureImportance {
+	if params.Architecture != ArchitectureGBDT {
+		return nil
+	}
+
+	names := FeatureNames(params)
+	gains := make([]float64, len(names))
+	total := 0.0
+	for _, tree := range weights[1:] {
+		nodes := tree.Data().([]float64)
+		for node := 0; node < len(nodes); node += gbdtColumns {
+			if feature := int(nodes[node+gbdtFeature]); feature >= 0 && feature < len(gains) {
+				gains[feature] += nodes[node+gbdtGain]
+				total += nodes[node+gbdtGain]
+			}
+		}
+	}
+
+	importance := make([]FeatureImportance, len(names))
+	for i, name := range names {
+		importance[i] = FeatureImportance{name, gains[i] / math.Max(total, 1e-12)}
+	}
+	sort.SliceStable(importance, func(i, j int) bool {
+		return importance[i].Gain > importance[j].Gain
+	})
+	return importance
+}
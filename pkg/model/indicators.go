@@ -0,0 +1,186 @@
+package model
+
+import "github.com/grexie/signals/pkg/ta"
+
+// Base technical indicators
+var baseFeatures = featureSpec{
+	name:    "base",
+	enabled: always,
+	warmup: func(p ModelParams) int {
+		return fracDiffWarmup(p) + max(
+			p.LongMovingAverageLength+1,
+			p.ShortMovingAverageLength+1,
+			smoothingWarmup(p.LongRSILength, p.RSISmoothing)+p.RSISlope,
+			smoothingWarmup(p.LongMACDWindowLength, p.MACDSmoothing)+smoothingWarmup(p.MACDSignalWindow, p.MACDSmoothing),
+			smoothingWarmup(p.FastLongMACDWindowLength, p.MACDSmoothing)+smoothingWarmup(p.FastMACDSignalWindow, p.MACDSmoothing),
+			smoothingWarmup(p.BollingerBandsWindow, p.BollingerBandsSmoothing),
+			p.StochasticOscillatorWindow+3,
+		)
+	},
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		ma50 := ta.MovingAverage(in.closes, p.ShortMovingAverageLength)
+		ma200 := ta.MovingAverage(in.closes, p.LongMovingAverageLength)
+		rsi14 := ta.RSI(in.closes, p.LongRSILength, p.RSISmoothing)
+		rsi5 := ta.RSI(in.closes, p.ShortRSILength, p.RSISmoothing) // Short-term RSI for quick movements
+		macd, macdSignal := ta.MACD(in.closes, p.ShortMACDWindowLength, p.LongMACDWindowLength, p.MACDSignalWindow, p.MACDSmoothing)
+		macdFast, macdFastSignal := ta.MACD(in.closes, p.FastShortMACDWindowLength, p.FastLongMACDWindowLength, p.FastMACDSignalWindow, p.MACDSmoothing) // Faster MACD
+		ma20, bbUpper, bbLower := ta.BollingerBands(in.closes, p.BollingerBandsWindow, p.BollingerBandsMultiplier, p.BollingerBandsSmoothing)
+		stochK, stochD := ta.StochasticOscillator(in.closes, in.lows, in.highs, p.StochasticOscillatorWindow)
+		vwap := ta.VWAP(in.closes, in.volumes)
+
+		// RSI change per candle, centred on 0.5. The labels compare it with
+		// 0.5, while the feature is scaled down by 100 as training always has.
+		rsiSlope := in.series(func(i int) float64 {
+			if i < p.RSISlope {
+				return 0.5
+			}
+			return (rsi14[i]-rsi14[i-p.RSISlope])/float64(100*p.RSISlope) + 0.5
+		})
+
+		return []featureSeries{
+			priceLevel(in, "close", in.closes),
+			priceLevel(in, "ma_short", ma50),
+			priceLevel(in, "ma_long", ma200),
+			scaled("rsi_long", rsi14, 100),
+			scaled("rsi_short", rsi5, 100),
+			scaled("rsi_slope", rsiSlope, 100),
+			windowed("macd", macd),
+			windowed("macd_signal", macdSignal),
+			windowed("macd_fast", macdFast),
+			windowed("macd_fast_signal", macdFastSignal),
+			priceLevel(in, "bb_middle", ma20),
+			priceLevel(in, "bb_upper", bbUpper),
+			priceLevel(in, "bb_lower", bbLower),
+			scaled("stoch_k", stochK, 100),
+			scaled("stoch_d", stochD, 100),
+			priceLevel(in, "vwap", vwap),
+		}
+	},
+}
+
+// Volume indicators
+var volumeFeatures = featureSpec{
+	name:    "volume",
+	enabled: always,
+	warmup: func(p ModelParams) int {
+		return max(
+			p.VolumesMovingAverageLength+1,
+			p.OBVMovingAverageLength+2,
+			p.ChaikinMoneyFlowPeriod+1,
+			p.MoneyFlowIndexPeriod+1,
+		)
+	},
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		obv := ta.OBV(in.closes, in.volumes)
+		obvEma := ta.MovingAverage(obv, p.OBVMovingAverageLength)
+		vwma := ta.MovingAverage(in.volumes, p.VolumesMovingAverageLength)
+		cmf := ta.ChaikinMoneyFlow(in.highs, in.lows, in.closes, in.volumes, p.ChaikinMoneyFlowPeriod)
+		mfi := ta.MoneyFlowIndex(in.highs, in.lows, in.closes, in.volumes, p.MoneyFlowIndexPeriod)
+
+		return []featureSeries{
+			windowed("volume", in.volumes),
+			windowed("vwma", vwma),
+			windowed("obv", obv),
+			windowed("obv_ma", obvEma),
+			windowed("cmf", cmf),
+			windowed("mfi", mfi),
+		}
+	},
+}
+
+// Momentum indicators
+var momentumFeatures = featureSpec{
+	name:    "momentum",
+	enabled: always,
+	warmup: func(p ModelParams) int {
+		return max(p.RateOfChangePeriod, p.CCIPeriod, p.WilliamsRPeriod) + 1
+	},
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		return []featureSeries{
+			windowed("roc", ta.RateOfChange(in.closes, p.RateOfChangePeriod)),
+			windowed("cci", ta.CCI(in.highs, in.lows, in.closes, p.CCIPeriod)),
+			windowed("williams_r", ta.WilliamsR(in.highs, in.lows, in.closes, p.WilliamsRPeriod)),
+		}
+	},
+}
+
+// Volatility indicators
+var volatilityFeatures = featureSpec{
+	name:    "volatility",
+	enabled: always,
+	warmup: func(p ModelParams) int {
+		return smoothingWarmup(max(p.FastATRPeriod, p.SlowATRPeriod), p.ATRSmoothing) + 1
+	},
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		return []featureSeries{
+			windowed("atr_fast", ta.ATR(in.highs, in.lows, in.closes, p.FastATRPeriod, p.ATRSmoothing)),
+			windowed("atr_slow", ta.ATR(in.highs, in.lows, in.closes, p.SlowATRPeriod, p.ATRSmoothing)),
+		}
+	},
+}
+
+// Price changes over different timeframes
+var priceChangeFeatures = featureSpec{
+	name:    "price_change",
+	enabled: always,
+	warmup: func(p ModelParams) int {
+		return max(p.PriceChangeFastPeriod, p.PriceChangeMediumPeriod, p.PriceChangeSlowPeriod) + 1
+	},
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		return []featureSeries{
+			windowed("fast", ta.PriceChanges(in.closes, p.PriceChangeFastPeriod)),
+			windowed("medium", ta.PriceChanges(in.closes, p.PriceChangeMediumPeriod)),
+			windowed("slow", ta.PriceChanges(in.closes, p.PriceChangeSlowPeriod)),
+		}
+	},
+}
+
+// Pattern recognition
+var patternFeatures = featureSpec{
+	name:    "pattern",
+	enabled: always,
+	warmup: func(p ModelParams) int {
+		return max(2, smoothingWarmup(p.BollingerBandsWindow, p.BollingerBandsSmoothing))
+	},
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		_, bbUpper, bbLower := ta.BollingerBands(in.closes, p.BollingerBandsWindow, p.BollingerBandsMultiplier, p.BollingerBandsSmoothing)
+
+		return []featureSeries{
+			raw("doji", in.series(func(i int) float64 {
+				return boolToFloat(ta.IsDoji(in.opens[i], in.closes[i], in.highs[i], in.lows[i]))
+			})),
+			raw("hammer", in.series(func(i int) float64 {
+				return boolToFloat(ta.IsHammer(in.opens[i], in.closes[i], in.highs[i], in.lows[i]))
+			})),
+			raw("engulfing", in.series(func(i int) float64 {
+				return boolToFloat(i > 0 && ta.IsEngulfing(in.opens[i], in.closes[i], in.opens[i-1], in.closes[i-1]))
+			})),
+			raw("bb_position", in.series(func(i int) float64 {
+				return (in.closes[i] - bbLower[i]) / (bbUpper[i] - bbLower[i])
+			})),
+		}
+	},
+}
+
+// Momentum and acceleration
+var accelerationFeatures = featureSpec{
+	name:    "acceleration",
+	enabled: always,
+	warmup:  func(ModelParams) int { return 11 },
+	compute: func(in *featureInput) []featureSeries {
+		return []featureSeries{
+			ranged("velocity", in.series(func(i int) float64 {
+				return ta.Momentum(in.closes[max(i-5, 0) : i+1])
+			}), -0.05, 0.05),
+			ranged("acceleration", in.series(func(i int) float64 {
+				return ta.Acceleration(in.closes[max(i-10, 0) : i+1])
+			}), -0.01, 0.01),
+		}
+	},
+}
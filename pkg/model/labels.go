@@ -0,0 +1,241 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grexie/signals/pkg/candles"
+	"github.com/grexie/signals/pkg/ta"
+	"github.com/jedib0t/go-pretty/v6/progress"
+)
+
+// LabelingMethod is how Prepare labels each candle with the trade it should
+// have opened
+type LabelingMethod string
+
+const (
+	// LabelingRules labels a trade that reaches its take profit without
+	// reaching its stop loss, but only where RSI, MACD and optionally a
+	// divergence agree with it
+	LabelingRules LabelingMethod = "rules"
+	// LabelingTripleBarrier labels a trade by the first of its take profit,
+	// its stop loss or Candles candles that it reaches, from price alone
+	LabelingTripleBarrier LabelingMethod = "triple_barrier"
+)
+
+// Labelings lists every labeling method
+var Labelings = []LabelingMethod{LabelingRules, LabelingTripleBarrier}
+
+func ParseLabeling(s string) (LabelingMethod, error) {
+	v := LabelingMethod(strings.ToLower(s))
+	if slices.Contains(Labelings, v) {
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown labeling %q, expected rules or triple_barrier", s)
+}
+
+// Labeler labels a candle with the trade that should be opened at its
+// close, looking at most Candles candles ahead
+type Labeler interface {
+	Label(i int) Strategy
+}
+
+// newLabeler returns the params' labeler over the candles, whose features
+// are in set
+func newLabeler(candles []Candle, set *featureSet, params ModelParams) Labeler {
+	if params.Labeling == LabelingTripleBarrier {
+		return NewTripleBarrierLabeler(candles, params)
+	}
+	return newRulesLabeler(candles, set, params)
+}
+
+// rulesLabeler labels a LONG where price reaches TakeProfit above the close
+// without falling StopLoss below it and closes higher after Candles candles,
+// and where RSI is oversold and rising and MACD is above its signal. SHORT
+// is the reverse.
+type rulesLabeler struct {
+	candles   []Candle
+	params    ModelParams
+	rsi14     []float64
+	rsiSlope  []float64
+	macd      []float64
+	signal    []float64
+	confirmed func(i int, strategy Strategy) bool
+}
+
+func newRulesLabeler(candles []Candle, set *featureSet, params ModelParams) *rulesLabeler {
+	l := &rulesLabeler{
+		candles:   candles,
+		params:    params,
+		rsi14:     set.raw("base.rsi_long"),
+		rsiSlope:  set.raw("base.rsi_slope"),
+		macd:      set.raw("base.macd"),
+		signal:    set.raw("base.macd_signal"),
+		confirmed: func(i int, strategy Strategy) bool { return true },
+	}
+
+	// long labels can require a bullish divergence and short labels a
+	// bearish one
+	if params.DivergenceConfirmation {
+		bullish, bearish := divergenceConfirmations(candles, params)
+		l.confirmed = func(i int, strategy Strategy) bool {
+			if strategy == StrategyLong {
+				return bullish[i]
+			}
+			return bearish[i]
+		}
+	}
+	return l
+}
+
+func (l *rulesLabeler) Label(i int) Strategy {
+	candles, params := l.candles, l.params
+	basePrice := candles[i].Close
+
+	// Look ahead window
+	highestHigh := basePrice
+	lowestLow := basePrice
+	closingPrice := candles[i+params.Candles].Close
+
+	for j := 1; j <= params.Candles; j++ {
+		highestHigh = math.Max(highestHigh, candles[i+j].High)
+		lowestLow = math.Min(lowestLow, candles[i+j].Low)
+
+		potentialGain := (highestHigh - basePrice) / basePrice
+		potentialLoss := (basePrice - lowestLow) / basePrice
+		actualChange := (closingPrice - basePrice) / basePrice
+
+		// Enhanced signal generation with trend confirmation
+		if potentialGain >= params.TakeProfit &&
+			potentialLoss < params.StopLoss &&
+			actualChange > 0 &&
+			l.rsi14[i] < params.RSILowerBound &&
+			l.rsiSlope[i] > 0.5 &&
+			l.macd[i] > l.signal[i] &&
+			l.confirmed(i, StrategyLong) {
+			return StrategyLong
+		} else if potentialLoss >= params.TakeProfit &&
+			potentialGain < params.StopLoss &&
+			actualChange < 0 &&
+			l.rsi14[i] > params.RSIUpperBound &&
+			l.rsiSlope[i] < 0.5 &&
+			l.macd[i] < l.signal[i] &&
+			l.confirmed(i, StrategyShort) {
+			return StrategyShort
+		}
+	}
+	return StrategyHold
+}
+
+// barrierReferenceWindows is how many BarrierVolatilityWindow windows of
+// volatility a candle's volatility is compared with to scale its barriers
+const barrierReferenceWindows = 24
+
+// barrierWarmup is the number of candles before barrier scales no longer
+// depend on where the candles start
+func barrierWarmup(params ModelParams) int {
+	if !params.VolatilityBarriers {
+		return 0
+	}
+	return (barrierReferenceWindows + 1) * params.BarrierVolatilityWindow
+}
+
+// barrierScales is how much wider than TakeProfit and StopLoss the barriers
+// of each candle are with VolatilityBarriers, or nil without: the Parkinson
+// volatility over the BarrierVolatilityWindow candles up to it relative to
+// its mean over the barrierReferenceWindows windows up to it. Each scale
+// only depends on earlier candles, so labels, backtests and live trading
+// scale the same candle's barriers alike. Candles before barrierWarmup keep
+// fixed barriers.
+func barrierScales(candles []Candle, params ModelParams) []float64 {
+	if !params.VolatilityBarriers {
+		return nil
+	}
+
+	highs, lows := make([]float64, len(candles)), make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i], lows[i] = c.High, c.Low
+	}
+	volatility := ta.ParkinsonVolatility(highs, lows, params.BarrierVolatilityWindow)
+	reference := ta.MovingAverage(volatility, barrierReferenceWindows*params.BarrierVolatilityWindow)
+
+	scales := make([]float64, len(candles))
+	for i := range scales {
+		scales[i] = 1
+		if i >= barrierWarmup(params) && volatility[i] > 0 && reference[i] > 0 {
+			scales[i] = volatility[i] / reference[i]
+		}
+	}
+	return scales
+}
+
+// BarrierScale is how much wider than TakeProfit and StopLoss the barriers
+// of a trade opened at now are, one without VolatilityBarriers
+func (m *Model) BarrierScale(pw progress.Writer, now time.Time) (float64, error) {
+	if !m.params.VolatilityBarriers {
+		return 1, nil
+	}
+	from := now.Truncate(time.Minute).Add(-time.Duration(barrierWarmup(m.params)+1) * time.Minute)
+	candles, err := candles.GetCandles(m.db, pw, m.Instrument, candles.Network(Network()), from, now)
+	if err != nil {
+		return 1, err
+	}
+	if len(candles) == 0 {
+		return 1, fmt.Errorf("no candle data received")
+	}
+	scales := barrierScales(candles, m.params)
+	return scales[len(scales)-1], nil
+}
+
+// tripleBarrierLabeler labels a LONG where a long trade opened at the close
+// would reach its take profit before its stop loss within Candles candles,
+// and a SHORT where a short trade would. Otherwise the time barrier closes
+// both trades and the candle is labelled HOLD.
+type tripleBarrierLabeler struct {
+	candles []Candle
+	params  ModelParams
+	// scale is how much wider than TakeProfit and StopLoss each candle's
+	// barriers are, or nil for fixed barriers
+	scale []float64
+}
+
+// NewTripleBarrierLabeler labels the candles by the barriers alone, scaled
+// by barrierScales with VolatilityBarriers
+func NewTripleBarrierLabeler(candles []Candle, params ModelParams) Labeler {
+	return &tripleBarrierLabeler{candles: candles, params: params, scale: barrierScales(candles, params)}
+}
+
+func (l *tripleBarrierLabeler) Label(i int) Strategy {
+	takeProfit, stopLoss := l.params.TakeProfit, l.params.StopLoss
+	if l.scale != nil {
+		takeProfit *= l.scale[i]
+		stopLoss *= l.scale[i]
+	}
+	basePrice := l.candles[i].Close
+
+	longOpen, shortOpen := true, true
+	for j := 1; j <= l.params.Candles && (longOpen || shortOpen); j++ {
+		c := l.candles[i+j]
+
+		// a candle that reaches both barriers is assumed to have reached
+		// the stop loss first, as the paper trader does
+		longOpen = longOpen && c.Low > basePrice*(1-stopLoss)
+		shortOpen = shortOpen && c.High < basePrice*(1+stopLoss)
+
+		long := longOpen && c.High >= basePrice*(1+takeProfit)
+		short := shortOpen && c.Low <= basePrice*(1-takeProfit)
+		switch {
+		case long && short:
+			// there's no telling which came first
+			return StrategyHold
+		case long:
+			return StrategyLong
+		case short:
+			return StrategyShort
+		}
+	}
+	return StrategyHold
+}
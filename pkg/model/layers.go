@@ -0,0 +1,169 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// ActivationFunction is the nonlinearity after each of the MLP's hidden
+// layers
+type ActivationFunction string
+
+const (
+	ActivationReLU ActivationFunction = "relu"
+	ActivationMish ActivationFunction = "mish"
+	// ActivationGELU uses the tanh approximation
+	ActivationGELU ActivationFunction = "gelu"
+	ActivationTanh ActivationFunction = "tanh"
+)
+
+// Activations lists every activation, for the optimizer to choose from
+var Activations = []ActivationFunction{ActivationReLU, ActivationMish, ActivationGELU, ActivationTanh}
+
+func ParseActivation(s string) (ActivationFunction, error) {
+	v := ActivationFunction(strings.ToLower(s))
+	if slices.Contains(Activations, v) {
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown activation %q, expected relu, mish, gelu or tanh", s)
+}
+
+// LayerNormalization is how the MLP normalizes each hidden layer before its
+// activation. Both normalizations learn a scale and shift for each unit.
+type LayerNormalization string
+
+const (
+	NormalizationNone LayerNormalization = "none"
+	// NormalizationBatch normalizes each unit over the batch in training,
+	// and over running averages of the batches when predicting
+	NormalizationBatch LayerNormalization = "batch"
+	// NormalizationLayer normalizes each sample over the layer's units
+	NormalizationLayer LayerNormalization = "layer"
+)
+
+// Normalizations lists every normalization, for the optimizer to choose from
+var Normalizations = []LayerNormalization{NormalizationNone, NormalizationBatch, NormalizationLayer}
+
+func ParseNormalization(s string) (LayerNormalization, error) {
+	v := LayerNormalization(strings.ToLower(s))
+	if slices.Contains(Normalizations, v) {
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown normalization %q, expected none, batch or layer", s)
+}
+
+func (n LayerNormalization) enabled() bool {
+	return n == NormalizationBatch || n == NormalizationLayer
+}
+
+const (
+	// normalizationEpsilon keeps normalization finite over constant units
+	normalizationEpsilon = 1e-5
+	// normalizationMomentum is the weight of the running averages of batch
+	// normalization against each new batch
+	normalizationMomentum = 0.9
+)
+
+// hiddenLayerSizes is the width of each of the MLP's hidden layers, the
+// first HiddenLayerSize and each after it HiddenLayerDecay times the last
+func hiddenLayerSizes(params ModelParams) []int {
+	sizes := make([]int, params.HiddenLayers)
+	size := float64(params.HiddenLayerSize)
+	for i := range sizes {
+		sizes[i] = max(int(math.Round(size)), outputSize)
+		size *= params.HiddenLayerDecay
+	}
+	return sizes
+}
+
+// activationGraph adds the activation of x to the graph
+func activationGraph(activation ActivationFunction, x *gorgonia.Node) *gorgonia.Node {
+	switch activation {
+	case ActivationMish:
+		return gorgonia.Must(Mish(x))
+	case ActivationGELU:
+		inner := gorgonia.Must(gorgonia.Add(x, gorgonia.Must(gorgonia.Mul(gorgonia.NewConstant(0.044715), gorgonia.Must(gorgonia.Cube(x))))))
+		t := gorgonia.Must(gorgonia.Tanh(gorgonia.Must(gorgonia.Mul(gorgonia.NewConstant(math.Sqrt(2/math.Pi)), inner))))
+		return gorgonia.Must(gorgonia.Mul(gorgonia.NewConstant(0.5), gorgonia.Must(gorgonia.HadamardProd(x, gorgonia.Must(gorgonia.Add(t, gorgonia.NewConstant(1.0)))))))
+	case ActivationTanh:
+		return gorgonia.Must(gorgonia.Tanh(x))
+	default:
+		return gorgonia.Must(gorgonia.Rectify(x))
+	}
+}
+
+// activation returns the activation of a single value, as activationGraph
+// calculates it
+func activation(activation ActivationFunction) func(float64) float64 {
+	switch activation {
+	case ActivationMish:
+		return func(v float64) float64 { return v * math.Tanh(math.Log(math.Exp(v)+1)) }
+	case ActivationGELU:
+		return func(v float64) float64 {
+			return 0.5 * v * (math.Tanh(math.Sqrt(2/math.Pi)*(v+0.044715*v*v*v)) + 1)
+		}
+	case ActivationTanh:
+		return math.Tanh
+	default:
+		return func(v float64) float64 { return math.Max(v, 0) }
+	}
+}
+
+// normalizationGraph adds the normalization of x, a batch of a layer's
+// units, to the graph, scaled by gamma and shifted by beta. It also returns
+// the mean and variance it normalized by.
+func normalizationGraph(normalization LayerNormalization, x, gamma, beta *gorgonia.Node) (out, mean, variance *gorgonia.Node) {
+	rows, cols := x.Shape()[0], x.Shape()[1]
+
+	// layer normalization averages over each row and broadcasts along it,
+	// batch normalization over each column
+	axis, shape, pattern := 1, tensor.Shape{rows, 1}, []byte{1}
+	if normalization == NormalizationBatch {
+		axis, shape, pattern = 0, tensor.Shape{1, cols}, []byte{0}
+	}
+
+	mean = gorgonia.Must(gorgonia.Reshape(gorgonia.Must(gorgonia.Mean(x, axis)), shape))
+	centered := gorgonia.Must(gorgonia.BroadcastSub(x, mean, nil, pattern))
+	variance = gorgonia.Must(gorgonia.Reshape(gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(centered)), axis)), shape))
+	std := gorgonia.Must(gorgonia.Sqrt(gorgonia.Must(gorgonia.Add(variance, gorgonia.NewConstant(normalizationEpsilon)))))
+
+	out = gorgonia.Must(gorgonia.BroadcastHadamardDiv(centered, std, nil, pattern))
+	out = gorgonia.Must(gorgonia.BroadcastHadamardProd(out, gamma, nil, []byte{0}))
+	out = gorgonia.Must(gorgonia.BroadcastAdd(out, beta, nil, []byte{0}))
+	return out, mean, variance
+}
+
+// normalize normalizes each row of x as normalizationGraph does, with batch
+// normalization using the running mean and variance rather than the
+// batch's
+func normalize(normalization LayerNormalization, x *mat.Dense, gamma, beta, mean, variance []float64) {
+	rows, cols := x.Dims()
+	for i := range rows {
+		row := x.RawRowView(i)
+		if normalization == NormalizationLayer {
+			m := 0.0
+			for _, v := range row {
+				m += v
+			}
+			m /= float64(cols)
+			s := 0.0
+			for _, v := range row {
+				s += (v - m) * (v - m)
+			}
+			std := math.Sqrt(s/float64(cols) + normalizationEpsilon)
+			for j, v := range row {
+				row[j] = (v-m)/std*gamma[j] + beta[j]
+			}
+		} else {
+			for j, v := range row {
+				row[j] = (v-mean[j])/math.Sqrt(variance[j]+normalizationEpsilon)*gamma[j] + beta[j]
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+// LevelSource says which levels the backtester places take profits and stop
+// losses at
+type LevelSource string
+
+const (
+	// LevelsNone uses the fixed SIGNALS_TAKE_PROFIT and SIGNALS_STOP_LOSS
+	// percentages
+	LevelsNone LevelSource = "none"
+	// LevelsPivots uses the nearest pivot levels either side of the entry
+	LevelsPivots LevelSource = "pivots"
+	// LevelsSupportResistance uses the nearest support and resistance
+	// either side of the entry
+	LevelsSupportResistance LevelSource = "support_resistance"
+)
+
+func ParseLevelSource(s string) (LevelSource, error) {
+	switch v := LevelSource(strings.ToLower(s)); v {
+	case LevelsNone, LevelsPivots, LevelsSupportResistance:
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown level source %q, expected none, pivots or support_resistance", s)
+}
+
+// pivotLevels returns the pivot levels of each candle in ascending order
+func pivotLevels(candles []Candle, params ModelParams) [][]float64 {
+	pivots := ta.PivotPoints(candles, params.PivotAnchor, params.PivotMethod)
+	out := make([][]float64, len(candles))
+	for i, p := range pivots {
+		out[i] = p.Levels()
+	}
+	return out
+}
+
+// supportResistanceLevels returns the support and resistance levels of each
+// candle in ascending order
+func supportResistanceLevels(candles []Candle, params ModelParams) [][]float64 {
+	in := newFeatureInput(candles, nil, params)
+	return ta.SupportResistance(in.highs, in.lows, params.SwingStrength, params.SupportResistanceLookback, params.SupportResistanceTolerance, params.SupportResistanceTouches)
+}
+
+// tradeLevels returns the levels for the backtester's take profits and
+// stop losses, or nil for fixed percentages
+func tradeLevels(candles []Candle, params ModelParams) [][]float64 {
+	switch params.TradeLevels {
+	case LevelsPivots:
+		return pivotLevels(candles, params)
+	case LevelsSupportResistance:
+		return supportResistanceLevels(candles, params)
+	}
+	return nil
+}
+
+func tradeLevelsWarmup(params ModelParams) int {
+	switch params.TradeLevels {
+	case LevelsPivots:
+		return pivotFeatures.warmup(params)
+	case LevelsSupportResistance:
+		return supportResistanceFeatures.warmup(params)
+	}
+	return 0
+}
+
+// levelFeatures returns the distances from the close down to the nearest
+// level and up to the next, and where the close sits between them
+func levelFeatures(in *featureInput, levels [][]float64) []featureSeries {
+	below, above := make([]float64, len(in.closes)), make([]float64, len(in.closes))
+	for i, close := range in.closes {
+		below[i], above[i] = ta.NearestLevels(close, levels[i])
+	}
+	return []featureSeries{
+		windowed("support_distance", in.series(func(i int) float64 {
+			return relativeDistance(in.closes[i], below[i])
+		})),
+		windowed("resistance_distance", in.series(func(i int) float64 {
+			return -relativeDistance(in.closes[i], above[i])
+		})),
+		raw("position", in.series(func(i int) float64 {
+			return channelPosition(in.closes[i], below[i], above[i])
+		})),
+	}
+}
+
+// Optional pivot point and support/resistance feature groups
+var pivotFeatures = featureSpec{
+	name:    "pivots",
+	enabled: func(p ModelParams) bool { return p.LevelsEnabled },
+	warmup:  func(p ModelParams) int { return 2 * sessionWarmup(p.PivotAnchor) },
+	compute: func(in *featureInput) []featureSeries {
+		return levelFeatures(in, pivotLevels(in.candles, in.params))
+	},
+}
+
+var supportResistanceFeatures = featureSpec{
+	name:    "support_resistance",
+	enabled: func(p ModelParams) bool { return p.LevelsEnabled },
+	warmup:  func(p ModelParams) int { return p.SupportResistanceLookback + 2*p.SwingStrength + 1 },
+	compute: func(in *featureInput) []featureSeries {
+		return levelFeatures(in, supportResistanceLevels(in.candles, in.params))
+	},
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strings"
 
+	"github.com/grexie/signals/pkg/ta"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
@@ -17,6 +19,14 @@ type ModelMetrics struct {
 
 	Samples []int
 
+	// ClassImbalance is how training made up for the rarity of LONG and
+	// SHORT labels. The metrics are always over the labels as they are.
+	ClassImbalance ImbalanceStrategy
+
+	// FeatureImportance is the share of the gain of each feature's splits,
+	// highest first, for tree models
+	FeatureImportance []FeatureImportance
+
 	Backtest DeepBacktestMetrics
 }
 
@@ -109,7 +119,11 @@ func (m ModelMetrics) Write(w io.Writer) error {
 
 	t = table.NewWriter()
 	t.SetOutputMirror(w)
-	t.SetTitle("Class Metrics")
+	if m.ClassImbalance != "" {
+		t.SetTitle(fmt.Sprintf("Class Metrics (%s)", m.ClassImbalance))
+	} else {
+		t.SetTitle("Class Metrics")
+	}
 	t.AppendHeader(table.Row{"CLASS", "PRECISION", "RECALL", "F1 SCORE", "SAMPLES"})
 	t.AppendRows([]table.Row{
 		{"HOLD", fmt.Sprintf("%6.2f%%", m.ClassPrecision[0]), fmt.Sprintf("%6.2f%%", m.ClassRecall[0]), fmt.Sprintf("%6.2f%%", m.F1Scores[0]), fmt.Sprintf("%d", m.Samples[0])},
@@ -137,6 +151,41 @@ func (m ModelMetrics) Write(w io.Writer) error {
 	t.AppendRow(table.Row{"Fitness", fmt.Sprintf("%.6f", m.Fitness())})
 	t.Render()
 
+	if m.Backtest.Regimes != nil {
+		total := 0
+		for _, r := range m.Backtest.Regimes {
+			total += r.Candles
+		}
+
+		t = table.NewWriter()
+		t.SetOutputMirror(w)
+		t.SetTitle("Regime Metrics")
+		t.AppendHeader(table.Row{"REGIME", "TIME", "TRADES", "WIN RATE", "MEAN RETURN", "TOTAL RETURN"})
+		for _, regime := range ta.Regimes {
+			r := m.Backtest.Regimes[regime]
+			t.AppendRow(table.Row{
+				strings.ToUpper(strings.ReplaceAll(regime.String(), "_", " ")),
+				fmt.Sprintf("%6.2f%%", float64(r.Candles)/math.Max(1, float64(total))*100),
+				fmt.Sprintf("%d", r.Trades),
+				fmt.Sprintf("%6.2f%%", r.WinRate()),
+				fmt.Sprintf("%6.2f%%", r.MeanReturn()),
+				fmt.Sprintf("%6.2f%%", r.Return),
+			})
+		}
+		t.Render()
+	}
+
+	if len(m.FeatureImportance) > 0 {
+		t = table.NewWriter()
+		t.SetOutputMirror(w)
+		t.SetTitle("Feature Importance")
+		t.AppendHeader(table.Row{"FEATURE", "GAIN"})
+		for _, f := range m.FeatureImportance[:min(20, len(m.FeatureImportance))] {
+			t.AppendRow(table.Row{f.Name, fmt.Sprintf("%6.2f%%", f.Gain*100)})
+		}
+		t.Render()
+	}
+
 	return nil
 }
 
@@ -6,7 +6,7 @@ import (
 	"gorgonia.org/gorgonia"
 )
 
-// Add Mish activation function implementation
+// Mish is x * tanh(softplus(x)), elementwise
 func Mish(x *gorgonia.Node) (*gorgonia.Node, error) {
 	if x == nil {
 		return nil, fmt.Errorf("input node is nil")
@@ -32,7 +32,7 @@ func Mish(x *gorgonia.Node) (*gorgonia.Node, error) {
 		return nil, fmt.Errorf("tanh error: %v", err)
 	}
 
-	result, err := gorgonia.Mul(x, tanh)
+	result, err := gorgonia.HadamardProd(x, tanh)
 	if err != nil {
 		return nil, fmt.Errorf("mul error: %v", err)
 	}
@@ -0,0 +1,228 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"gonum.org/v1/gonum/mat"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// mlpClassifier is the feed forward network over the features of the latest
+// candle
+type mlpClassifier struct{}
+
+func (mlpClassifier) sequential() bool {
+	return false
+}
+
+func (mlpClassifier) train(tracker *progress.Tracker, params ModelParams, features [][]float64, labels []float64, epochs int) ([]tensor.Tensor, error) {
+	net, err := newMLPNetwork(params, len(features[0]))
+	if err != nil {
+		return nil, err
+	}
+	return fit(tracker, params, net, features, labels, epochs)
+}
+
+// The MLP weights are each hidden layer's in turn: its weights, then its
+// normalization's scale and shift, then with residual connections the
+// projection of its input when the layer changes width. The output weights
+// follow, then for batch normalization the running mean and variance of
+// each layer.
+
+// newMLPNetwork builds the feed forward network over one row of features.
+// Each hidden layer is normalized, activated and dropped out, then with
+// residual connections its input is added back.
+func newMLPNetwork(params ModelParams, inputSize int) (*network, error) {
+	if params.HiddenLayers < 1 {
+		return nil, fmt.Errorf("mlp needs at least one hidden layer, got %d", params.HiddenLayers)
+	}
+	batchSize := params.BatchSize
+	sizes := hiddenLayerSizes(params)
+
+	g := gorgonia.NewGraph()
+
+	// Input and target tensors
+	xTensor := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(batchSize, inputSize),
+		gorgonia.WithName("x"))
+
+	yTensor := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(batchSize, outputSize),
+		gorgonia.WithName("y"))
+
+	// the scales and shifts aren't regularized
+	weights, regularized := gorgonia.Nodes{}, gorgonia.Nodes{}
+	weight := func(name string, rows, cols int, init gorgonia.InitWFn) *gorgonia.Node {
+		w := gorgonia.NewMatrix(g, tensor.Float64,
+			gorgonia.WithShape(rows, cols),
+			gorgonia.WithInit(init),
+			gorgonia.WithName(name))
+		weights = append(weights, w)
+		return w
+	}
+
+	// the batch statistics are read as they're calculated, as the backward
+	// pass can reuse their memory
+	stats := make([]gorgonia.Value, 2*len(sizes))
+	h, in := xTensor, inputSize
+	for i, size := range sizes {
+		w := weight(fmt.Sprintf("w%d", i), in, size, gorgonia.GlorotN(1.0))
+		regularized = append(regularized, w)
+		l := gorgonia.Must(gorgonia.Mul(h, w))
+
+		if params.Normalization.enabled() {
+			gamma := weight(fmt.Sprintf("gamma%d", i), 1, size, gorgonia.Ones())
+			beta := weight(fmt.Sprintf("beta%d", i), 1, size, gorgonia.Zeroes())
+			var mean, variance *gorgonia.Node
+			l, mean, variance = normalizationGraph(params.Normalization, l, gamma, beta)
+			if params.Normalization == NormalizationBatch {
+				gorgonia.Read(mean, &stats[2*i])
+				gorgonia.Read(variance, &stats[2*i+1])
+			}
+		}
+
+		l = gorgonia.Must(gorgonia.Dropout(activationGraph(params.Activation, l), params.DropoutRate))
+
+		if params.Residual {
+			shortcut := h
+			if in != size {
+				p := weight(fmt.Sprintf("p%d", i), in, size, gorgonia.GlorotN(1.0))
+				regularized = append(regularized, p)
+				shortcut = gorgonia.Must(gorgonia.Mul(h, p))
+			}
+			l = gorgonia.Must(gorgonia.Add(l, shortcut))
+		}
+
+		h, in = l, size
+	}
+
+	wOut := weight(fmt.Sprintf("w%d", len(sizes)), in, outputSize, gorgonia.GlorotN(1.0))
+	regularized = append(regularized, wOut)
+	pred := gorgonia.Must(gorgonia.Mul(h, wOut))
+
+	net := &network{
+		g:       g,
+		y:       yTensor,
+		loss:    classificationLoss(params, pred, yTensor, regularized),
+		weights: weights,
+		let: func(batch []float64) error {
+			return gorgonia.Let(xTensor, tensor.New(
+				tensor.WithShape(batchSize, inputSize),
+				tensor.WithBacking(batch)))
+		},
+	}
+
+	if params.Normalization == NormalizationBatch {
+		running := make([]tensor.Tensor, 0, 2*len(sizes))
+		for _, size := range sizes {
+			mean := make([]float64, size)
+			variance := make([]float64, size)
+			for j := range variance {
+				variance[j] = 1
+			}
+			running = append(running,
+				tensor.New(tensor.WithShape(1, size), tensor.WithBacking(mean)),
+				tensor.New(tensor.WithShape(1, size), tensor.WithBacking(variance)))
+		}
+
+		net.step = func() {
+			for i, stat := range stats {
+				avg := running[i].Data().([]float64)
+				for j, v := range stat.Data().([]float64) {
+					avg[j] = normalizationMomentum*avg[j] + (1-normalizationMomentum)*v
+				}
+			}
+		}
+		net.state = func() []tensor.Tensor {
+			return running
+		}
+	}
+
+	return net, nil
+}
+
+// mlpLayer is a hidden layer's weights, as newMLPNetwork lays them out
+type mlpLayer struct {
+	w, projection  *mat.Dense
+	gamma, beta    []float64
+	mean, variance []float64
+}
+
+// mlpLayers splits the MLP's weights into its hidden layers and its output
+// weights
+func mlpLayers(params ModelParams, weights []tensor.Tensor) ([]mlpLayer, *mat.Dense) {
+	if params.HiddenLayers < 1 {
+		panic(fmt.Sprintf("mlp needs at least one hidden layer, got %d", params.HiddenLayers))
+	}
+
+	layers := make([]mlpLayer, params.HiddenLayers)
+	next := 0
+	in := weights[0].Shape()[0]
+	for i := range layers {
+		l := &layers[i]
+		l.w = weightMatrix(weights[next])
+		next++
+		_, size := l.w.Dims()
+		if params.Normalization.enabled() {
+			l.gamma = weights[next].Data().([]float64)
+			l.beta = weights[next+1].Data().([]float64)
+			next += 2
+		}
+		if params.Residual && in != size {
+			l.projection = weightMatrix(weights[next])
+			next++
+		}
+		in = size
+	}
+	out := weightMatrix(weights[next])
+	next++
+
+	if params.Normalization == NormalizationBatch {
+		for i := range layers {
+			layers[i].mean = weights[next].Data().([]float64)
+			layers[i].variance = weights[next+1].Data().([]float64)
+			next += 2
+		}
+	}
+	return layers, out
+}
+
+// predictor runs the MLP's layers over the batch as the training graph
+// does, without dropout
+func (mlpClassifier) predictor(params ModelParams, weights []tensor.Tensor) batchPredictor {
+	layers, out := mlpLayers(params, weights)
+	activate := activation(params.Activation)
+
+	return func(features [][]float64) ([][]float64, error) {
+		inputSize, _ := layers[0].w.Dims()
+		x, err := batchMatrix(features, inputSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range layers {
+			var h mat.Dense
+			h.Mul(x, l.w)
+			if l.gamma != nil {
+				normalize(params.Normalization, &h, l.gamma, l.beta, l.mean, l.variance)
+			}
+			h.Apply(func(_, _ int, v float64) float64 { return activate(v) }, &h)
+			if params.Residual {
+				if l.projection != nil {
+					var shortcut mat.Dense
+					shortcut.Mul(x, l.projection)
+					h.Add(&h, &shortcut)
+				} else {
+					h.Add(&h, x)
+				}
+			}
+			x = &h
+		}
+
+		var logits mat.Dense
+		logits.Mul(x, out)
+		return softmaxRows(&logits), nil
+	}
+}
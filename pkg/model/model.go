@@ -3,7 +3,6 @@ package model
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/grexie/signals/pkg/candles"
@@ -14,10 +13,14 @@ import (
 
 type Model struct {
 	weights    []tensor.Tensor
+	predictor  *Predictor
 	db         *leveldb.DB
 	params     ModelParams
 	Instrument string
 	Metrics    ModelMetrics
+	// From and To are the range of candles the model was trained on
+	From time.Time
+	To   time.Time
 }
 
 func NewModel(ctx context.Context, pw progress.Writer, db *leveldb.DB, instrument string, params ModelParams, now time.Time) (*Model, error) {
@@ -33,6 +36,14 @@ func NewModel(ctx context.Context, pw progress.Writer, db *leveldb.DB, instrumen
 		return nil, fmt.Errorf("no candle data received")
 	}
 
+	// fixed on the training candles so serving uses the same order
+	params = ResolveFracDiffOrder(candles, params)
+
+	references, err := GetReferences(db, nil, params, from, to)
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure we have enough candle data (at least 200 window + 5 for prediction)
 	required := params.WindowSize + params.Candles
 	if len(candles) < required {
@@ -42,6 +53,7 @@ func NewModel(ctx context.Context, pw progress.Writer, db *leveldb.DB, instrumen
 	features, labels := Prepare(
 		pw,
 		candles,
+		references,
 		params,
 	)
 
@@ -71,13 +83,14 @@ func NewModel(ctx context.Context, pw progress.Writer, db *leveldb.DB, instrumen
 		total := len(testingFeatures)
 		predictions := make([]int, total)
 
-		for i, features := range testingFeatures {
-			pred, err := Predict(weights, features)
+		predictor := NewPredictor(params, weights)
+		preds, err := predictor.PredictBatch(testingFeatures)
+		if err != nil {
+			return nil, fmt.Errorf("validation error: %v", err)
+		}
+
+		for i, pred := range preds {
 			tracker.Increment(1)
-			if err != nil {
-				log.Printf("prediction error for sample %d: %v", i, err)
-				continue
-			}
 
 			predictedClass := argmax(pred)
 			actualClass := int(testingLabels[i])
@@ -92,13 +105,18 @@ func NewModel(ctx context.Context, pw progress.Writer, db *leveldb.DB, instrumen
 
 		// Calculate detailed metrics
 		metrics := calculateMetrics(confusionMatrix, total)
+		metrics.FeatureImportance = featureImportance(params, weights)
+		metrics.ClassImbalance = params.ClassImbalance
 
 		m := &Model{
 			weights:    weights,
+			predictor:  predictor,
 			db:         db,
 			params:     params,
 			Instrument: instrument,
 			Metrics:    metrics,
+			From:       from,
+			To:         to,
 		}
 
 		if backtest, err := m.DeepBacktest(pw, instrument, params, to); err != nil {
@@ -127,16 +145,20 @@ type Prediction map[Strategy]float64
 
 func (m *Model) Predict(pw progress.Writer, feature []float64, now time.Time) ([]float64, Prediction, error) {
 	if feature == nil {
-		from := now.Truncate(time.Minute).Add(-time.Duration(WindowSize()*2) * time.Minute)
+		from := now.Truncate(time.Minute).Add(-time.Duration(FeatureWarmup(m.params)+1) * time.Minute)
 		candles, err := candles.GetCandles(m.db, pw, m.Instrument, candles.Network(Network()), from, now)
 		if err != nil {
 			return nil, nil, err
 		}
-		features := PrepareForPrediction(candles, m.params)
+		references, err := GetReferences(m.db, pw, m.params, from, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		features := PrepareForPrediction(candles, references, m.params)
 		feature = features[len(features)-1]
 	}
 
-	pred, err := Predict(m.weights, feature)
+	pred, err := m.predictor.Predict(feature)
 	if err != nil {
 		return nil, nil, err
 	}
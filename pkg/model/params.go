@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/grexie/signals/pkg/candles"
+	"github.com/grexie/signals/pkg/ta"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
@@ -61,6 +62,106 @@ type ModelParams struct {
 	RSILowerBound              float64
 	RSISlope                   int
 
+	RSISmoothing            ta.Smoothing
+	MACDSmoothing           ta.Smoothing
+	ATRSmoothing            ta.Smoothing
+	BollingerBandsSmoothing ta.Smoothing
+
+	ADXEnabled                bool
+	ADXPeriod                 int
+	ParabolicSAREnabled       bool
+	ParabolicSARStep          float64
+	ParabolicSARMaxStep       float64
+	IchimokuEnabled           bool
+	IchimokuConversionPeriod  int
+	IchimokuBasePeriod        int
+	IchimokuSpanPeriod        int
+	SuperTrendEnabled         bool
+	SuperTrendPeriod          int
+	SuperTrendMultiplier      float64
+	KeltnerChannelsEnabled    bool
+	KeltnerChannelsPeriod     int
+	KeltnerChannelsMultiplier float64
+	DonchianChannelsEnabled   bool
+	DonchianChannelsPeriod    int
+
+	SessionVWAPEnabled     bool
+	SessionVWAPAnchor      ta.SessionAnchor
+	SessionVWAPMultiplier  float64
+	VolumeProfileEnabled   bool
+	VolumeProfileWindow    int
+	VolumeProfileBinWidth  float64
+	VolumeProfileValueArea float64
+
+	CandlePatterns ta.CandlePatternSet
+
+	InvalidRows InvalidRowPolicy
+
+	StatisticsEnabled      bool
+	StatisticsWindow       int
+	AutocorrelationLag     int
+	SampleEntropyDimension int
+	SampleEntropyTolerance float64
+
+	ReferenceInstruments Instruments
+	ReferenceWindow      int
+	ReferenceLag         int
+
+	CalendarEnabled bool
+	FundingSchedule ta.SessionAnchor
+
+	RegimeEnabled             bool
+	RegimeWindow              int
+	RegimeLookback            int
+	RegimeTrendThreshold      float64
+	RegimeVolatilityThreshold float64
+	RegimeBlacklist           ta.RegimeSet
+
+	FracDiffEnabled bool
+	FracDiffOrder   float64
+	FracDiffWindow  int
+
+	DivergenceEnabled      bool
+	DivergenceConfirmation bool
+	SwingStrength          int
+	DivergenceLookback     int
+
+	LevelsEnabled              bool
+	PivotMethod                ta.PivotMethod
+	PivotAnchor                ta.SessionAnchor
+	SupportResistanceLookback  int
+	SupportResistanceTolerance float64
+	SupportResistanceTouches   int
+	TradeLevels                LevelSource
+
+	TimeframeFeatures Timeframes
+
+	Architecture   ModelArchitecture
+	SequenceLength int
+	BPTTSteps      int
+
+	TCNKernelSize int
+
+	GBDTTrees     int
+	GBDTDepth     int
+	GBDTLearnRate float64
+	GBDTSubsample float64
+	GBDTL2        float64
+
+	HiddenLayers     int
+	HiddenLayerDecay float64
+	Activation       ActivationFunction
+	Normalization    LayerNormalization
+	Residual         bool
+
+	ClassImbalance     ImbalanceStrategy
+	FocalGamma         float64
+	ImbalanceNeighbors int
+
+	Labeling                LabelingMethod
+	VolatilityBarriers      bool
+	BarrierVolatilityWindow int
+
 	L2Penalty   float64
 	DropoutRate float64
 	LearnRate   float64
@@ -120,6 +221,106 @@ func (m *ModelParams) Write(w io.Writer, title string, tradeInfo bool) {
 		fmt.Sprintf("SIGNALS_RSI_UPPER_BOUND=%0.02f", m.RSIUpperBound),
 		fmt.Sprintf("SIGNALS_RSI_LOWER_BOUND=%0.02f", m.RSILowerBound),
 		fmt.Sprintf("SIGNALS_RSI_SLOPE=%d", m.RSISlope),
+		"",
+		fmt.Sprintf("SIGNALS_RSI_SMOOTHING=%s", m.RSISmoothing),
+		fmt.Sprintf("SIGNALS_MACD_SMOOTHING=%s", m.MACDSmoothing),
+		fmt.Sprintf("SIGNALS_ATR_SMOOTHING=%s", m.ATRSmoothing),
+		fmt.Sprintf("SIGNALS_BOLLINGER_BANDS_SMOOTHING=%s", m.BollingerBandsSmoothing),
+		"",
+		fmt.Sprintf("SIGNALS_ADX_ENABLED=%t", m.ADXEnabled),
+		fmt.Sprintf("SIGNALS_ADX_PERIOD=%d", m.ADXPeriod),
+		fmt.Sprintf("SIGNALS_PARABOLIC_SAR_ENABLED=%t", m.ParabolicSAREnabled),
+		fmt.Sprintf("SIGNALS_PARABOLIC_SAR_STEP=%0.04f", m.ParabolicSARStep),
+		fmt.Sprintf("SIGNALS_PARABOLIC_SAR_MAX_STEP=%0.04f", m.ParabolicSARMaxStep),
+		fmt.Sprintf("SIGNALS_ICHIMOKU_ENABLED=%t", m.IchimokuEnabled),
+		fmt.Sprintf("SIGNALS_ICHIMOKU_CONVERSION_PERIOD=%d", m.IchimokuConversionPeriod),
+		fmt.Sprintf("SIGNALS_ICHIMOKU_BASE_PERIOD=%d", m.IchimokuBasePeriod),
+		fmt.Sprintf("SIGNALS_ICHIMOKU_SPAN_PERIOD=%d", m.IchimokuSpanPeriod),
+		fmt.Sprintf("SIGNALS_SUPERTREND_ENABLED=%t", m.SuperTrendEnabled),
+		fmt.Sprintf("SIGNALS_SUPERTREND_PERIOD=%d", m.SuperTrendPeriod),
+		fmt.Sprintf("SIGNALS_SUPERTREND_MULTIPLIER=%0.02f", m.SuperTrendMultiplier),
+		fmt.Sprintf("SIGNALS_KELTNER_CHANNELS_ENABLED=%t", m.KeltnerChannelsEnabled),
+		fmt.Sprintf("SIGNALS_KELTNER_CHANNELS_PERIOD=%d", m.KeltnerChannelsPeriod),
+		fmt.Sprintf("SIGNALS_KELTNER_CHANNELS_MULTIPLIER=%0.02f", m.KeltnerChannelsMultiplier),
+		fmt.Sprintf("SIGNALS_DONCHIAN_CHANNELS_ENABLED=%t", m.DonchianChannelsEnabled),
+		fmt.Sprintf("SIGNALS_DONCHIAN_CHANNELS_PERIOD=%d", m.DonchianChannelsPeriod),
+		"",
+		fmt.Sprintf("SIGNALS_SESSION_VWAP_ENABLED=%t", m.SessionVWAPEnabled),
+		fmt.Sprintf("SIGNALS_SESSION_VWAP_ANCHOR=%s", m.SessionVWAPAnchor),
+		fmt.Sprintf("SIGNALS_SESSION_VWAP_MULTIPLIER=%0.02f", m.SessionVWAPMultiplier),
+		fmt.Sprintf("SIGNALS_VOLUME_PROFILE_ENABLED=%t", m.VolumeProfileEnabled),
+		fmt.Sprintf("SIGNALS_VOLUME_PROFILE_WINDOW=%d", m.VolumeProfileWindow),
+		fmt.Sprintf("SIGNALS_VOLUME_PROFILE_BIN_WIDTH=%0.04f", m.VolumeProfileBinWidth),
+		fmt.Sprintf("SIGNALS_VOLUME_PROFILE_VALUE_AREA=%0.02f", m.VolumeProfileValueArea),
+		"",
+		fmt.Sprintf("SIGNALS_CANDLE_PATTERNS=%s", m.CandlePatterns),
+		"",
+		fmt.Sprintf("SIGNALS_INVALID_ROWS=%s", m.InvalidRows),
+		"",
+		fmt.Sprintf("SIGNALS_STATISTICS_ENABLED=%t", m.StatisticsEnabled),
+		fmt.Sprintf("SIGNALS_STATISTICS_WINDOW=%d", m.StatisticsWindow),
+		fmt.Sprintf("SIGNALS_AUTOCORRELATION_LAG=%d", m.AutocorrelationLag),
+		fmt.Sprintf("SIGNALS_SAMPLE_ENTROPY_DIMENSION=%d", m.SampleEntropyDimension),
+		fmt.Sprintf("SIGNALS_SAMPLE_ENTROPY_TOLERANCE=%0.02f", m.SampleEntropyTolerance),
+		"",
+		fmt.Sprintf("SIGNALS_REFERENCE_INSTRUMENTS=%s", m.ReferenceInstruments),
+		fmt.Sprintf("SIGNALS_REFERENCE_WINDOW=%d", m.ReferenceWindow),
+		fmt.Sprintf("SIGNALS_REFERENCE_LAG=%d", m.ReferenceLag),
+		"",
+		fmt.Sprintf("SIGNALS_CALENDAR_ENABLED=%t", m.CalendarEnabled),
+		fmt.Sprintf("SIGNALS_FUNDING_SCHEDULE=%s", m.FundingSchedule),
+		"",
+		fmt.Sprintf("SIGNALS_REGIME_ENABLED=%t", m.RegimeEnabled),
+		fmt.Sprintf("SIGNALS_REGIME_WINDOW=%d", m.RegimeWindow),
+		fmt.Sprintf("SIGNALS_REGIME_LOOKBACK=%d", m.RegimeLookback),
+		fmt.Sprintf("SIGNALS_REGIME_TREND_THRESHOLD=%0.02f", m.RegimeTrendThreshold),
+		fmt.Sprintf("SIGNALS_REGIME_VOLATILITY_THRESHOLD=%0.02f", m.RegimeVolatilityThreshold),
+		fmt.Sprintf("SIGNALS_REGIME_BLACKLIST=%s", m.RegimeBlacklist),
+		"",
+		fmt.Sprintf("SIGNALS_FRACDIFF_ENABLED=%t", m.FracDiffEnabled),
+		fmt.Sprintf("SIGNALS_FRACDIFF_ORDER=%0.02f", m.FracDiffOrder),
+		fmt.Sprintf("SIGNALS_FRACDIFF_WINDOW=%d", m.FracDiffWindow),
+		"",
+		fmt.Sprintf("SIGNALS_DIVERGENCE_ENABLED=%t", m.DivergenceEnabled),
+		fmt.Sprintf("SIGNALS_DIVERGENCE_CONFIRMATION=%t", m.DivergenceConfirmation),
+		fmt.Sprintf("SIGNALS_SWING_STRENGTH=%d", m.SwingStrength),
+		fmt.Sprintf("SIGNALS_DIVERGENCE_LOOKBACK=%d", m.DivergenceLookback),
+		"",
+		fmt.Sprintf("SIGNALS_LEVELS_ENABLED=%t", m.LevelsEnabled),
+		fmt.Sprintf("SIGNALS_PIVOT_METHOD=%s", m.PivotMethod),
+		fmt.Sprintf("SIGNALS_PIVOT_ANCHOR=%s", m.PivotAnchor),
+		fmt.Sprintf("SIGNALS_SUPPORT_RESISTANCE_LOOKBACK=%d", m.SupportResistanceLookback),
+		fmt.Sprintf("SIGNALS_SUPPORT_RESISTANCE_TOLERANCE=%0.04f", m.SupportResistanceTolerance),
+		fmt.Sprintf("SIGNALS_SUPPORT_RESISTANCE_TOUCHES=%d", m.SupportResistanceTouches),
+		fmt.Sprintf("SIGNALS_TRADE_LEVELS=%s", m.TradeLevels),
+		"",
+		fmt.Sprintf("SIGNALS_TIMEFRAME_FEATURES=%s", m.TimeframeFeatures),
+		"",
+		fmt.Sprintf("SIGNALS_ARCHITECTURE=%s", m.Architecture),
+		fmt.Sprintf("SIGNALS_SEQUENCE_LENGTH=%d", m.SequenceLength),
+		fmt.Sprintf("SIGNALS_BPTT_STEPS=%d", m.BPTTSteps),
+		"",
+		fmt.Sprintf("SIGNALS_TCN_KERNEL_SIZE=%d", m.TCNKernelSize),
+		"",
+		fmt.Sprintf("SIGNALS_GBDT_TREES=%d", m.GBDTTrees),
+		fmt.Sprintf("SIGNALS_GBDT_DEPTH=%d", m.GBDTDepth),
+		fmt.Sprintf("SIGNALS_GBDT_LEARN_RATE=%0.03f", m.GBDTLearnRate),
+		fmt.Sprintf("SIGNALS_GBDT_SUBSAMPLE=%0.02f", m.GBDTSubsample),
+		fmt.Sprintf("SIGNALS_GBDT_L2=%0.02f", m.GBDTL2),
+		"",
+		fmt.Sprintf("SIGNALS_HIDDEN_LAYERS=%d", m.HiddenLayers),
+		fmt.Sprintf("SIGNALS_HIDDEN_LAYER_DECAY=%0.02f", m.HiddenLayerDecay),
+		fmt.Sprintf("SIGNALS_ACTIVATION=%s", m.Activation),
+		fmt.Sprintf("SIGNALS_NORMALIZATION=%s", m.Normalization),
+		fmt.Sprintf("SIGNALS_RESIDUAL=%t", m.Residual),
+		"",
+		fmt.Sprintf("SIGNALS_CLASS_IMBALANCE=%s", m.ClassImbalance),
+		fmt.Sprintf("SIGNALS_FOCAL_GAMMA=%0.02f", m.FocalGamma),
+		fmt.Sprintf("SIGNALS_IMBALANCE_NEIGHBORS=%d", m.ImbalanceNeighbors),
+		"",
+		fmt.Sprintf("SIGNALS_LABELING=%s", m.Labeling),
+		fmt.Sprintf("SIGNALS_VOLATILITY_BARRIERS=%t", m.VolatilityBarriers),
+		fmt.Sprintf("SIGNALS_BARRIER_VOLATILITY_WINDOW=%d", m.BarrierVolatilityWindow),
 	}
 
 	for _, param := range params {
@@ -130,7 +331,7 @@ func (m *ModelParams) Write(w io.Writer, title string, tradeInfo bool) {
 
 	if tradeInfo {
 		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
+		t.SetOutputMirror(w)
 		t.SetTitle("Trade Info")
 		t.AppendRows([]table.Row{
 			{"Take Profit", fmt.Sprintf("%0.02f%%", (100*m.TakeProfit*m.Leverage)/m.TradeMultiplier)},
@@ -194,6 +395,106 @@ func NewModelParamsFromDefaults() ModelParams {
 		RSILowerBound:              RSILowerBound(),
 		RSISlope:                   RSISlope(),
 
+		RSISmoothing:            RSISmoothing(),
+		MACDSmoothing:           MACDSmoothing(),
+		ATRSmoothing:            ATRSmoothing(),
+		BollingerBandsSmoothing: BollingerBandsSmoothing(),
+
+		ADXEnabled:                ADXEnabled(),
+		ADXPeriod:                 ADXPeriod(),
+		ParabolicSAREnabled:       ParabolicSAREnabled(),
+		ParabolicSARStep:          ParabolicSARStep(),
+		ParabolicSARMaxStep:       ParabolicSARMaxStep(),
+		IchimokuEnabled:           IchimokuEnabled(),
+		IchimokuConversionPeriod:  IchimokuConversionPeriod(),
+		IchimokuBasePeriod:        IchimokuBasePeriod(),
+		IchimokuSpanPeriod:        IchimokuSpanPeriod(),
+		SuperTrendEnabled:         SuperTrendEnabled(),
+		SuperTrendPeriod:          SuperTrendPeriod(),
+		SuperTrendMultiplier:      SuperTrendMultiplier(),
+		KeltnerChannelsEnabled:    KeltnerChannelsEnabled(),
+		KeltnerChannelsPeriod:     KeltnerChannelsPeriod(),
+		KeltnerChannelsMultiplier: KeltnerChannelsMultiplier(),
+		DonchianChannelsEnabled:   DonchianChannelsEnabled(),
+		DonchianChannelsPeriod:    DonchianChannelsPeriod(),
+
+		SessionVWAPEnabled:     SessionVWAPEnabled(),
+		SessionVWAPAnchor:      SessionVWAPAnchor(),
+		SessionVWAPMultiplier:  SessionVWAPMultiplier(),
+		VolumeProfileEnabled:   VolumeProfileEnabled(),
+		VolumeProfileWindow:    VolumeProfileWindow(),
+		VolumeProfileBinWidth:  VolumeProfileBinWidth(),
+		VolumeProfileValueArea: VolumeProfileValueArea(),
+
+		CandlePatterns: CandlePatterns(),
+
+		InvalidRows: InvalidRows(),
+
+		StatisticsEnabled:      StatisticsEnabled(),
+		StatisticsWindow:       StatisticsWindow(),
+		AutocorrelationLag:     AutocorrelationLag(),
+		SampleEntropyDimension: SampleEntropyDimension(),
+		SampleEntropyTolerance: SampleEntropyTolerance(),
+
+		ReferenceInstruments: ReferenceInstruments(),
+		ReferenceWindow:      ReferenceWindow(),
+		ReferenceLag:         ReferenceLag(),
+
+		CalendarEnabled: CalendarEnabled(),
+		FundingSchedule: FundingSchedule(),
+
+		RegimeEnabled:             RegimeEnabled(),
+		RegimeWindow:              RegimeWindow(),
+		RegimeLookback:            RegimeLookback(),
+		RegimeTrendThreshold:      RegimeTrendThreshold(),
+		RegimeVolatilityThreshold: RegimeVolatilityThreshold(),
+		RegimeBlacklist:           RegimeBlacklist(),
+
+		FracDiffEnabled: FracDiffEnabled(),
+		FracDiffOrder:   FracDiffOrder(),
+		FracDiffWindow:  FracDiffWindow(),
+
+		DivergenceEnabled:      DivergenceEnabled(),
+		DivergenceConfirmation: DivergenceConfirmation(),
+		SwingStrength:          SwingStrength(),
+		DivergenceLookback:     DivergenceLookback(),
+
+		LevelsEnabled:              LevelsEnabled(),
+		PivotMethod:                PivotMethod(),
+		PivotAnchor:                PivotAnchor(),
+		SupportResistanceLookback:  SupportResistanceLookback(),
+		SupportResistanceTolerance: SupportResistanceTolerance(),
+		SupportResistanceTouches:   SupportResistanceTouches(),
+		TradeLevels:                TradeLevels(),
+
+		TimeframeFeatures: TimeframeFeatures(),
+
+		Architecture:   Architecture(),
+		SequenceLength: SequenceLength(),
+		BPTTSteps:      BPTTSteps(),
+
+		TCNKernelSize: TCNKernelSize(),
+
+		GBDTTrees:     GBDTTrees(),
+		GBDTDepth:     GBDTDepth(),
+		GBDTLearnRate: GBDTLearnRate(),
+		GBDTSubsample: GBDTSubsample(),
+		GBDTL2:        GBDTL2(),
+
+		HiddenLayers:     HiddenLayers(),
+		HiddenLayerDecay: HiddenLayerDecay(),
+		Activation:       Activation(),
+		Normalization:    Normalization(),
+		Residual:         Residual(),
+
+		ClassImbalance:     ClassImbalance(),
+		FocalGamma:         FocalGamma(),
+		ImbalanceNeighbors: ImbalanceNeighbors(),
+
+		Labeling:                Labeling(),
+		VolatilityBarriers:      VolatilityBarriers(),
+		BarrierVolatilityWindow: BarrierVolatilityWindow(),
+
 		BatchSize:       BatchSize(),
 		HiddenLayerSize: HiddenLayerSize(),
 		L2Penalty:       L2Penalty(),
@@ -241,6 +542,76 @@ func envString(name string, def func() string) func() string {
 	}
 }
 
+func envBool(name string, def func() bool) func() bool {
+	return func() bool {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := strconv.ParseBool(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envSmoothing(name string, def func() ta.Smoothing) func() ta.Smoothing {
+	return func() ta.Smoothing {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ta.ParseSmoothing(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envSessionAnchor(name string, def func() ta.SessionAnchor) func() ta.SessionAnchor {
+	return func() ta.SessionAnchor {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ta.ParseSessionAnchor(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envCandlePatternSet(name string, def func() ta.CandlePatternSet) func() ta.CandlePatternSet {
+	return func() ta.CandlePatternSet {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ta.ParseCandlePatternSet(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envInvalidRowPolicy(name string, def func() InvalidRowPolicy) func() InvalidRowPolicy {
+	return func() InvalidRowPolicy {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseInvalidRowPolicy(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
 func envDuration(name string, def func() time.Duration, dec func(v time.Duration) time.Duration) func() time.Duration {
 	return func() time.Duration {
 		value := def()
@@ -339,6 +710,144 @@ var (
 	RSISlope                   = envInt("SIGNALS_RSI_SLOPE", func() int { return 3 }, BoundRSISlope)
 )
 
+var (
+	RSISmoothing            = envSmoothing("SIGNALS_RSI_SMOOTHING", func() ta.Smoothing { return ta.SmoothingSimple })
+	MACDSmoothing           = envSmoothing("SIGNALS_MACD_SMOOTHING", func() ta.Smoothing { return ta.SmoothingSimple })
+	ATRSmoothing            = envSmoothing("SIGNALS_ATR_SMOOTHING", func() ta.Smoothing { return ta.SmoothingSimple })
+	BollingerBandsSmoothing = envSmoothing("SIGNALS_BOLLINGER_BANDS_SMOOTHING", func() ta.Smoothing { return ta.SmoothingSimple })
+)
+
+var (
+	ADXEnabled                = envBool("SIGNALS_ADX_ENABLED", func() bool { return false })
+	ADXPeriod                 = envInt("SIGNALS_ADX_PERIOD", func() int { return 14 }, BoundADXPeriod)
+	ParabolicSAREnabled       = envBool("SIGNALS_PARABOLIC_SAR_ENABLED", func() bool { return false })
+	ParabolicSARStep          = envFloat64("SIGNALS_PARABOLIC_SAR_STEP", func() float64 { return 0.02 }, BoundParabolicSARStep)
+	ParabolicSARMaxStep       = envFloat64("SIGNALS_PARABOLIC_SAR_MAX_STEP", func() float64 { return 0.2 }, BoundParabolicSARMaxStep)
+	IchimokuEnabled           = envBool("SIGNALS_ICHIMOKU_ENABLED", func() bool { return false })
+	IchimokuConversionPeriod  = envInt("SIGNALS_ICHIMOKU_CONVERSION_PERIOD", func() int { return 9 }, BoundIchimokuConversionPeriod)
+	IchimokuBasePeriod        = envInt("SIGNALS_ICHIMOKU_BASE_PERIOD", func() int { return 26 }, BoundIchimokuBasePeriod)
+	IchimokuSpanPeriod        = envInt("SIGNALS_ICHIMOKU_SPAN_PERIOD", func() int { return 52 }, BoundIchimokuSpanPeriod)
+	SuperTrendEnabled         = envBool("SIGNALS_SUPERTREND_ENABLED", func() bool { return false })
+	SuperTrendPeriod          = envInt("SIGNALS_SUPERTREND_PERIOD", func() int { return 10 }, BoundSuperTrendPeriod)
+	SuperTrendMultiplier      = envFloat64("SIGNALS_SUPERTREND_MULTIPLIER", func() float64 { return 3.0 }, BoundSuperTrendMultiplier)
+	KeltnerChannelsEnabled    = envBool("SIGNALS_KELTNER_CHANNELS_ENABLED", func() bool { return false })
+	KeltnerChannelsPeriod     = envInt("SIGNALS_KELTNER_CHANNELS_PERIOD", func() int { return 20 }, BoundKeltnerChannelsPeriod)
+	KeltnerChannelsMultiplier = envFloat64("SIGNALS_KELTNER_CHANNELS_MULTIPLIER", func() float64 { return 2.0 }, BoundKeltnerChannelsMultiplier)
+	DonchianChannelsEnabled   = envBool("SIGNALS_DONCHIAN_CHANNELS_ENABLED", func() bool { return false })
+	DonchianChannelsPeriod    = envInt("SIGNALS_DONCHIAN_CHANNELS_PERIOD", func() int { return 20 }, BoundDonchianChannelsPeriod)
+)
+
+var (
+	SessionVWAPEnabled     = envBool("SIGNALS_SESSION_VWAP_ENABLED", func() bool { return false })
+	SessionVWAPAnchor      = envSessionAnchor("SIGNALS_SESSION_VWAP_ANCHOR", func() ta.SessionAnchor { return ta.SessionAnchorDaily })
+	SessionVWAPMultiplier  = envFloat64("SIGNALS_SESSION_VWAP_MULTIPLIER", func() float64 { return 2.0 }, BoundSessionVWAPMultiplier)
+	VolumeProfileEnabled   = envBool("SIGNALS_VOLUME_PROFILE_ENABLED", func() bool { return false })
+	VolumeProfileWindow    = envInt("SIGNALS_VOLUME_PROFILE_WINDOW", func() int { return 1440 }, BoundVolumeProfileWindow)
+	VolumeProfileBinWidth  = envFloat64("SIGNALS_VOLUME_PROFILE_BIN_WIDTH", func() float64 { return 0.001 }, BoundVolumeProfileBinWidth)
+	VolumeProfileValueArea = envFloat64("SIGNALS_VOLUME_PROFILE_VALUE_AREA", func() float64 { return 0.7 }, BoundVolumeProfileValueArea)
+)
+
+var (
+	CandlePatterns = envCandlePatternSet("SIGNALS_CANDLE_PATTERNS", func() ta.CandlePatternSet { return ta.CandlePatternSet{} })
+)
+
+var (
+	InvalidRows = envInvalidRowPolicy("SIGNALS_INVALID_ROWS", func() InvalidRowPolicy { return InvalidRowsDrop })
+)
+
+var (
+	StatisticsEnabled      = envBool("SIGNALS_STATISTICS_ENABLED", func() bool { return false })
+	StatisticsWindow       = envInt("SIGNALS_STATISTICS_WINDOW", func() int { return 60 }, BoundStatisticsWindow)
+	AutocorrelationLag     = envInt("SIGNALS_AUTOCORRELATION_LAG", func() int { return 1 }, BoundAutocorrelationLag)
+	SampleEntropyDimension = envInt("SIGNALS_SAMPLE_ENTROPY_DIMENSION", func() int { return 2 }, BoundSampleEntropyDimension)
+	SampleEntropyTolerance = envFloat64("SIGNALS_SAMPLE_ENTROPY_TOLERANCE", func() float64 { return 0.2 }, BoundSampleEntropyTolerance)
+)
+
+var (
+	ReferenceInstruments = envInstruments("SIGNALS_REFERENCE_INSTRUMENTS", func() Instruments { return Instruments{} })
+	ReferenceWindow      = envInt("SIGNALS_REFERENCE_WINDOW", func() int { return 60 }, BoundReferenceWindow)
+	ReferenceLag         = envInt("SIGNALS_REFERENCE_LAG", func() int { return 1 }, BoundReferenceLag)
+)
+
+var (
+	CalendarEnabled = envBool("SIGNALS_CALENDAR_ENABLED", func() bool { return false })
+	FundingSchedule = envSessionAnchor("SIGNALS_FUNDING_SCHEDULE", func() ta.SessionAnchor { return ta.SessionAnchor{Period: 8 * time.Hour} })
+)
+
+var (
+	RegimeEnabled             = envBool("SIGNALS_REGIME_ENABLED", func() bool { return false })
+	RegimeWindow              = envInt("SIGNALS_REGIME_WINDOW", func() int { return 60 }, BoundRegimeWindow)
+	RegimeLookback            = envInt("SIGNALS_REGIME_LOOKBACK", func() int { return 1440 }, BoundRegimeLookback)
+	RegimeTrendThreshold      = envFloat64("SIGNALS_REGIME_TREND_THRESHOLD", func() float64 { return 0.3 }, BoundRegimeTrendThreshold)
+	RegimeVolatilityThreshold = envFloat64("SIGNALS_REGIME_VOLATILITY_THRESHOLD", func() float64 { return 1.5 }, BoundRegimeVolatilityThreshold)
+	RegimeBlacklist           = envRegimeSet("SIGNALS_REGIME_BLACKLIST", func() ta.RegimeSet { return ta.RegimeSet{} })
+)
+
+var (
+	FracDiffEnabled = envBool("SIGNALS_FRACDIFF_ENABLED", func() bool { return false })
+	FracDiffOrder   = envFloat64("SIGNALS_FRACDIFF_ORDER", func() float64 { return 0 }, BoundFracDiffOrder)
+	FracDiffWindow  = envInt("SIGNALS_FRACDIFF_WINDOW", func() int { return 200 }, BoundFracDiffWindow)
+)
+
+var (
+	DivergenceEnabled      = envBool("SIGNALS_DIVERGENCE_ENABLED", func() bool { return false })
+	DivergenceConfirmation = envBool("SIGNALS_DIVERGENCE_CONFIRMATION", func() bool { return false })
+	SwingStrength          = envInt("SIGNALS_SWING_STRENGTH", func() int { return 3 }, BoundSwingStrength)
+	DivergenceLookback     = envInt("SIGNALS_DIVERGENCE_LOOKBACK", func() int { return 60 }, BoundDivergenceLookback)
+)
+
+var (
+	LevelsEnabled              = envBool("SIGNALS_LEVELS_ENABLED", func() bool { return false })
+	PivotMethod                = envPivotMethod("SIGNALS_PIVOT_METHOD", func() ta.PivotMethod { return ta.PivotClassic })
+	PivotAnchor                = envSessionAnchor("SIGNALS_PIVOT_ANCHOR", func() ta.SessionAnchor { return ta.SessionAnchorDaily })
+	SupportResistanceLookback  = envInt("SIGNALS_SUPPORT_RESISTANCE_LOOKBACK", func() int { return 1440 }, BoundSupportResistanceLookback)
+	SupportResistanceTolerance = envFloat64("SIGNALS_SUPPORT_RESISTANCE_TOLERANCE", func() float64 { return 0.002 }, BoundSupportResistanceTolerance)
+	SupportResistanceTouches   = envInt("SIGNALS_SUPPORT_RESISTANCE_TOUCHES", func() int { return 2 }, BoundSupportResistanceTouches)
+	TradeLevels                = envLevelSource("SIGNALS_TRADE_LEVELS", func() LevelSource { return LevelsNone })
+)
+
+var (
+	TimeframeFeatures = envTimeframes("SIGNALS_TIMEFRAME_FEATURES", func() Timeframes { return Timeframes{} })
+)
+
+var (
+	Architecture   = envArchitecture("SIGNALS_ARCHITECTURE", func() ModelArchitecture { return ArchitectureMLP })
+	SequenceLength = envInt("SIGNALS_SEQUENCE_LENGTH", func() int { return 30 }, BoundSequenceLength)
+	BPTTSteps      = envInt("SIGNALS_BPTT_STEPS", func() int { return 10 }, BoundBPTTSteps)
+)
+
+var (
+	TCNKernelSize = envInt("SIGNALS_TCN_KERNEL_SIZE", func() int { return 3 }, BoundTCNKernelSize)
+)
+
+var (
+	GBDTTrees     = envInt("SIGNALS_GBDT_TREES", func() int { return 200 }, BoundGBDTTrees)
+	GBDTDepth     = envInt("SIGNALS_GBDT_DEPTH", func() int { return 4 }, BoundGBDTDepth)
+	GBDTLearnRate = envFloat64("SIGNALS_GBDT_LEARN_RATE", func() float64 { return 0.1 }, BoundGBDTLearnRate)
+	GBDTSubsample = envFloat64("SIGNALS_GBDT_SUBSAMPLE", func() float64 { return 0.8 }, BoundGBDTSubsample)
+	GBDTL2        = envFloat64("SIGNALS_GBDT_L2", func() float64 { return 1 }, BoundGBDTL2)
+)
+
+var (
+	HiddenLayers     = envInt("SIGNALS_HIDDEN_LAYERS", func() int { return 3 }, BoundHiddenLayers)
+	HiddenLayerDecay = envFloat64("SIGNALS_HIDDEN_LAYER_DECAY", func() float64 { return 0.5 }, BoundHiddenLayerDecay)
+	Activation       = envActivation("SIGNALS_ACTIVATION", func() ActivationFunction { return ActivationReLU })
+	Normalization    = envNormalization("SIGNALS_NORMALIZATION", func() LayerNormalization { return NormalizationNone })
+	Residual         = envBool("SIGNALS_RESIDUAL", func() bool { return false })
+)
+
+var (
+	ClassImbalance     = envImbalance("SIGNALS_CLASS_IMBALANCE", func() ImbalanceStrategy { return ImbalanceOversample })
+	FocalGamma         = envFloat64("SIGNALS_FOCAL_GAMMA", func() float64 { return 2 }, BoundFocalGamma)
+	ImbalanceNeighbors = envInt("SIGNALS_IMBALANCE_NEIGHBORS", func() int { return 5 }, BoundImbalanceNeighbors)
+)
+
+var (
+	Labeling                = envLabeling("SIGNALS_LABELING", func() LabelingMethod { return LabelingRules })
+	VolatilityBarriers      = envBool("SIGNALS_VOLATILITY_BARRIERS", func() bool { return false })
+	BarrierVolatilityWindow = envInt("SIGNALS_BARRIER_VOLATILITY_WINDOW", func() int { return 60 }, BoundBarrierVolatilityWindow)
+)
+
 var (
 	BatchSize       = envInt("SIGNALS_BATCH_SIZE", func() int { return 32 }, BoundBatchSize)
 	HiddenLayerSize = envInt("SIGNALS_HIDDEN_LAYER_SIZE", func() int { return 128 }, BoundHiddenLayerSize)
@@ -347,3 +856,143 @@ var (
 	LearnRate       = envFloat64("SIGNALS_LEARN_RATE", func() float64 { return 0.00005 }, BoundLearnRate)
 	TrainDays       = envDays("SIGNALS_TRAIN_DAYS", func() time.Duration { return 30 * time.Hour }, BoundTrainDays)
 )
+
+func envInstruments(name string, def func() Instruments) func() Instruments {
+	return func() Instruments {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseInstruments(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envRegimeSet(name string, def func() ta.RegimeSet) func() ta.RegimeSet {
+	return func() ta.RegimeSet {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ta.ParseRegimeSet(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envPivotMethod(name string, def func() ta.PivotMethod) func() ta.PivotMethod {
+	return func() ta.PivotMethod {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ta.ParsePivotMethod(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envLevelSource(name string, def func() LevelSource) func() LevelSource {
+	return func() LevelSource {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseLevelSource(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envTimeframes(name string, def func() Timeframes) func() Timeframes {
+	return func() Timeframes {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseTimeframes(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envArchitecture(name string, def func() ModelArchitecture) func() ModelArchitecture {
+	return func() ModelArchitecture {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseArchitecture(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envActivation(name string, def func() ActivationFunction) func() ActivationFunction {
+	return func() ActivationFunction {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseActivation(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envNormalization(name string, def func() LayerNormalization) func() LayerNormalization {
+	return func() LayerNormalization {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseNormalization(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envImbalance(name string, def func() ImbalanceStrategy) func() ImbalanceStrategy {
+	return func() ImbalanceStrategy {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseImbalance(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
+
+func envLabeling(name string, def func() LabelingMethod) func() LabelingMethod {
+	return func() LabelingMethod {
+		value := def()
+		if v, ok := os.LookupEnv(name); ok {
+			if v, err := ParseLabeling(v); err != nil {
+				log.Fatalf("failed to parse env.%s: %v", name, err)
+			} else {
+				value = v
+			}
+		}
+		return value
+	}
+}
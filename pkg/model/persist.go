@@ -0,0 +1,185 @@
+package model
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"gorgonia.org/tensor"
+)
+
+// modelFormatVersion is bumped whenever the saved model layout changes
+const modelFormatVersion = 1
+
+type savedTensor struct {
+	Shape []int
+	Data  []float64
+}
+
+// savedModel is what Save writes: everything needed to predict with the
+// model again without retraining it
+type savedModel struct {
+	Version       int
+	Instrument    string
+	Params        ModelParams
+	Metrics       ModelMetrics
+	From          time.Time
+	To            time.Time
+	FeatureSchema string
+	Weights       []savedTensor
+}
+
+// Save writes the model's weights, params, metrics, training range and
+// feature schema to w
+func (m *Model) Save(w io.Writer) error {
+	saved := savedModel{
+		Version:       modelFormatVersion,
+		Instrument:    m.Instrument,
+		Params:        m.params,
+		Metrics:       m.Metrics,
+		From:          m.From,
+		To:            m.To,
+		FeatureSchema: FeatureSchemaHash(m.params),
+	}
+	for _, weight := range m.weights {
+		data, ok := weight.Data().([]float64)
+		if !ok {
+			return fmt.Errorf("unsupported weight type %T", weight.Data())
+		}
+		saved.Weights = append(saved.Weights, savedTensor{
+			Shape: append([]int{}, weight.Shape()...),
+			Data:  append([]float64{}, data...),
+		})
+	}
+
+	if err := gob.NewEncoder(w).Encode(saved); err != nil {
+		return fmt.Errorf("failed to encode model: %w", err)
+	}
+	return nil
+}
+
+// SaveFile saves the model to filename, replacing it only once the model
+// is completely written
+func (m *Model) SaveFile(filename string) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := m.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// Load reads a model written by Save. It fails if the features the model
+// was trained on are no longer the features its params calculate, as its
+// predictions would be meaningless.
+func Load(db *leveldb.DB, r io.Reader) (*Model, error) {
+	var saved savedModel
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("failed to decode model: %w", err)
+	}
+
+	if saved.Version != modelFormatVersion {
+		return nil, fmt.Errorf("unsupported model format version %d, expected %d", saved.Version, modelFormatVersion)
+	}
+	if schema := FeatureSchemaHash(saved.Params); schema != saved.FeatureSchema {
+		return nil, fmt.Errorf("feature schema %s has changed since the model was trained with %s", schema, saved.FeatureSchema)
+	}
+
+	weights := make([]tensor.Tensor, len(saved.Weights))
+	for i, weight := range saved.Weights {
+		size := 1
+		for _, d := range weight.Shape {
+			size *= d
+		}
+		if len(weight.Shape) == 0 || size != len(weight.Data) {
+			return nil, fmt.Errorf("weight %d has %d values for shape %v", i, len(weight.Data), weight.Shape)
+		}
+		weights[i] = tensor.New(
+			tensor.WithShape(weight.Shape...),
+			tensor.Of(tensor.Float64),
+			tensor.WithBacking(weight.Data),
+		)
+	}
+
+	m, err := NewModelFromWeights(db, saved.Instrument, saved.Params, weights)
+	if err != nil {
+		return nil, err
+	}
+	m.Metrics = saved.Metrics
+	m.From = saved.From
+	m.To = saved.To
+	return m, nil
+}
+
+// NewModelFromWeights creates a model from weights trained with the params,
+// failing if they aren't weights the params' architecture can predict with
+func NewModelFromWeights(db *leveldb.DB, instrument string, params ModelParams, weights []tensor.Tensor) (*Model, error) {
+	predictor, err := checkedPredictor(params, weights)
+	if err != nil {
+		return nil, err
+	}
+	return &Model{
+		weights:    weights,
+		predictor:  predictor,
+		db:         db,
+		params:     params,
+		Instrument: instrument,
+	}, nil
+}
+
+// checkedPredictor prepares the weights and predicts a sample of the params'
+// width with them. GBDT weights are checked first, as malformed trees can
+// loop rather than panic. The predictors index the weights as training laid them
+// out, so weights of another layout panic, which is returned as an error.
+func checkedPredictor(params ModelParams, weights []tensor.Tensor) (p *Predictor, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p, err = nil, fmt.Errorf("weights don't match the model's architecture: %v", r)
+		}
+	}()
+
+	if params.Architecture == ArchitectureGBDT {
+		if err := validateGBDT(weights); err != nil {
+			return nil, err
+		}
+	}
+
+	p = NewPredictor(params, weights)
+	width := len(FeatureNames(params)) * sequenceLength(params)
+	if _, err := p.Predict(make([]float64, width)); err != nil {
+		return nil, fmt.Errorf("weights don't match the model's features: %w", err)
+	}
+	return p, nil
+}
+
+// LoadFile loads a model saved to filename
+func LoadFile(db *leveldb.DB, filename string) (*Model, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := Load(db, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return m, nil
+}
+
+// Params returns the params the model was trained with
+func (m *Model) Params() ModelParams {
+	return m.params
+}
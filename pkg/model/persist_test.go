@@ -0,0 +1,161 @@
+package model_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/model"
+	"github.com/grexie/signals/pkg/ta"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"gorgonia.org/tensor"
+)
+
+const testInstrument = "DOGE-USDT-SWAP"
+
+// candleDB stores the candles in an in-memory database, so models read them
+// rather than fetching them
+func candleDB(t *testing.T, candles []model.Candle) *leveldb.DB {
+	t.Helper()
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, candle := range candles {
+		key := fmt.Appendf([]byte{}, "%s-%s-1m-%s", testInstrument, model.Network(), candle.Timestamp.UTC().Format("2006-01-02T15:04"))
+		value, err := json.Marshal(candle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put(key, value, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// testWeights trains a small model of the params' architecture on random
+// samples as wide as the params' features
+func testWeights(t *testing.T, params model.ModelParams) []tensor.Tensor {
+	t.Helper()
+	params.HiddenLayerSize = 8
+	params.BatchSize = 32
+	params.GBDTTrees = 5
+
+	width := len(model.FeatureNames(params))
+	if params.Architecture != model.ArchitectureMLP && params.Architecture != model.ArchitectureGBDT {
+		width *= params.SequenceLength
+	}
+	r := rand.New(rand.NewSource(1))
+	features := make([][]float64, 400)
+	labels := make([]float64, len(features))
+	for i := range features {
+		features[i] = make([]float64, width)
+		for j := range features[i] {
+			features[i][j] = r.Float64()
+		}
+		labels[i] = float64(r.Intn(3))
+	}
+
+	weights, err := model.Train(progress.NewWriter(), params, features, labels, 1)
+	if err != nil {
+		t.Fatalf("%s: %v", params.Architecture, err)
+	}
+	return weights
+}
+
+func TestSaveLoad(t *testing.T) {
+	defaults := model.NewModelParamsFromDefaults()
+	defaults.SequenceLength = 4
+	// the patterns' detectors aren't saved, only their names
+	defaults.CandlePatterns = ta.CandlePatternSet(ta.CandlePatterns)
+
+	candles := randomCandles(model.FeatureWarmup(defaults)+100, 1)
+	db := candleDB(t, candles)
+	now := candles[len(candles)-1].Timestamp.Add(time.Minute)
+	pw := progress.NewWriter()
+
+	for _, architecture := range model.Architectures {
+		params := defaults
+		params.Architecture = architecture
+		m, err := model.NewModelFromWeights(db, testInstrument, params, testWeights(t, params))
+		if err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+
+		_, want, err := m.Predict(pw, nil, now)
+		if err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+
+		var buf bytes.Buffer
+		if err := m.Save(&buf); err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+		saved := slices.Clone(buf.Bytes())
+
+		loaded, err := model.Load(db, &buf)
+		if err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+		if loaded.Params().CandlePatterns.String() != params.CandlePatterns.String() {
+			t.Fatalf("%s: loaded patterns %s, want %s", architecture, loaded.Params().CandlePatterns, params.CandlePatterns)
+		}
+		_, got, err := loaded.Predict(pw, nil, now)
+		if err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+		for s := range want {
+			if got[s] != want[s] {
+				t.Errorf("%s: loaded model predicts %v, want %v", architecture, got, want)
+				break
+			}
+		}
+
+		if _, err := model.Load(db, bytes.NewReader(saved[:len(saved)/2])); err == nil {
+			t.Errorf("%s: loaded a truncated model", architecture)
+		}
+	}
+}
+
+// Weights that don't fit the params fail rather than panicking when
+// predicting
+func TestNewModelFromWeightsMismatch(t *testing.T) {
+	params := model.NewModelParamsFromDefaults()
+	params.SequenceLength = 4
+	weights := testWeights(t, params)
+
+	architecture := func(a model.ModelArchitecture) model.ModelParams {
+		p := params
+		p.Architecture = a
+		return p
+	}
+	noLayers := params
+	noLayers.HiddenLayers = 0
+
+	for _, test := range []struct {
+		name    string
+		params  model.ModelParams
+		weights []tensor.Tensor
+	}{
+		{"no weights", params, nil},
+		{"missing layers", params, weights[:len(weights)-1]},
+		{"no hidden layers", noLayers, weights},
+		{"lstm", architecture(model.ArchitectureLSTM), weights},
+		{"tcn", architecture(model.ArchitectureTCN), weights},
+		{"gbdt", architecture(model.ArchitectureGBDT), weights},
+		{"another width", params, []tensor.Tensor{tensor.New(tensor.WithShape(3, 3), tensor.WithBacking(make([]float64, 9)))}},
+	} {
+		if _, err := model.NewModelFromWeights(nil, testInstrument, test.params, test.weights); err == nil {
+			t.Errorf("%s: created a model from weights that don't fit its params", test.name)
+		}
+	}
+}
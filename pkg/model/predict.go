@@ -2,62 +2,103 @@ package model
 
 import (
 	"fmt"
+	"math"
 
-	"gorgonia.org/gorgonia"
+	"gonum.org/v1/gonum/mat"
 	"gorgonia.org/tensor"
 )
 
-// Updated prediction function to match the deeper network
-// Predict completes the forward pass for inference
-func Predict(weights []tensor.Tensor, input []float64) ([]float64, error) {
-	g := gorgonia.NewGraph()
-	inputSize := len(input)
-
-	// Input tensor with explicit shape (1 x inputSize)
-	xVal := tensor.New(
-		tensor.WithShape(1, inputSize),
-		tensor.Of(tensor.Float64),
-		tensor.WithBacking(input),
-	)
-
-	xTensor := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(1, inputSize),
-		gorgonia.WithValue(xVal))
-
-	// Load weights with explicit shapes
-	w0 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(weights[0].Shape()...),
-		gorgonia.WithValue(weights[0]))
-	w1 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(weights[1].Shape()...),
-		gorgonia.WithValue(weights[1]))
-	w2 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(weights[2].Shape()...),
-		gorgonia.WithValue(weights[2]))
-	w3 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(weights[3].Shape()...),
-		gorgonia.WithValue(weights[3]))
-
-	// Forward pass matching training
-	l0 := gorgonia.Must(gorgonia.Mul(xTensor, w0))
-	l0Act := gorgonia.Must(gorgonia.Rectify(l0))
-
-	l1 := gorgonia.Must(gorgonia.Mul(l0Act, w1))
-	l1Act := gorgonia.Must(gorgonia.Rectify(l1))
-
-	l2 := gorgonia.Must(gorgonia.Mul(l1Act, w2))
-	l2Act := gorgonia.Must(gorgonia.Rectify(l2))
-
-	pred := gorgonia.Must(gorgonia.Mul(l2Act, w3))
-	predSoftmax := gorgonia.Must(gorgonia.SoftMax(pred))
-
-	vm := gorgonia.NewTapeMachine(g)
-	defer vm.Close()
-
-	if err := vm.RunAll(); err != nil {
-		return nil, fmt.Errorf("forward pass failed: %v", err)
-	}
-
-	output := predSoftmax.Value().Data().([]float64)
-	return output, nil
+// batchPredictor returns the probability of each class for each sample of a
+// batch. Each sample's result only depends on that sample, so a batch gives
+// bit for bit what its samples would one at a time.
+type batchPredictor func(features [][]float64) ([][]float64, error)
+
+// Predictor runs a model's weights over samples. The weights are prepared
+// once rather than for every sample, and a batch of samples runs through the
+// classifier together in a few matrix multiplications.
+type Predictor struct {
+	predict batchPredictor
+}
+
+func NewPredictor(params ModelParams, weights []tensor.Tensor) *Predictor {
+	return &Predictor{params.Architecture.classifier().predictor(params, weights)}
+}
+
+// Predict returns the probability of each class for a sample
+func (p *Predictor) Predict(input []float64) ([]float64, error) {
+	out, err := p.predict([][]float64{input})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// PredictBatch returns the probability of each class for each sample,
+// exactly as Predict would for each in turn
+func (p *Predictor) PredictBatch(features [][]float64) ([][]float64, error) {
+	if len(features) == 0 {
+		return [][]float64{}, nil
+	}
+	return p.predict(features)
+}
+
+// Predict completes the forward pass for inference with the params'
+// architecture, returning the probability of each class. Use a Predictor to
+// predict more than one sample.
+func Predict(params ModelParams, weights []tensor.Tensor, input []float64) ([]float64, error) {
+	return NewPredictor(params, weights).Predict(input)
+}
+
+// batchMatrix copies a batch of samples of width features into a matrix
+// with a row for each
+func batchMatrix(features [][]float64, width int) (*mat.Dense, error) {
+	data := make([]float64, 0, len(features)*width)
+	for _, f := range features {
+		if len(f) != width {
+			return nil, fmt.Errorf("sample has %d features, expected %d", len(f), width)
+		}
+		data = append(data, f...)
+	}
+	return mat.NewDense(len(features), width, data), nil
+}
+
+// sequenceBatch flattens a batch of sequences, returning them with the
+// number of features in each of their rows
+func sequenceBatch(params ModelParams, features [][]float64) ([]float64, int, error) {
+	rowSize, err := sequenceRowSize(params, len(features[0]))
+	if err != nil {
+		return nil, 0, err
+	}
+	batch, err := batchMatrix(features, len(features[0]))
+	if err != nil {
+		return nil, 0, err
+	}
+	return batch.RawMatrix().Data, rowSize, nil
+}
+
+// softmaxRows returns the softmax of each row of the logits
+func softmaxRows(logits *mat.Dense) [][]float64 {
+	rows, _ := logits.Dims()
+	out := make([][]float64, rows)
+	for i := range out {
+		out[i] = softmax(logits.RawRowView(i))
+	}
+	return out
+}
+
+func softmax(logits []float64) []float64 {
+	out := make([]float64, len(logits))
+	maxLogit := math.Inf(-1)
+	for _, v := range logits {
+		maxLogit = math.Max(maxLogit, v)
+	}
+	sum := 0.0
+	for i, v := range logits {
+		out[i] = math.Exp(v - maxLogit)
+		sum += out[i]
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+	return out
 }
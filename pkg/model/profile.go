@@ -0,0 +1,61 @@
+package model
+
+import "github.com/grexie/signals/pkg/ta"
+
+// Optional session VWAP and volume profile feature groups
+
+var sessionVWAPFeatures = featureSpec{
+	name:    "session_vwap",
+	enabled: func(p ModelParams) bool { return p.SessionVWAPEnabled },
+	warmup:  func(p ModelParams) int { return sessionWarmup(p.SessionVWAPAnchor) },
+	compute: func(in *featureInput) []featureSeries {
+		vwap, upper, lower := ta.AnchoredVWAP(in.candles, in.params.SessionVWAPAnchor, in.params.SessionVWAPMultiplier)
+		return []featureSeries{
+			windowed("distance", in.series(func(i int) float64 {
+				return relativeDistance(in.closes[i], vwap[i])
+			})),
+			raw("position", in.series(func(i int) float64 {
+				return channelPosition(in.closes[i], lower[i], upper[i])
+			})),
+		}
+	},
+}
+
+var volumeProfileFeatures = featureSpec{
+	name:    "volume_profile",
+	enabled: func(p ModelParams) bool { return p.VolumeProfileEnabled },
+	warmup:  func(p ModelParams) int { return p.VolumeProfileWindow },
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		poc, vah, val := ta.VolumeProfile(in.candles, p.VolumeProfileWindow, p.VolumeProfileBinWidth, p.VolumeProfileValueArea)
+		return []featureSeries{
+			windowed("poc_distance", in.series(func(i int) float64 {
+				return relativeDistance(in.closes[i], poc[i])
+			})),
+			raw("value_area_position", in.series(func(i int) float64 {
+				return channelPosition(in.closes[i], val[i], vah[i])
+			})),
+			raw("above_value_area", in.series(func(i int) float64 {
+				return boolToFloat(vah[i] > 0 && in.closes[i] > vah[i])
+			})),
+			raw("below_value_area", in.series(func(i int) float64 {
+				return boolToFloat(val[i] > 0 && in.closes[i] < val[i])
+			})),
+		}
+	},
+}
+
+// Graded candle patterns selected by SIGNALS_CANDLE_PATTERNS
+var candlePatternFeatures = featureSpec{
+	name:    "candle_pattern",
+	enabled: func(p ModelParams) bool { return len(p.CandlePatterns) > 0 },
+	warmup:  func(ModelParams) int { return 10 },
+	compute: func(in *featureInput) []featureSeries {
+		strengths := in.params.CandlePatterns.Strengths(in.candles)
+		series := make([]featureSeries, len(strengths))
+		for j, strength := range strengths {
+			series[j] = raw(in.params.CandlePatterns[j].Name, strength)
+		}
+		return series
+	},
+}
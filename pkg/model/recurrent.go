@@ -0,0 +1,256 @@
+package model
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"gonum.org/v1/gonum/mat"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// recurrentClassifier is an LSTM or GRU network over the features of the
+// last SequenceLength candles
+type recurrentClassifier struct {
+	architecture ModelArchitecture
+}
+
+func (recurrentClassifier) sequential() bool {
+	return true
+}
+
+func (c recurrentClassifier) train(tracker *progress.Tracker, params ModelParams, features [][]float64, labels []float64, epochs int) ([]tensor.Tensor, error) {
+	rowSize, err := sequenceRowSize(params, len(features[0]))
+	if err != nil {
+		return nil, err
+	}
+	net, err := newRecurrentNetwork(params, c.architecture, rowSize)
+	if err != nil {
+		return nil, err
+	}
+	return fit(tracker, params, net, features, labels, epochs)
+}
+
+func (c recurrentClassifier) predictor(params ModelParams, weights []tensor.Tensor) batchPredictor {
+	hiddenSize := weights[1].Shape()[0]
+	wOut := weightMatrix(weights[len(weights)-1])
+
+	return func(features [][]float64) ([][]float64, error) {
+		batch, rowSize, err := sequenceBatch(params, features)
+		if err != nil {
+			return nil, err
+		}
+
+		state := newRecurrentState(len(features), hiddenSize)
+		state.run(c.architecture, weights, batch, rowSize, params.SequenceLength, 0, params.SequenceLength)
+
+		var logits mat.Dense
+		logits.Mul(state.h, wOut)
+		return softmaxRows(&logits), nil
+	}
+}
+
+// The recurrent weights are an input and a recurrent weight for each gate,
+// in the order below, then the output weights:
+//
+//	LSTM: input, forget, output, candidate
+//	GRU:  update, reset, candidate
+func recurrentGates(architecture ModelArchitecture) int {
+	if architecture == ArchitectureLSTM {
+		return 4
+	}
+	return 3
+}
+
+// newRecurrentNetwork builds an LSTM or GRU network over sequences of rows
+// of rowSize features. Truncated backpropagation through time only unrolls
+// the last BPTTSteps steps in the graph; the state they start from is
+// calculated outside it with the current weights, so no gradient flows
+// into the earlier steps.
+func newRecurrentNetwork(params ModelParams, architecture ModelArchitecture, rowSize int) (*network, error) {
+	batchSize := params.BatchSize
+	hiddenSize := params.HiddenLayerSize
+	length := params.SequenceLength
+	steps := min(params.BPTTSteps, length)
+
+	g := gorgonia.NewGraph()
+
+	xs := make([]*gorgonia.Node, steps)
+	for t := range xs {
+		xs[t] = gorgonia.NewMatrix(g, tensor.Float64,
+			gorgonia.WithShape(batchSize, rowSize),
+			gorgonia.WithName(fmt.Sprintf("x%d", t)))
+	}
+	yTensor := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(batchSize, outputSize),
+		gorgonia.WithName("y"))
+	h0 := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(batchSize, hiddenSize),
+		gorgonia.WithName("h0"))
+	c0 := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(batchSize, hiddenSize),
+		gorgonia.WithName("c0"))
+
+	weights := gorgonia.Nodes{}
+	for gate := range recurrentGates(architecture) {
+		weights = append(weights,
+			gorgonia.NewMatrix(g, tensor.Float64,
+				gorgonia.WithShape(rowSize, hiddenSize),
+				gorgonia.WithInit(gorgonia.GlorotN(1.0)),
+				gorgonia.WithName(fmt.Sprintf("wx%d", gate))),
+			gorgonia.NewMatrix(g, tensor.Float64,
+				gorgonia.WithShape(hiddenSize, hiddenSize),
+				gorgonia.WithInit(gorgonia.GlorotN(1.0)),
+				gorgonia.WithName(fmt.Sprintf("wh%d", gate))),
+		)
+	}
+	wOut := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(hiddenSize, outputSize),
+		gorgonia.WithInit(gorgonia.GlorotN(1.0)),
+		gorgonia.WithName("wout"))
+	weights = append(weights, wOut)
+
+	h, c := h0, c0
+	for _, x := range xs {
+		h, c = recurrentCellGraph(architecture, weights, x, h, c)
+	}
+	hDrop := gorgonia.Must(gorgonia.Dropout(h, params.DropoutRate))
+	pred := gorgonia.Must(gorgonia.Mul(hDrop, wOut))
+
+	return &network{
+		g:       g,
+		y:       yTensor,
+		loss:    classificationLoss(params, pred, yTensor, weights),
+		weights: weights,
+		let: func(batch []float64) error {
+			values := make([]tensor.Tensor, len(weights))
+			for i, w := range weights {
+				values[i] = w.Value().(tensor.Tensor)
+			}
+
+			state := newRecurrentState(batchSize, hiddenSize)
+			state.run(architecture, values, batch, rowSize, length, 0, length-steps)
+			if err := gorgonia.Let(h0, tensor.New(tensor.WithShape(batchSize, hiddenSize), tensor.WithBacking(state.h.RawMatrix().Data))); err != nil {
+				return err
+			}
+			if err := gorgonia.Let(c0, tensor.New(tensor.WithShape(batchSize, hiddenSize), tensor.WithBacking(state.c.RawMatrix().Data))); err != nil {
+				return err
+			}
+
+			for t, x := range xs {
+				step := sequenceStep(batch, batchSize, rowSize, length, length-steps+t)
+				if err := gorgonia.Let(x, tensor.New(tensor.WithShape(batchSize, rowSize), tensor.WithBacking(step))); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// recurrentCellGraph adds one step of the cell to the graph, returning the
+// new hidden and cell state. GRUs have no cell state and return c as is.
+func recurrentCellGraph(architecture ModelArchitecture, weights gorgonia.Nodes, x, h, c *gorgonia.Node) (*gorgonia.Node, *gorgonia.Node) {
+	gate := func(i int, hidden *gorgonia.Node) *gorgonia.Node {
+		return gorgonia.Must(gorgonia.Add(
+			gorgonia.Must(gorgonia.Mul(x, weights[2*i])),
+			gorgonia.Must(gorgonia.Mul(hidden, weights[2*i+1])),
+		))
+	}
+	sigmoid := func(n *gorgonia.Node) *gorgonia.Node { return gorgonia.Must(gorgonia.Sigmoid(n)) }
+	tanh := func(n *gorgonia.Node) *gorgonia.Node { return gorgonia.Must(gorgonia.Tanh(n)) }
+	mul := func(a, b *gorgonia.Node) *gorgonia.Node { return gorgonia.Must(gorgonia.HadamardProd(a, b)) }
+	add := func(a, b *gorgonia.Node) *gorgonia.Node { return gorgonia.Must(gorgonia.Add(a, b)) }
+
+	if architecture == ArchitectureLSTM {
+		i := sigmoid(gate(0, h))
+		f := sigmoid(gate(1, h))
+		o := sigmoid(gate(2, h))
+		candidate := tanh(gate(3, h))
+		c = add(mul(f, c), mul(i, candidate))
+		return mul(o, tanh(c)), c
+	}
+
+	z := sigmoid(gate(0, h))
+	r := sigmoid(gate(1, h))
+	candidate := tanh(gate(2, mul(r, h)))
+	// (1-z)*candidate + z*h
+	return add(candidate, mul(z, gorgonia.Must(gorgonia.Sub(h, candidate)))), c
+}
+
+// sequenceStep gathers the rows at step t of a batch of sequences
+func sequenceStep(batch []float64, batchSize, rowSize, length, t int) []float64 {
+	step := make([]float64, batchSize*rowSize)
+	for b := range batchSize {
+		offset := (b*length + t) * rowSize
+		copy(step[b*rowSize:(b+1)*rowSize], batch[offset:offset+rowSize])
+	}
+	return step
+}
+
+// recurrentState is the hidden and cell state of a batch of sequences,
+// stepped outside the graph exactly as recurrentCellGraph steps it inside
+type recurrentState struct {
+	h, c *mat.Dense
+}
+
+func newRecurrentState(batchSize, hiddenSize int) *recurrentState {
+	return &recurrentState{
+		h: mat.NewDense(batchSize, hiddenSize, nil),
+		c: mat.NewDense(batchSize, hiddenSize, nil),
+	}
+}
+
+func weightMatrix(w tensor.Tensor) *mat.Dense {
+	shape := w.Shape()
+	return mat.NewDense(shape[0], shape[1], w.Data().([]float64))
+}
+
+// run steps the state through steps from up to to of the sequences
+func (s *recurrentState) run(architecture ModelArchitecture, weights []tensor.Tensor, batch []float64, rowSize, length, from, to int) {
+	batchSize, hiddenSize := s.h.Dims()
+
+	gate := func(i int, x, hidden *mat.Dense, activation func(float64) float64) *mat.Dense {
+		var in, rec mat.Dense
+		in.Mul(x, weightMatrix(weights[2*i]))
+		rec.Mul(hidden, weightMatrix(weights[2*i+1]))
+		in.Add(&in, &rec)
+		in.Apply(func(_, _ int, v float64) float64 { return activation(v) }, &in)
+		return &in
+	}
+
+	for t := from; t < to; t++ {
+		x := mat.NewDense(batchSize, rowSize, sequenceStep(batch, batchSize, rowSize, length, t))
+
+		if architecture == ArchitectureLSTM {
+			i := gate(0, x, s.h, sigmoid)
+			f := gate(1, x, s.h, sigmoid)
+			o := gate(2, x, s.h, sigmoid)
+			candidate := gate(3, x, s.h, math.Tanh)
+			for r := range batchSize {
+				for k := range hiddenSize {
+					c := f.At(r, k)*s.c.At(r, k) + i.At(r, k)*candidate.At(r, k)
+					s.c.Set(r, k, c)
+					s.h.Set(r, k, o.At(r, k)*math.Tanh(c))
+				}
+			}
+			continue
+		}
+
+		z := gate(0, x, s.h, sigmoid)
+		reset := gate(1, x, s.h, sigmoid)
+		reset.MulElem(reset, s.h)
+		candidate := gate(2, x, reset, math.Tanh)
+		for r := range batchSize {
+			for k := range hiddenSize {
+				n := candidate.At(r, k)
+				s.h.Set(r, k, n+z.At(r, k)*(s.h.At(r, k)-n))
+			}
+		}
+	}
+}
+
+func sigmoid(v float64) float64 {
+	return 1 / (1 + math.Exp(-v))
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/grexie/signals/pkg/candles"
+	"github.com/grexie/signals/pkg/ta"
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Instruments is a list of instrument IDs such as BTC-USDT-SWAP
+type Instruments []string
+
+// ParseInstruments parses comma separated instrument IDs
+func ParseInstruments(s string) (Instruments, error) {
+	instruments := Instruments{}
+	for _, instrument := range strings.Split(s, ",") {
+		instrument = strings.ToUpper(strings.TrimSpace(instrument))
+		if instrument == "" {
+			continue
+		}
+		if strings.ContainsAny(instrument, " \t") {
+			return nil, fmt.Errorf("invalid instrument %q", instrument)
+		}
+		instruments = append(instruments, instrument)
+	}
+	return instruments, nil
+}
+
+func (i Instruments) String() string {
+	return strings.Join(i, ",")
+}
+
+// References holds the candles of each reference instrument
+type References map[string][]Candle
+
+// GetReferences fetches the candles of every reference instrument in params
+// between from and to
+func GetReferences(db *leveldb.DB, pw progress.Writer, params ModelParams, from, to time.Time) (References, error) {
+	references := References{}
+	for _, instrument := range params.ReferenceInstruments {
+		c, err := candles.GetCandles(db, pw, instrument, candles.Network(Network()), from, to)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching reference %s: %v", instrument, err)
+		}
+		references[instrument] = c
+	}
+	return references, nil
+}
+
+// alignCloses returns the close of the reference candle at or before each
+// candle's timestamp, so gaps carry the last close forward. Candles before
+// the first reference candle are NaN.
+func alignCloses(candles []Candle, reference []Candle) []float64 {
+	out := make([]float64, len(candles))
+	j := 0
+	for i, candle := range candles {
+		for j < len(reference) && !reference[j].Timestamp.After(candle.Timestamp) {
+			j++
+		}
+		if j == 0 {
+			out[i] = math.NaN()
+		} else {
+			out[i] = reference[j-1].Close
+		}
+	}
+	return out
+}
+
+// Cross-asset features from SIGNALS_REFERENCE_INSTRUMENTS, per reference:
+// the instrument's return over the window relative to the reference's, the
+// rolling beta and correlation of their returns, and the reference's return
+// over the last SIGNALS_REFERENCE_LAG candles with how well it has led the
+// instrument.
+var referenceFeatures = featureSpec{
+	name:    "reference",
+	enabled: func(p ModelParams) bool { return len(p.ReferenceInstruments) > 0 },
+	warmup:  func(p ModelParams) int { return p.ReferenceWindow + p.ReferenceLag + 1 },
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		window, lag := p.ReferenceWindow, p.ReferenceLag
+		returns := ta.LogReturns(in.closes)
+
+		series := []featureSeries{}
+		for _, instrument := range p.ReferenceInstruments {
+			closes := alignCloses(in.candles, in.references[instrument])
+			// the first candle with a full window and lag of the reference
+			first := len(closes)
+			for i, c := range closes {
+				if !math.IsNaN(c) {
+					first = i + window + lag + 1
+					break
+				}
+			}
+			missing := func(values []float64) []float64 {
+				for i := 0; i < min(first, len(values)); i++ {
+					values[i] = math.NaN()
+				}
+				return values
+			}
+
+			referenceReturns := ta.LogReturns(closes)
+			series = append(series,
+				windowed(instrument+".relative_strength", missing(in.series(func(i int) float64 {
+					if i < window || closes[i-window] <= 0 || in.closes[i-window] <= 0 {
+						return 0
+					}
+					return math.Log(in.closes[i]/in.closes[i-window]) - math.Log(closes[i]/closes[i-window])
+				}))),
+				windowed(instrument+".beta", missing(ta.Beta(returns, referenceReturns, window))),
+				ranged(instrument+".correlation", missing(ta.Correlation(returns, referenceReturns, window)), -1, 1),
+				windowed(instrument+".lag_return", missing(in.series(func(i int) float64 {
+					if i < lag || closes[i-lag] <= 0 {
+						return 0
+					}
+					return math.Log(closes[i] / closes[i-lag])
+				}))),
+				ranged(instrument+".lead_correlation", missing(ta.Correlation(returns, ta.Lag(referenceReturns, lag), window)), -1, 1),
+			)
+		}
+		return series
+	},
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grexie/signals/pkg/candles"
+	"github.com/grexie/signals/pkg/ta"
+	"github.com/jedib0t/go-pretty/v6/progress"
+)
+
+// classifyRegimes labels each candle with its market regime
+func classifyRegimes(candles []Candle, params ModelParams) []ta.Regime {
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	return ta.ClassifyRegimes(closes, params.RegimeWindow, params.RegimeLookback, params.RegimeTrendThreshold, params.RegimeVolatilityThreshold)
+}
+
+func regimeWarmup(params ModelParams) int {
+	return params.RegimeWindow + params.RegimeLookback
+}
+
+// Optional regime feature group, one-hot encoding the regime of each candle
+var regimeFeatures = featureSpec{
+	name:    "regime",
+	enabled: func(p ModelParams) bool { return p.RegimeEnabled },
+	warmup:  regimeWarmup,
+	compute: func(in *featureInput) []featureSeries {
+		regimes := classifyRegimes(in.candles, in.params)
+		series := make([]featureSeries, len(ta.Regimes))
+		for j, regime := range ta.Regimes {
+			series[j] = raw(regime.String(), in.series(func(i int) float64 {
+				return boolToFloat(regimes[i] == regime)
+			}))
+		}
+		return series
+	},
+}
+
+// Regime classifies the market at now from the model's instrument
+func (m *Model) Regime(pw progress.Writer, now time.Time) (ta.Regime, error) {
+	from := now.Truncate(time.Minute).Add(-time.Duration(regimeWarmup(m.params)+1) * time.Minute)
+	candles, err := candles.GetCandles(m.db, pw, m.Instrument, candles.Network(Network()), from, now)
+	if err != nil {
+		return ta.RegimeRanging, err
+	}
+	if len(candles) == 0 {
+		return ta.RegimeRanging, fmt.Errorf("no candle data received")
+	}
+	regimes := classifyRegimes(candles, m.params)
+	return regimes[len(regimes)-1], nil
+}
+
+// RegimeMetrics summarises the trades entered in one regime
+type RegimeMetrics struct {
+	Candles int
+	Trades  int
+	Wins    int
+	Return  float64
+}
+
+func (r RegimeMetrics) WinRate() float64 {
+	if r.Trades == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Trades) * 100
+}
+
+func (r RegimeMetrics) MeanReturn() float64 {
+	if r.Trades == 0 {
+		return 0
+	}
+	return r.Return / float64(r.Trades)
+}
+
+// RegimeBreakdown holds the metrics of each regime, indexed by ta.Regime
+type RegimeBreakdown []RegimeMetrics
+
+func NewRegimeBreakdown() RegimeBreakdown {
+	return make(RegimeBreakdown, len(ta.Regimes))
+}
+
+// AddTrades counts the closed trades against the regime they were entered in
+func (b RegimeBreakdown) AddTrades(trades []Trade) {
+	for _, trade := range trades {
+		if trade.PercentageReturn == nil {
+			continue
+		}
+		r := &b[trade.Regime]
+		r.Trades++
+		if *trade.PercentageReturn > 0 {
+			r.Wins++
+		}
+		r.Return += *trade.PercentageReturn * 100
+	}
+}
+
+// Add sums another breakdown into b
+func (b RegimeBreakdown) Add(other RegimeBreakdown) {
+	for i := range other {
+		b[i].Candles += other[i].Candles
+		b[i].Trades += other[i].Trades
+		b[i].Wins += other[i].Wins
+		b[i].Return += other[i].Return
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import "github.com/grexie/signals/pkg/ta"
+
+// Optional statistical and microstructure feature group over
+// SIGNALS_STATISTICS_WINDOW candles
+
+// Sample entropy is measured on log returns standardised against the window
+// ending at each return, which only depends on earlier candles, so it needs
+// two windows to warm up
+var statisticsFeatures = featureSpec{
+	name:    "statistics",
+	enabled: func(p ModelParams) bool { return p.StatisticsEnabled },
+	warmup:  func(p ModelParams) int { return 2*p.StatisticsWindow + 1 },
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		window := p.StatisticsWindow
+		returns := ta.LogReturns(in.closes)
+		standardised := ta.ZScore(returns, window)
+
+		return []featureSeries{
+			windowed("parkinson", ta.ParkinsonVolatility(in.highs, in.lows, window)),
+			windowed("garman_klass", ta.GarmanKlassVolatility(in.opens, in.highs, in.lows, in.closes, window)),
+			windowed("yang_zhang", ta.YangZhangVolatility(in.opens, in.highs, in.lows, in.closes, window)),
+			ranged("zscore", ta.ZScore(in.closes, window), -3, 3),
+			ranged("skewness", ta.Skewness(returns, window), -3, 3),
+			windowed("kurtosis", ta.Kurtosis(returns, window)),
+			ranged("autocorrelation", ta.Autocorrelation(returns, window, p.AutocorrelationLag), -1, 1),
+			raw("hurst", ta.Hurst(in.closes, window)),
+			windowed("sample_entropy", ta.SampleEntropy(standardised, window, p.SampleEntropyDimension, p.SampleEntropyTolerance)),
+			windowed("amihud", ta.AmihudIlliquidity(in.closes, in.volumes, window)),
+		}
+	},
+}
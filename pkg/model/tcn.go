@@ -0,0 +1,230 @@
+package model
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"gonum.org/v1/gonum/mat"
+	"gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+// ohlcvFeatures are the candles themselves, as channels for the TCN to find
+// shapes in: the open, high, low and close as log returns from the previous
+// close, and the volume scaled over the window
+var ohlcvFeatures = featureSpec{
+	name:    "ohlcv",
+	enabled: func(p ModelParams) bool { return p.Architecture == ArchitectureTCN },
+	warmup:  func(ModelParams) int { return 1 },
+	compute: func(in *featureInput) []featureSeries {
+		change := func(prices []float64) []float64 {
+			return in.series(func(i int) float64 {
+				previous := in.opens[0]
+				if i > 0 {
+					previous = in.closes[i-1]
+				}
+				return math.Log(prices[i] / previous)
+			})
+		}
+
+		// 1m candles rarely move more than a percent
+		return []featureSeries{
+			scaled("open", change(in.opens), 0.01),
+			scaled("high", change(in.highs), 0.01),
+			scaled("low", change(in.lows), 0.01),
+			scaled("close", change(in.closes), 0.01),
+			windowed("volume", in.volumes),
+		}
+	},
+}
+
+// tcnClassifier is a temporal convolutional network over the features of the
+// last SequenceLength candles. Each layer is a causal convolution of
+// TCNKernelSize taps, dilated by twice the layer before it, with enough
+// layers for the last output to see the whole sequence. Every layer after
+// the first adds its input back as a residual.
+type tcnClassifier struct{}
+
+func (tcnClassifier) sequential() bool {
+	return true
+}
+
+func (tcnClassifier) train(tracker *progress.Tracker, params ModelParams, features [][]float64, labels []float64, epochs int) ([]tensor.Tensor, error) {
+	rowSize, err := sequenceRowSize(params, len(features[0]))
+	if err != nil {
+		return nil, err
+	}
+	net, err := newTCNNetwork(params, rowSize)
+	if err != nil {
+		return nil, err
+	}
+	return fit(tracker, params, net, features, labels, epochs)
+}
+
+func (tcnClassifier) predictor(params ModelParams, weights []tensor.Tensor) batchPredictor {
+	wOut := weightMatrix(weights[len(weights)-1])
+
+	return func(features [][]float64) ([][]float64, error) {
+		batch, rowSize, err := sequenceBatch(params, features)
+		if err != nil {
+			return nil, err
+		}
+
+		var logits mat.Dense
+		logits.Mul(tcnForward(params, weights, batch, len(features), rowSize), wOut)
+		return softmaxRows(&logits), nil
+	}
+}
+
+// tcnLayers is the number of layers for the receptive field of the last
+// output to cover the sequence
+func tcnLayers(kernel, length int) int {
+	layers, field := 1, kernel
+	for field < length {
+		field += (kernel - 1) << layers
+		layers++
+	}
+	return layers
+}
+
+// The TCN weights are each layer's taps in turn, then the output weights.
+// Tap j of a layer with dilation d multiplies its input d*j steps back.
+func newTCNNetwork(params ModelParams, rowSize int) (*network, error) {
+	batchSize := params.BatchSize
+	channels := params.HiddenLayerSize
+	length := params.SequenceLength
+	kernel := params.TCNKernelSize
+	layers := tcnLayers(kernel, length)
+
+	g := gorgonia.NewGraph()
+
+	xs := make([]*gorgonia.Node, length)
+	for t := range xs {
+		xs[t] = gorgonia.NewMatrix(g, tensor.Float64,
+			gorgonia.WithShape(batchSize, rowSize),
+			gorgonia.WithName(fmt.Sprintf("x%d", t)))
+	}
+	yTensor := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(batchSize, outputSize),
+		gorgonia.WithName("y"))
+
+	weights := gorgonia.Nodes{}
+	inputSize := rowSize
+	for layer := range layers {
+		for tap := range kernel {
+			weights = append(weights, gorgonia.NewMatrix(g, tensor.Float64,
+				gorgonia.WithShape(inputSize, channels),
+				gorgonia.WithInit(gorgonia.GlorotN(1.0)),
+				gorgonia.WithName(fmt.Sprintf("w%d_%d", layer, tap))))
+		}
+		inputSize = channels
+	}
+	wOut := gorgonia.NewMatrix(g, tensor.Float64,
+		gorgonia.WithShape(channels, outputSize),
+		gorgonia.WithInit(gorgonia.GlorotN(1.0)),
+		gorgonia.WithName("wout"))
+	weights = append(weights, wOut)
+
+	// only the outputs the last step depends on are added to the graph
+	outputs := map[[2]int]*gorgonia.Node{}
+	var output func(layer, t int) *gorgonia.Node
+	output = func(layer, t int) *gorgonia.Node {
+		if t < 0 {
+			// causal padding
+			return nil
+		}
+		if layer < 0 {
+			return xs[t]
+		}
+		if n, ok := outputs[[2]int{layer, t}]; ok {
+			return n
+		}
+
+		var sum *gorgonia.Node
+		for tap := range kernel {
+			in := output(layer-1, t-tap<<layer)
+			if in == nil {
+				continue
+			}
+			n := gorgonia.Must(gorgonia.Mul(in, weights[layer*kernel+tap]))
+			if sum == nil {
+				sum = n
+			} else {
+				sum = gorgonia.Must(gorgonia.Add(sum, n))
+			}
+		}
+		n := gorgonia.Must(gorgonia.Rectify(sum))
+		if layer > 0 {
+			n = gorgonia.Must(gorgonia.Add(n, output(layer-1, t)))
+		}
+		outputs[[2]int{layer, t}] = n
+		return n
+	}
+
+	h := output(layers-1, length-1)
+	hDrop := gorgonia.Must(gorgonia.Dropout(h, params.DropoutRate))
+	pred := gorgonia.Must(gorgonia.Mul(hDrop, wOut))
+
+	return &network{
+		g:       g,
+		y:       yTensor,
+		loss:    classificationLoss(params, pred, yTensor, weights),
+		weights: weights,
+		let: func(batch []float64) error {
+			for t, x := range xs {
+				step := sequenceStep(batch, batchSize, rowSize, length, t)
+				if err := gorgonia.Let(x, tensor.New(tensor.WithShape(batchSize, rowSize), tensor.WithBacking(step))); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// tcnForward runs a batch of sequences through the convolutions exactly as
+// newTCNNetwork's graph does, returning the last layer's output at the last
+// step
+func tcnForward(params ModelParams, weights []tensor.Tensor, batch []float64, batchSize, rowSize int) *mat.Dense {
+	length := params.SequenceLength
+	kernel := params.TCNKernelSize
+	layers := (len(weights) - 1) / kernel
+
+	outputs := map[[2]int]*mat.Dense{}
+	var output func(layer, t int) *mat.Dense
+	output = func(layer, t int) *mat.Dense {
+		if t < 0 {
+			return nil
+		}
+		if layer < 0 {
+			return mat.NewDense(batchSize, rowSize, sequenceStep(batch, batchSize, rowSize, length, t))
+		}
+		if n, ok := outputs[[2]int{layer, t}]; ok {
+			return n
+		}
+
+		var sum *mat.Dense
+		for tap := range kernel {
+			in := output(layer-1, t-tap<<layer)
+			if in == nil {
+				continue
+			}
+			var n mat.Dense
+			n.Mul(in, weightMatrix(weights[layer*kernel+tap]))
+			if sum == nil {
+				sum = &n
+			} else {
+				sum.Add(sum, &n)
+			}
+		}
+		sum.Apply(func(_, _ int, v float64) float64 { return math.Max(v, 0) }, sum)
+		if layer > 0 {
+			sum.Add(sum, output(layer-1, t))
+		}
+		outputs[[2]int{layer, t}] = sum
+		return sum
+	}
+
+	return output(layers-1, length-1)
+}
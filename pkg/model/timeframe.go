@@ -0,0 +1,156 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+// Timeframe is a feature group, or a single feature of it, computed on
+// higher timeframe bars, such as 15m:base.rsi_long or 4h:volatility
+type Timeframe struct {
+	Period  time.Duration
+	Group   string
+	Feature string
+}
+
+// Timeframes lists the higher timeframe feature groups
+type Timeframes []Timeframe
+
+// featureSpecsByName indexes the feature groups for the timeframe group,
+// filled in init as the timeframe group is itself a feature group
+var featureSpecsByName map[string]featureSpec
+
+func init() {
+	featureSpecsByName = map[string]featureSpec{}
+	for _, spec := range featureSpecs {
+		featureSpecsByName[spec.name] = spec
+	}
+}
+
+// names lists the group's features by computing it on no candles. Every
+// candle pattern is enabled, as those features depend on the params.
+func (spec featureSpec) names() []string {
+	in := newFeatureInput(nil, nil, ModelParams{CandlePatterns: ta.CandlePatternSet(ta.CandlePatterns)})
+	names := []string{}
+	for _, s := range spec.compute(in) {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// ParseTimeframes parses comma separated period:group or
+// period:group.feature entries, such as "15m:base.rsi_long,4h:volatility"
+func ParseTimeframes(s string) (Timeframes, error) {
+	timeframes := Timeframes{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(strings.ToLower(entry))
+		if entry == "" || entry == "none" {
+			continue
+		}
+
+		period, name, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid timeframe %q, expected period:group", entry)
+		}
+		var t Timeframe
+		var err error
+		if t.Period, err = time.ParseDuration(period); err != nil {
+			return nil, fmt.Errorf("invalid timeframe %q: %w", entry, err)
+		}
+		if t.Period <= time.Minute || t.Period%time.Minute != 0 {
+			return nil, fmt.Errorf("invalid timeframe %q: period must be a multiple of 1m above 1m", entry)
+		}
+		t.Group, t.Feature, _ = strings.Cut(name, ".")
+		switch t.Group {
+		case "timeframe", "reference":
+			return nil, fmt.Errorf("invalid timeframe %q: %s features can't be resampled", entry, t.Group)
+		}
+		spec, ok := featureSpecsByName[t.Group]
+		if !ok {
+			return nil, fmt.Errorf("invalid timeframe %q: unknown feature group %q", entry, t.Group)
+		}
+		if t.Feature != "" && !slices.Contains(spec.names(), t.Feature) {
+			return nil, fmt.Errorf("invalid timeframe %q: unknown feature %q in group %q", entry, t.Feature, t.Group)
+		}
+		timeframes = append(timeframes, t)
+	}
+	return timeframes, nil
+}
+
+// period formats the period as 15m or 4h rather than 15m0s or 4h0m0s
+func (t Timeframe) period() string {
+	if t.Period%time.Hour == 0 {
+		return fmt.Sprintf("%dh", t.Period/time.Hour)
+	}
+	return fmt.Sprintf("%dm", t.Period/time.Minute)
+}
+
+func (t Timeframe) String() string {
+	if t.Feature != "" {
+		return fmt.Sprintf("%s:%s.%s", t.period(), t.Group, t.Feature)
+	}
+	return fmt.Sprintf("%s:%s", t.period(), t.Group)
+}
+
+func (t Timeframes) String() string {
+	entries := make([]string, len(t))
+	for i, timeframe := range t {
+		entries[i] = timeframe.String()
+	}
+	return strings.Join(entries, ",")
+}
+
+// warmup is the number of 1m candles before the timeframe's values no
+// longer depend on where the candles start: the group's warm-up in bars,
+// the partial first bar that's skipped, and the bar still forming
+func (t Timeframe) warmup(params ModelParams) int {
+	bars := featureSpecsByName[t.Group].warmup(params) + 2
+	return bars * int(t.Period/time.Minute)
+}
+
+// Optional feature groups computed on higher timeframe bars resampled from
+// the 1m candles. Each candle sees the values of the last complete bar, so
+// the bar still forming never leaks into the features.
+var timeframeFeatures = featureSpec{
+	name:    "timeframe",
+	enabled: func(p ModelParams) bool { return len(p.TimeframeFeatures) > 0 },
+	warmup: func(p ModelParams) int {
+		warmup := 0
+		for _, t := range p.TimeframeFeatures {
+			warmup = max(warmup, t.warmup(p))
+		}
+		return warmup
+	},
+	compute: func(in *featureInput) []featureSeries {
+		series := []featureSeries{}
+		for _, t := range in.params.TimeframeFeatures {
+			bars, index := ta.Resample(in.candles, ta.SessionAnchor{Period: t.Period}, time.Minute)
+			barInput := newFeatureInput(bars, nil, in.params)
+
+			found := false
+			for _, s := range featureSpecsByName[t.Group].compute(barInput) {
+				if t.Feature != "" && s.name != t.Feature {
+					continue
+				}
+				found = true
+				values := in.series(func(i int) float64 {
+					if index[i] < 0 {
+						return math.NaN()
+					}
+					return s.values[index[i]]
+				})
+				name := fmt.Sprintf("%s.%s.%s", t.period(), t.Group, s.name)
+				series = append(series, featureSeries{name, values, s.normalization})
+			}
+			if !found {
+				panic(fmt.Sprintf("unknown feature %s in timeframe %s", t.Feature, t))
+			}
+		}
+		return series
+	},
+}
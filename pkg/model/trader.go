@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/grexie/signals/pkg/ta"
 	"gonum.org/v1/gonum/stat"
 )
 
@@ -20,6 +21,17 @@ type PaperTrader struct {
 	Leverage          float64
 	Cooldown          time.Duration
 	NotBefore         *time.Time
+	// Regime is the current market regime, recorded on the trades opened
+	Regime ta.Regime
+	// Support and Resistance, when set, replace the stop loss and take
+	// profit percentages of trades opened between them. A level closer to
+	// the entry than the stop loss percentage would exit on noise, so the
+	// percentage is kept for that exit.
+	Support    float64
+	Resistance float64
+	// BarrierScale, when set, scales the stop loss and take profit
+	// percentages of trades opened, as with volatility barriers
+	BarrierScale float64
 }
 
 // Trade represents an open or closed trade
@@ -33,6 +45,7 @@ type Trade struct {
 	ExitTime         *time.Time
 	ExitPrice        *float64
 	PercentageReturn *float64
+	Regime           ta.Regime
 }
 
 // NewPaperTrader initializes a new paper trader
@@ -65,12 +78,37 @@ func (pt *PaperTrader) AddTrade(entryPrice float64, isLong bool) (*Trade, error)
 	tradeSize := maxTradeCapital * pt.Leverage
 
 	// compute stop loss and take profit levels
-	stopLoss := entryPrice * (1 - pt.StopLossPercent)
-	takeProfit := entryPrice * (1 + pt.TakeProfitPercent)
+	stopLossPercent, takeProfitPercent := pt.StopLossPercent, pt.TakeProfitPercent
+	if pt.BarrierScale > 0 {
+		stopLossPercent *= pt.BarrierScale
+		takeProfitPercent *= pt.BarrierScale
+	}
+	stopLoss := entryPrice * (1 - stopLossPercent)
+	takeProfit := entryPrice * (1 + takeProfitPercent)
 
 	if !isLong {
-		stopLoss = entryPrice * (1 + pt.StopLossPercent)
-		takeProfit = entryPrice * (1 - pt.TakeProfitPercent)
+		stopLoss = entryPrice * (1 + stopLossPercent)
+		takeProfit = entryPrice * (1 - takeProfitPercent)
+	}
+
+	if pt.Support > 0 && pt.Support < entryPrice && pt.Resistance > entryPrice {
+		minDistance := entryPrice * stopLossPercent
+		below, above := entryPrice-pt.Support >= minDistance, pt.Resistance-entryPrice >= minDistance
+		if isLong {
+			if below {
+				stopLoss = pt.Support
+			}
+			if above {
+				takeProfit = pt.Resistance
+			}
+		} else {
+			if above {
+				stopLoss = pt.Resistance
+			}
+			if below {
+				takeProfit = pt.Support
+			}
+		}
 	}
 
 	// calculate and deduct the entry fee
@@ -88,6 +126,7 @@ func (pt *PaperTrader) AddTrade(entryPrice float64, isLong bool) (*Trade, error)
 		StopLoss:   stopLoss,
 		TakeProfit: takeProfit,
 		EntryTime:  time.Now(),
+		Regime:     pt.Regime,
 	}
 
 	return pt.OpenTrade, nil
@@ -0,0 +1,66 @@
+package model_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/model"
+)
+
+func TestPaperTraderLevelExits(t *testing.T) {
+	for name, test := range map[string]struct {
+		support, resistance  float64
+		long                 bool
+		stopLoss, takeProfit float64
+	}{
+		"fixed":                  {0, 0, true, 99, 102},
+		"long levels":            {95, 110, true, 95, 110},
+		"short levels":           {90, 105, false, 105, 90},
+		"long levels too close":  {99.5, 100.5, true, 99, 102},
+		"short levels too close": {99.5, 100.5, false, 101, 98},
+		"long stop too close":    {99.5, 110, true, 99, 110},
+		"outside the levels":     {101, 110, true, 99, 102},
+	} {
+		trader := model.NewPaperTrader(10000, 0.01, 0.02, 0, 1, 0)
+		trader.Support, trader.Resistance = test.support, test.resistance
+		trade, err := trader.AddTrade(100, test.long)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if math.Abs(trade.StopLoss-test.stopLoss) > 1e-9 || math.Abs(trade.TakeProfit-test.takeProfit) > 1e-9 {
+			t.Errorf("%s: stop loss %v and take profit %v, expected %v and %v", name, trade.StopLoss, trade.TakeProfit, test.stopLoss, test.takeProfit)
+		}
+	}
+
+	// volatility barriers scale the percentages and the levels' minimum
+	// distance alike
+	trader := model.NewPaperTrader(10000, 0.01, 0.02, 0, 1, 0)
+	trader.BarrierScale = 2
+	trader.Support, trader.Resistance = 98.5, 110
+	if trade, err := trader.AddTrade(100, true); err != nil {
+		t.Fatal(err)
+	} else if math.Abs(trade.StopLoss-98) > 1e-9 || math.Abs(trade.TakeProfit-110) > 1e-9 {
+		t.Errorf("scaled stop loss %v and take profit %v, expected 98 and 110", trade.StopLoss, trade.TakeProfit)
+	}
+
+	// a long trade holds through the fixed take profit to the resistance
+	trader = model.NewPaperTrader(10000, 0.01, 0.02, 0, 1, 0)
+	trader.Support, trader.Resistance = 95, 110
+	hold := func(model.Candle) model.Strategy { return model.StrategyHold }
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	trader.Iterate(model.Candle{Timestamp: now, Open: 100, High: 100, Low: 100, Close: 100}, func(model.Candle) model.Strategy {
+		return model.StrategyLong
+	})
+	trader.Iterate(model.Candle{Timestamp: now.Add(time.Minute), Open: 100, High: 105, Low: 96, Close: 104}, hold)
+	if trader.OpenTrade == nil {
+		t.Fatal("closed before reaching a level")
+	}
+	trader.Iterate(model.Candle{Timestamp: now.Add(2 * time.Minute), Open: 104, High: 111, Low: 103, Close: 109}, hold)
+	if trader.OpenTrade != nil || len(trader.ClosedTrades) != 1 {
+		t.Fatal("didn't close at the resistance")
+	}
+	if closed := trader.ClosedTrades[0]; *closed.ExitPrice != 110 || math.Abs(*closed.PercentageReturn-0.1) > 1e-9 {
+		t.Fatalf("closed at %v returning %v", *closed.ExitPrice, *closed.PercentageReturn)
+	}
+}
@@ -3,7 +3,6 @@ package model
 import (
 	"fmt"
 	"math"
-	"math/rand/v2"
 	"runtime"
 
 	"github.com/jedib0t/go-pretty/v6/progress"
@@ -11,6 +10,27 @@ import (
 	"gorgonia.org/tensor"
 )
 
+const outputSize = 3
+
+// network is a graph for the training loop to fit: its inputs, its loss
+// and the weights it learns
+type network struct {
+	g       *gorgonia.ExprGraph
+	y       *gorgonia.Node
+	loss    *gorgonia.Node
+	weights gorgonia.Nodes
+
+	// let sets the inputs to a batch of features, flattened row by row
+	let func(batch []float64) error
+
+	// step, if set, is called after each training batch, for layers that
+	// keep statistics of the batches they've seen
+	step func()
+	// state, if set, returns what the network needs to predict beyond its
+	// weights, saved after them
+	state func() []tensor.Tensor
+}
+
 func Train(pw progress.Writer, params ModelParams, features [][]float64, labels []float64, epochs int) ([]tensor.Tensor, error) {
 	tracker := progress.Tracker{
 		Message: "Training",
@@ -20,113 +40,81 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 	pw.AppendTracker(&tracker)
 	tracker.Start()
 
-	// Network architecture with explicit shapes
-	inputSize := len(features[0])
-	hiddenSize1 := params.HiddenLayerSize
-	hiddenSize2 := hiddenSize1 / 2
-	hiddenSize3 := hiddenSize2 / 2
-	outputSize := 3
+	weights, err := params.Architecture.classifier().train(&tracker, params, features, labels, epochs)
+	if err != nil {
+		return nil, err
+	}
+	tracker.MarkAsDone()
+	return weights, nil
+}
+
+// classificationLoss is the cross entropy of the softmax of the logits with
+// L2 regularization of the weights. The targets are one hot, scaled by the
+// weight of their class, and with focal loss each sample's cross entropy is
+// scaled by (1-p)^FocalGamma of its class's probability.
+func classificationLoss(params ModelParams, logits, y *gorgonia.Node, weights gorgonia.Nodes) *gorgonia.Node {
+	predSoftmax := gorgonia.Must(gorgonia.SoftMax(logits))
+
+	losses := gorgonia.Must(gorgonia.HadamardProd(y, gorgonia.Must(gorgonia.Log(predSoftmax))))
+	if params.ClassImbalance == ImbalanceFocal {
+		// exp(gamma log(1-p)) rather than pow, which has no gradient as p
+		// reaches 1
+		remaining := gorgonia.Must(gorgonia.Add(
+			gorgonia.Must(gorgonia.Sub(gorgonia.NewConstant(1.0), predSoftmax)),
+			gorgonia.NewConstant(1e-7)))
+		focal := gorgonia.Must(gorgonia.Exp(gorgonia.Must(gorgonia.Mul(
+			gorgonia.NewConstant(params.FocalGamma),
+			gorgonia.Must(gorgonia.Log(remaining))))))
+		losses = gorgonia.Must(gorgonia.HadamardProd(losses, focal))
+	}
+
+	// Loss with L2 regularization
+	crossEntropy := gorgonia.Must(gorgonia.Neg(
+		gorgonia.Must(gorgonia.Mean(
+			gorgonia.Must(gorgonia.Sum(losses, 1))))))
+
+	// Calculate L2 regularization
+	var sum *gorgonia.Node
+	for _, w := range weights {
+		l2 := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(w))))
+		if sum == nil {
+			sum = l2
+		} else {
+			sum = gorgonia.Must(gorgonia.Add(sum, l2))
+		}
+	}
+	regularization := gorgonia.Must(gorgonia.Mul(gorgonia.NewConstant(params.L2Penalty), sum))
+
+	return gorgonia.Must(gorgonia.Add(crossEntropy, regularization))
+}
+
+// fit trains the network's weights with early stopping on a validation set,
+// returning the weights with the lowest validation loss. Only the training
+// samples are rebalanced.
+func fit(tracker *progress.Tracker, params ModelParams, net *network, features [][]float64, labels []float64, epochs int) ([]tensor.Tensor, error) {
 	batchSize := params.BatchSize
 
 	// Hyperparameters
-	dropoutRate := params.DropoutRate // 0.4
-	l2Penalty := params.L2Penalty     // 0.05
 	validateEvery := 5
 	patience := 10
 
 	// Create validation set (10%)
-	totalSamples := len(features)
-	validationSize := totalSamples / 10
+	validationSize := len(features) / 10
 	if validationSize < batchSize {
 		validationSize = batchSize
 	}
-	trainSize := totalSamples - validationSize
-
-	// Shuffle indices
-	indices := rand.Perm(totalSamples)
-	trainIndices := indices[:trainSize]
-	validIndices := indices[trainSize:]
-
-	g := gorgonia.NewGraph()
-
-	// Input and target tensors
-	xTensor := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(batchSize, inputSize),
-		gorgonia.WithName("x"))
-
-	yTensor := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(batchSize, outputSize),
-		gorgonia.WithName("y"))
-
-	// Initialize weights with explicit shapes
-	w0 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(inputSize, hiddenSize1),
-		gorgonia.WithInit(gorgonia.GlorotN(1.0)),
-		gorgonia.WithName("w0"))
-
-	w1 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(hiddenSize1, hiddenSize2),
-		gorgonia.WithInit(gorgonia.GlorotN(1.0)),
-		gorgonia.WithName("w1"))
-
-	w2 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(hiddenSize2, hiddenSize3),
-		gorgonia.WithInit(gorgonia.GlorotN(1.0)),
-		gorgonia.WithName("w2"))
-
-	w3 := gorgonia.NewMatrix(g, tensor.Float64,
-		gorgonia.WithShape(hiddenSize3, outputSize),
-		gorgonia.WithInit(gorgonia.GlorotN(1.0)),
-		gorgonia.WithName("w3"))
-
-	// Forward pass with dropout and batch normalization
-	l0 := gorgonia.Must(gorgonia.Mul(xTensor, w0))
-	l0Act := gorgonia.Must(gorgonia.Rectify(l0))
-	l0Drop := gorgonia.Must(gorgonia.Dropout(l0Act, dropoutRate))
 
-	l1 := gorgonia.Must(gorgonia.Mul(l0Drop, w1))
-	l1Act := gorgonia.Must(gorgonia.Rectify(l1))
-	l1Drop := gorgonia.Must(gorgonia.Dropout(l1Act, dropoutRate))
-
-	l2 := gorgonia.Must(gorgonia.Mul(l1Drop, w2))
-	l2Act := gorgonia.Must(gorgonia.Rectify(l2))
-	l2Drop := gorgonia.Must(gorgonia.Dropout(l2Act, dropoutRate))
-
-	pred := gorgonia.Must(gorgonia.Mul(l2Drop, w3))
-	predSoftmax := gorgonia.Must(gorgonia.SoftMax(pred))
-
-	// Loss with L2 regularization
-	crossEntropy := gorgonia.Must(gorgonia.Neg(
-		gorgonia.Must(gorgonia.Mean(
-			gorgonia.Must(gorgonia.Sum(
-				gorgonia.Must(gorgonia.HadamardProd(
-					yTensor,
-					gorgonia.Must(gorgonia.Log(predSoftmax)))),
-				1))))))
-
-	// Calculate L2 regularization
-	l2w0 := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(w0))))
-	l2w1 := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(w1))))
-	l2w2 := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(w2))))
-	l2w3 := gorgonia.Must(gorgonia.Mean(gorgonia.Must(gorgonia.Square(w3))))
-
-	regularization := gorgonia.Must(gorgonia.Mul(
-		gorgonia.NewConstant(l2Penalty),
-		gorgonia.Must(gorgonia.Add(
-			gorgonia.Must(gorgonia.Add(l2w0, l2w1)),
-			gorgonia.Must(gorgonia.Add(l2w2, l2w3)),
-		)),
-	))
-
-	loss := gorgonia.Must(gorgonia.Add(crossEntropy, regularization))
+	tracker.Message = fmt.Sprintf("Rebalancing classes (%s)", params.ClassImbalance)
+	features, labels, trainIndices, validIndices := splitValidation(params, features, labels, validationSize)
+	trainSize := len(trainIndices)
 
 	// Calculate gradients
-	if _, err := gorgonia.Grad(loss, w0, w1, w2, w3); err != nil {
+	if _, err := gorgonia.Grad(net.loss, net.weights...); err != nil {
 		return nil, fmt.Errorf("failed to compute gradients: %v", err)
 	}
 
 	// Create VM
-	vm := gorgonia.NewTapeMachine(g,
+	vm := gorgonia.NewTapeMachine(net.g,
 		gorgonia.WithLogger(nil),
 		gorgonia.WithValueFmt("%3.3f"),
 	)
@@ -144,7 +132,27 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 	// Training loop with early stopping
 	bestLoss := math.Inf(1)
 	noImprovementCount := 0
-	bestWeights := make([]tensor.Tensor, 4)
+	bestWeights := []tensor.Tensor{}
+
+	classWeights := classWeights(params, labels, trainIndices)
+
+	let := func(batchIndices []int) error {
+		targets := flattenBatchLabels(labels, batchIndices, outputSize)
+		for i := range targets {
+			targets[i] *= classWeights[i%outputSize]
+		}
+		batchLabels := tensor.New(
+			tensor.WithShape(batchSize, outputSize),
+			tensor.WithBacking(targets))
+
+		if err := net.let(flattenBatchFeatures(features, batchIndices)); err != nil {
+			return fmt.Errorf("failed to update x tensor: %v", err)
+		}
+		if err := gorgonia.Let(net.y, batchLabels); err != nil {
+			return fmt.Errorf("failed to update y tensor: %v", err)
+		}
+		return nil
+	}
 
 	for epoch := range epochs {
 		// Training phase
@@ -158,19 +166,8 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 				break
 			}
 
-			batchIndices := trainIndices[start:end]
-			batchFeatures := tensor.New(
-				tensor.WithShape(batchSize, inputSize),
-				tensor.WithBacking(flattenBatchFeatures(features, batchIndices)))
-			batchLabels := tensor.New(
-				tensor.WithShape(batchSize, outputSize),
-				tensor.WithBacking(flattenBatchLabels(labels, batchIndices, outputSize)))
-
-			if err := gorgonia.Let(xTensor, batchFeatures); err != nil {
-				return nil, fmt.Errorf("failed to update x tensor: %v", err)
-			}
-			if err := gorgonia.Let(yTensor, batchLabels); err != nil {
-				return nil, fmt.Errorf("failed to update y tensor: %v", err)
+			if err := let(trainIndices[start:end]); err != nil {
+				return nil, err
 			}
 
 			vm.Reset()
@@ -178,8 +175,11 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 				return nil, fmt.Errorf("forward/backward pass failed: %v", err)
 			}
 
-			solver.Step(gorgonia.NodesToValueGrads(gorgonia.Nodes{w0, w1, w2, w3}))
-			trainLoss += loss.Value().Data().(float64)
+			solver.Step(gorgonia.NodesToValueGrads(net.weights))
+			if net.step != nil {
+				net.step()
+			}
+			trainLoss += net.loss.Value().Data().(float64)
 		}
 
 		avgTrainLoss := trainLoss / float64(batches)
@@ -196,19 +196,8 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 					break
 				}
 
-				batchIndices := validIndices[start:end]
-				batchFeatures := tensor.New(
-					tensor.WithShape(batchSize, inputSize),
-					tensor.WithBacking(flattenBatchFeatures(features, batchIndices)))
-				batchLabels := tensor.New(
-					tensor.WithShape(batchSize, outputSize),
-					tensor.WithBacking(flattenBatchLabels(labels, batchIndices, outputSize)))
-
-				if err := gorgonia.Let(xTensor, batchFeatures); err != nil {
-					return nil, fmt.Errorf("failed to update validation x tensor: %v", err)
-				}
-				if err := gorgonia.Let(yTensor, batchLabels); err != nil {
-					return nil, fmt.Errorf("failed to update validation y tensor: %v", err)
+				if err := let(validIndices[start:end]); err != nil {
+					return nil, fmt.Errorf("validation: %v", err)
 				}
 
 				vm.Reset()
@@ -216,7 +205,7 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 					return nil, fmt.Errorf("validation forward pass failed: %v", err)
 				}
 
-				validLoss += loss.Value().Data().(float64)
+				validLoss += net.loss.Value().Data().(float64)
 			}
 
 			avgValidLoss := validLoss / float64(validBatches)
@@ -226,10 +215,15 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 				bestLoss = avgValidLoss
 				noImprovementCount = 0
 				// Save best weights
-				bestWeights[0] = w0.Value().(tensor.Tensor).Clone().(tensor.Tensor)
-				bestWeights[1] = w1.Value().(tensor.Tensor).Clone().(tensor.Tensor)
-				bestWeights[2] = w2.Value().(tensor.Tensor).Clone().(tensor.Tensor)
-				bestWeights[3] = w3.Value().(tensor.Tensor).Clone().(tensor.Tensor)
+				bestWeights = bestWeights[:0]
+				for _, w := range net.weights {
+					bestWeights = append(bestWeights, w.Value().(tensor.Tensor).Clone().(tensor.Tensor))
+				}
+				if net.state != nil {
+					for _, t := range net.state() {
+						bestWeights = append(bestWeights, t.Clone().(tensor.Tensor))
+					}
+				}
 			} else {
 				noImprovementCount++
 			}
@@ -249,7 +243,5 @@ func Train(pw progress.Writer, params ModelParams, features [][]float64, labels
 		tracker.SetValue(int64(epoch + 1))
 	}
 
-	tracker.MarkAsDone()
-
 	return bestWeights, nil
 }
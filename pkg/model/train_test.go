@@ -0,0 +1,339 @@
+package model_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/grexie/signals/pkg/model"
+	"github.com/jedib0t/go-pretty/v6/progress"
+)
+
+// sequenceSamples generates sequences whose label depends on a feature
+// several steps before the last, so only a model that remembers it can
+// learn them
+func sequenceSamples(n, length, width int, seed int64) ([][]float64, []float64) {
+	r := rand.New(rand.NewSource(seed))
+	features := make([][]float64, n)
+	labels := make([]float64, n)
+	for i := range features {
+		features[i] = make([]float64, length*width)
+		for j := range features[i] {
+			features[i][j] = r.Float64()*2 - 1
+		}
+		// the networks have no biases, so labels depend on the sign
+		if features[i][(length-3)*width] > 0 {
+			labels[i] = float64(model.StrategyLong)
+		} else {
+			labels[i] = float64(model.StrategyShort)
+		}
+	}
+	return features, labels
+}
+
+func TestSequenceTraining(t *testing.T) {
+	for _, architecture := range []model.ModelArchitecture{model.ArchitectureLSTM, model.ArchitectureGRU, model.ArchitectureTCN} {
+		params := model.NewModelParamsFromDefaults()
+		params.Architecture = architecture
+		params.SequenceLength = 8
+		params.BPTTSteps = 5
+		params.HiddenLayerSize = 16
+		params.BatchSize = 32
+		params.LearnRate = 0.01
+		params.DropoutRate = 0
+		params.L2Penalty = 0
+
+		features, labels := sequenceSamples(2000, params.SequenceLength, 1, 1)
+		weights, err := model.Train(progress.NewWriter(), params, features, labels, 40)
+		if err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+
+		test, testLabels := sequenceSamples(500, params.SequenceLength, 1, 2)
+		correct := 0
+		for i, sample := range test {
+			pred, err := model.Predict(params, weights, sample)
+			if err != nil {
+				t.Fatalf("%s: %v", architecture, err)
+			}
+			best := 0
+			for c := range pred {
+				if pred[c] > pred[best] {
+					best = c
+				}
+			}
+			if best == int(testLabels[i]) {
+				correct++
+			}
+		}
+		if accuracy := float64(correct) / float64(len(test)); accuracy < 0.6 {
+			t.Errorf("%s: accuracy %.2f, expected the model to learn the sequences", architecture, accuracy)
+		}
+	}
+}
+
+func TestGBDTTraining(t *testing.T) {
+	params := model.NewModelParamsFromDefaults()
+	params.Architecture = model.ArchitectureGBDT
+	params.GBDTTrees = 100
+
+	// thresholds and an interaction, which the networks can't learn without
+	// biases but trees split on directly
+	samples := func(n int, seed int64) ([][]float64, []float64) {
+		r := rand.New(rand.NewSource(seed))
+		features := make([][]float64, n)
+		labels := make([]float64, n)
+		for i := range features {
+			features[i] = []float64{r.Float64()*2 - 1, r.Float64()*2 - 1, r.Float64()*2 - 1}
+			switch {
+			case features[i][0] > 0.3 && features[i][1] > 0:
+				labels[i] = float64(model.StrategyLong)
+			case features[i][0] < -0.3:
+				labels[i] = float64(model.StrategyShort)
+			default:
+				labels[i] = float64(model.StrategyHold)
+			}
+		}
+		return features, labels
+	}
+
+	features, labels := samples(3000, 1)
+	weights, err := model.Train(progress.NewWriter(), params, features, labels, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test, testLabels := samples(1000, 2)
+	correct := 0
+	for i, sample := range test {
+		pred, err := model.Predict(params, weights, sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		best := 0
+		for c := range pred {
+			if pred[c] > pred[best] {
+				best = c
+			}
+		}
+		if best == int(testLabels[i]) {
+			correct++
+		}
+	}
+	if accuracy := float64(correct) / float64(len(test)); accuracy < 0.95 {
+		t.Errorf("accuracy %.2f, expected the trees to learn the thresholds", accuracy)
+	}
+}
+
+// A batch must give exactly what its samples give one at a time, so
+// backtests see the same predictions as serving
+func TestPredictBatch(t *testing.T) {
+	for _, architecture := range model.Architectures {
+		params := model.NewModelParamsFromDefaults()
+		params.Architecture = architecture
+		params.SequenceLength = 8
+		params.HiddenLayerSize = 128
+		params.BatchSize = 32
+		params.GBDTTrees = 5
+
+		// a sample is 8 features for the MLP and trees, or 8 rows of 1
+		// feature for the sequence models
+		features, labels := sequenceSamples(500, params.SequenceLength, 1, 1)
+		weights, err := model.Train(progress.NewWriter(), params, features, labels, 1)
+		if err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+
+		test, _ := sequenceSamples(300, params.SequenceLength, 1, 2)
+		predictor := model.NewPredictor(params, weights)
+		batch, err := predictor.PredictBatch(test)
+		if err != nil {
+			t.Fatalf("%s: %v", architecture, err)
+		}
+		for i, sample := range test {
+			pred, err := predictor.Predict(sample)
+			if err != nil {
+				t.Fatalf("%s: %v", architecture, err)
+			}
+			for c := range pred {
+				if pred[c] != batch[i][c] {
+					t.Fatalf("%s: sample %d class %d predicted %v alone, %v in a batch", architecture, i, c, pred[c], batch[i][c])
+				}
+			}
+		}
+	}
+}
+
+func TestMLPTopology(t *testing.T) {
+	for _, activation := range model.Activations {
+		for _, normalization := range model.Normalizations {
+			params := model.NewModelParamsFromDefaults()
+			params.SequenceLength = 8
+			params.HiddenLayers = 2
+			params.HiddenLayerSize = 16
+			params.HiddenLayerDecay = 1
+			params.Activation = activation
+			params.Normalization = normalization
+			params.Residual = true
+			params.BatchSize = 32
+			params.LearnRate = 0.01
+			params.DropoutRate = 0
+			params.L2Penalty = 0
+
+			// the residual connections pass the sign through to the output
+			features, labels := sequenceSamples(2000, params.SequenceLength, 1, 1)
+			weights, err := model.Train(progress.NewWriter(), params, features, labels, 20)
+			if err != nil {
+				t.Fatalf("%s %s: %v", activation, normalization, err)
+			}
+
+			test, testLabels := sequenceSamples(500, params.SequenceLength, 1, 2)
+			preds, err := model.NewPredictor(params, weights).PredictBatch(test)
+			if err != nil {
+				t.Fatalf("%s %s: %v", activation, normalization, err)
+			}
+			correct := 0
+			for i, pred := range preds {
+				best := 0
+				for c := range pred {
+					if pred[c] > pred[best] {
+						best = c
+					}
+				}
+				if best == int(testLabels[i]) {
+					correct++
+				}
+			}
+			if accuracy := float64(correct) / float64(len(test)); accuracy < 0.85 {
+				t.Errorf("%s %s: accuracy %.2f, expected the model to learn the samples", activation, normalization, accuracy)
+			}
+		}
+	}
+
+	params := model.NewModelParamsFromDefaults()
+	params.HiddenLayers = 0
+	features, labels := imbalancedSamples(200, 1)
+	if _, err := model.Train(progress.NewWriter(), params, features, labels, 1); err == nil {
+		t.Error("trained an MLP without hidden layers")
+	}
+}
+
+// imbalancedSamples labels a few samples at the extremes of the first
+// feature LONG and SHORT and the rest HOLD. The last feature is constant,
+// standing in for the biases the networks don't have.
+func imbalancedSamples(n int, seed int64) ([][]float64, []float64) {
+	r := rand.New(rand.NewSource(seed))
+	features := make([][]float64, n)
+	labels := make([]float64, n)
+	for i := range features {
+		features[i] = []float64{r.Float64()*2 - 1, r.Float64()*2 - 1, 1}
+		switch {
+		case features[i][0] > 0.8:
+			labels[i] = float64(model.StrategyLong)
+		case features[i][0] < -0.9:
+			labels[i] = float64(model.StrategyShort)
+		default:
+			labels[i] = float64(model.StrategyHold)
+		}
+	}
+	return features, labels
+}
+
+func TestRebalance(t *testing.T) {
+	features, labels := imbalancedSamples(2000, 1)
+
+	for _, strategy := range model.Imbalances {
+		params := model.NewModelParamsFromDefaults()
+		params.ClassImbalance = strategy
+		balanced, balancedLabels := model.Rebalance(params, features, labels)
+
+		counts := make([]int, 3)
+		for _, label := range balancedLabels {
+			counts[int(label)]++
+		}
+		switch strategy {
+		case model.ImbalanceNone, model.ImbalanceWeighted, model.ImbalanceFocal:
+			if len(balanced) != len(features) {
+				t.Errorf("%s: %d samples, expected the %d samples as they are", strategy, len(balanced), len(features))
+			}
+			continue
+		}
+		if counts[1] != counts[0] || counts[2] != counts[0] {
+			t.Errorf("%s: class counts %v, expected them equal", strategy, counts)
+		}
+		for i := range features {
+			if &balanced[i][0] != &features[i][0] || balancedLabels[i] != labels[i] {
+				t.Fatalf("%s: sample %d changed, expected the originals first", strategy, i)
+			}
+		}
+
+		if strategy == model.ImbalanceOversample {
+			continue
+		}
+		// interpolated samples stay within their class
+		for i := len(features); i < len(balanced); i++ {
+			x := balanced[i][0]
+			switch model.Strategy(balancedLabels[i]) {
+			case model.StrategyLong:
+				if x <= 0.8 {
+					t.Fatalf("%s: synthetic LONG sample at %v", strategy, x)
+				}
+			case model.StrategyShort:
+				if x >= -0.9 {
+					t.Fatalf("%s: synthetic SHORT sample at %v", strategy, x)
+				}
+			}
+		}
+	}
+}
+
+// The loss strategies should learn the rare classes without resampling, and
+// SMOTE having resampled only the training samples
+func TestImbalanceStrategies(t *testing.T) {
+	features, labels := imbalancedSamples(4000, 1)
+	test, testLabels := imbalancedSamples(2000, 2)
+
+	for _, architecture := range []model.ModelArchitecture{model.ArchitectureMLP, model.ArchitectureGBDT} {
+		for _, strategy := range []model.ImbalanceStrategy{model.ImbalanceWeighted, model.ImbalanceFocal, model.ImbalanceSMOTE} {
+			params := model.NewModelParamsFromDefaults()
+			params.Architecture = architecture
+			params.ClassImbalance = strategy
+			params.HiddenLayers = 2
+			params.HiddenLayerSize = 16
+			params.BatchSize = 32
+			params.LearnRate = 0.01
+			params.DropoutRate = 0
+			params.L2Penalty = 0
+			params.GBDTTrees = 50
+
+			weights, err := model.Train(progress.NewWriter(), params, features, labels, 20)
+			if err != nil {
+				t.Fatalf("%s %s: %v", architecture, strategy, err)
+			}
+			preds, err := model.NewPredictor(params, weights).PredictBatch(test)
+			if err != nil {
+				t.Fatalf("%s %s: %v", architecture, strategy, err)
+			}
+
+			found, total := make([]int, 3), make([]int, 3)
+			for i, pred := range preds {
+				best := 0
+				for c := range pred {
+					if pred[c] > pred[best] {
+						best = c
+					}
+				}
+				total[int(testLabels[i])]++
+				if best == int(testLabels[i]) {
+					found[best]++
+				}
+			}
+			for class, name := range map[model.Strategy]string{model.StrategyLong: "LONG", model.StrategyShort: "SHORT"} {
+				recall := float64(found[int(class)]) / float64(total[int(class)])
+				t.Logf("%s %s: %s recall %.2f", architecture, strategy, name, recall)
+				if recall < 0.7 {
+					t.Errorf("%s %s: %s recall %.2f, expected the model to learn the rare class", architecture, strategy, name, recall)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"math"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+// Optional trend feature groups, each enabled through its own
+// SIGNALS_*_ENABLED flag
+
+var adxFeatures = featureSpec{
+	name:    "adx",
+	enabled: func(p ModelParams) bool { return p.ADXEnabled },
+	warmup:  func(p ModelParams) int { return 30 * p.ADXPeriod },
+	compute: func(in *featureInput) []featureSeries {
+		adx, plusDI, minusDI := ta.ADX(in.highs, in.lows, in.closes, in.params.ADXPeriod)
+		return []featureSeries{
+			scaled("adx", adx, 100),
+			scaled("plus_di", plusDI, 100),
+			scaled("minus_di", minusDI, 100),
+		}
+	},
+}
+
+// The parabolic SAR resets at every reversal, after which it no longer
+// depends on where the candles started
+var parabolicSARFeatures = featureSpec{
+	name:    "parabolic_sar",
+	enabled: func(p ModelParams) bool { return p.ParabolicSAREnabled },
+	warmup:  func(p ModelParams) int { return int(10 / p.ParabolicSARStep) },
+	compute: func(in *featureInput) []featureSeries {
+		sar, trend := ta.ParabolicSAR(in.highs, in.lows, in.params.ParabolicSARStep, in.params.ParabolicSARMaxStep)
+		return []featureSeries{
+			windowed("distance", in.series(func(i int) float64 {
+				return relativeDistance(in.closes[i], sar[i])
+			})),
+			raw("trend", in.series(func(i int) float64 {
+				return (trend[i] + 1) / 2
+			})),
+		}
+	},
+}
+
+var ichimokuFeatures = featureSpec{
+	name:    "ichimoku",
+	enabled: func(p ModelParams) bool { return p.IchimokuEnabled },
+	warmup: func(p ModelParams) int {
+		return max(p.IchimokuConversionPeriod, p.IchimokuBasePeriod, p.IchimokuSpanPeriod) + p.IchimokuBasePeriod
+	},
+	compute: func(in *featureInput) []featureSeries {
+		p := in.params
+		tenkan, kijun, spanA, spanB := ta.Ichimoku(in.highs, in.lows, p.IchimokuConversionPeriod, p.IchimokuBasePeriod, p.IchimokuSpanPeriod)
+		return []featureSeries{
+			windowed("conversion", tenkan),
+			windowed("base", kijun),
+			windowed("span_a", spanA),
+			windowed("span_b", spanB),
+			raw("above_cloud", in.series(func(i int) float64 {
+				return boolToFloat(math.Min(spanA[i], spanB[i]) > 0 && in.closes[i] > math.Max(spanA[i], spanB[i]))
+			})),
+			raw("below_cloud", in.series(func(i int) float64 {
+				return boolToFloat(math.Min(spanA[i], spanB[i]) > 0 && in.closes[i] < math.Min(spanA[i], spanB[i]))
+			})),
+		}
+	},
+}
+
+var superTrendFeatures = featureSpec{
+	name:    "supertrend",
+	enabled: func(p ModelParams) bool { return p.SuperTrendEnabled },
+	warmup:  func(p ModelParams) int { return 30 * p.SuperTrendPeriod },
+	compute: func(in *featureInput) []featureSeries {
+		supertrend, direction := ta.SuperTrend(in.highs, in.lows, in.closes, in.params.SuperTrendPeriod, in.params.SuperTrendMultiplier)
+		return []featureSeries{
+			windowed("line", supertrend),
+			raw("direction", in.series(func(i int) float64 {
+				return (direction[i] + 1) / 2
+			})),
+		}
+	},
+}
+
+var keltnerChannelsFeatures = featureSpec{
+	name:    "keltner",
+	enabled: func(p ModelParams) bool { return p.KeltnerChannelsEnabled },
+	warmup:  func(p ModelParams) int { return 30 * p.KeltnerChannelsPeriod },
+	compute: func(in *featureInput) []featureSeries {
+		middle, upper, lower := ta.KeltnerChannels(in.highs, in.lows, in.closes, in.params.KeltnerChannelsPeriod, in.params.KeltnerChannelsMultiplier)
+		return []featureSeries{
+			windowed("middle", middle),
+			windowed("upper", upper),
+			windowed("lower", lower),
+			raw("position", in.series(func(i int) float64 {
+				return channelPosition(in.closes[i], lower[i], upper[i])
+			})),
+		}
+	},
+}
+
+var donchianChannelsFeatures = featureSpec{
+	name:    "donchian",
+	enabled: func(p ModelParams) bool { return p.DonchianChannelsEnabled },
+	warmup:  func(p ModelParams) int { return p.DonchianChannelsPeriod },
+	compute: func(in *featureInput) []featureSeries {
+		upper, _, lower := ta.DonchianChannels(in.highs, in.lows, in.params.DonchianChannelsPeriod)
+		return []featureSeries{
+			windowed("width", in.series(func(i int) float64 {
+				return relativeDistance(upper[i], lower[i])
+			})),
+			raw("position", in.series(func(i int) float64 {
+				return channelPosition(in.closes[i], lower[i], upper[i])
+			})),
+		}
+	},
+}
+
+// relativeDistance returns (a-b)/a, or zero while either value is still
+// warming up
+func relativeDistance(a, b float64) float64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return (a - b) / a
+}
+
+// channelPosition returns where value sits between lower and upper, clamped
+// to 0-1 and 0.5 for an empty channel
+func channelPosition(value, lower, upper float64) float64 {
+	if upper <= lower {
+		return 0.5
+	}
+	return math.Max(0, math.Min(1, (value-lower)/(upper-lower)))
+}
@@ -0,0 +1,305 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/grexie/signals/pkg/model"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// ErrNotFound is returned for IDs that aren't in the registry
+var ErrNotFound = errors.New("model not found")
+
+// Entry describes a model in the registry
+type Entry struct {
+	ID         string
+	Created    time.Time
+	Instrument string
+	Params     model.ModelParams
+	Metrics    model.ModelMetrics
+	From       time.Time
+	To         time.Time
+	Tags       []string
+}
+
+// Promotion is the set of models live trading runs as an ensemble
+type Promotion struct {
+	IDs     []string
+	Created time.Time
+}
+
+// Registry stores models in a directory, as a model file and an entry per
+// model, with the promotion history of each instrument in promotions.json.
+// It lives on disk rather than in leveldb, which only one process can
+// open, so models can be promoted while live trading is running.
+type Registry struct {
+	dir string
+}
+
+func Open(dir string) (*Registry, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Registry{dir: dir}, nil
+}
+
+func (r *Registry) modelFile(id string) string {
+	return filepath.Join(r.dir, id+".bin")
+}
+
+func (r *Registry) entryFile(id string) string {
+	return filepath.Join(r.dir, id+".entry")
+}
+
+func (r *Registry) promotionsFile() string {
+	return filepath.Join(r.dir, "promotions.json")
+}
+
+// writeFile replaces filename only once data is completely written
+func writeFile(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+func newID(created time.Time) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return created.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// Add stores the model with the given tags and returns its entry
+func (r *Registry) Add(m *model.Model, tags []string) (Entry, error) {
+	created := time.Now()
+	entry := Entry{
+		ID:         newID(created),
+		Created:    created,
+		Instrument: m.Instrument,
+		Params:     m.Params(),
+		Metrics:    m.Metrics,
+		From:       m.From,
+		To:         m.To,
+		Tags:       tags,
+	}
+
+	if err := m.SaveFile(r.modelFile(entry.ID)); err != nil {
+		return Entry{}, err
+	}
+
+	// metrics can be NaN, which JSON can't represent
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return Entry{}, fmt.Errorf("failed to encode entry: %w", err)
+	}
+	if err := writeFile(r.entryFile(entry.ID), buf.Bytes()); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// List returns every entry, oldest first
+func (r *Registry) List() ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(r.dir, "*.entry"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for _, file := range files {
+		entry, err := r.readEntry(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return a.Created.Compare(b.Created)
+	})
+	return entries, nil
+}
+
+func (r *Registry) readEntry(filename string) (Entry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer f.Close()
+
+	var entry Entry
+	if err := gob.NewDecoder(f).Decode(&entry); err != nil {
+		return Entry{}, fmt.Errorf("failed to decode %s: %w", filename, err)
+	}
+	return entry, nil
+}
+
+// Get returns the entry with the given ID, or the only entry whose ID
+// starts with it
+func (r *Registry) Get(id string) (Entry, error) {
+	if entry, err := r.readEntry(r.entryFile(id)); err == nil {
+		return entry, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return Entry{}, err
+	}
+
+	entries, err := r.List()
+	if err != nil {
+		return Entry{}, err
+	}
+	matches := []Entry{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.ID, id) {
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	case 1:
+		return matches[0], nil
+	}
+	return Entry{}, fmt.Errorf("ambiguous model ID %s matches %d models", id, len(matches))
+}
+
+// Load loads the model with the given ID
+func (r *Registry) Load(db *leveldb.DB, id string) (*model.Model, error) {
+	entry, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return model.LoadFile(db, r.modelFile(entry.ID))
+}
+
+// lockPromotions takes an exclusive lock on the promotions, held until the
+// returned func is called, so promotions and rollbacks in other processes
+// don't overwrite each other. promotions.json itself is replaced on every
+// write, so the lock is on a file alongside it.
+func (r *Registry) lockPromotions() (func(), error) {
+	f, err := os.OpenFile(filepath.Join(r.dir, "promotions.lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock promotions: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+func (r *Registry) promotions() (map[string][]Promotion, error) {
+	promotions := map[string][]Promotion{}
+	data, err := os.ReadFile(r.promotionsFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return promotions, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &promotions); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", r.promotionsFile(), err)
+	}
+	return promotions, nil
+}
+
+func (r *Registry) writePromotions(promotions map[string][]Promotion) error {
+	data, err := json.MarshalIndent(promotions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(r.promotionsFile(), data)
+}
+
+// Promote makes the models with the given IDs the ensemble live trading
+// runs for their instrument
+func (r *Registry) Promote(ids []string) (Promotion, error) {
+	if len(ids) == 0 {
+		return Promotion{}, fmt.Errorf("no models to promote")
+	}
+
+	promotion := Promotion{Created: time.Now()}
+	instrument := ""
+	for _, id := range ids {
+		entry, err := r.Get(id)
+		if err != nil {
+			return Promotion{}, err
+		}
+		if instrument != "" && entry.Instrument != instrument {
+			return Promotion{}, fmt.Errorf("can't promote models of %s and %s together", instrument, entry.Instrument)
+		}
+		instrument = entry.Instrument
+		promotion.IDs = append(promotion.IDs, entry.ID)
+	}
+
+	unlock, err := r.lockPromotions()
+	if err != nil {
+		return Promotion{}, err
+	}
+	defer unlock()
+
+	promotions, err := r.promotions()
+	if err != nil {
+		return Promotion{}, err
+	}
+	promotions[instrument] = append(promotions[instrument], promotion)
+	return promotion, r.writePromotions(promotions)
+}
+
+// Rollback reverts the instrument to the models promoted before the
+// current ones and returns that promotion
+func (r *Registry) Rollback(instrument string) (Promotion, error) {
+	unlock, err := r.lockPromotions()
+	if err != nil {
+		return Promotion{}, err
+	}
+	defer unlock()
+
+	promotions, err := r.promotions()
+	if err != nil {
+		return Promotion{}, err
+	}
+
+	history := promotions[instrument]
+	if len(history) < 2 {
+		return Promotion{}, fmt.Errorf("no earlier promotion of %s to roll back to", instrument)
+	}
+	promotions[instrument] = history[:len(history)-1]
+	return history[len(history)-2], r.writePromotions(promotions)
+}
+
+// Promoted returns the models promoted for the instrument, if any
+func (r *Registry) Promoted(instrument string) (Promotion, bool, error) {
+	promotions, err := r.promotions()
+	if err != nil {
+		return Promotion{}, false, err
+	}
+	history := promotions[instrument]
+	if len(history) == 0 {
+		return Promotion{}, false, nil
+	}
+	return history[len(history)-1], true, nil
+}
@@ -0,0 +1,196 @@
+package registry_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grexie/signals/pkg/model"
+	"github.com/grexie/signals/pkg/registry"
+	"github.com/jedib0t/go-pretty/v6/progress"
+)
+
+const testInstrument = "DOGE-USDT-SWAP"
+
+// testModel trains a small model on random samples, as the registry only
+// stores models rather than predicting with them
+func testModel(t *testing.T) *model.Model {
+	t.Helper()
+	params := model.NewModelParamsFromDefaults()
+	params.HiddenLayerSize = 8
+	params.BatchSize = 32
+
+	r := rand.New(rand.NewSource(1))
+	features := make([][]float64, 200)
+	labels := make([]float64, len(features))
+	for i := range features {
+		features[i] = make([]float64, len(model.FeatureNames(params)))
+		for j := range features[i] {
+			features[i][j] = r.Float64()
+		}
+		labels[i] = float64(r.Intn(3))
+	}
+
+	weights, err := model.Train(progress.NewWriter(), params, features, labels, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := model.NewModelFromWeights(nil, testInstrument, params, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestRegistry(t *testing.T) {
+	r, err := registry.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := testModel(t)
+
+	first, err := r.Add(m, []string{"first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.Add(m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, err := r.List(); err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 2 || entries[0].ID != first.ID || entries[1].ID != second.ID {
+		t.Fatalf("listed %v, expected %s then %s", entries, first.ID, second.ID)
+	}
+
+	if entry, err := r.Get(first.ID); err != nil || entry.ID != first.ID || !slices.Equal(entry.Tags, []string{"first"}) {
+		t.Fatalf("got %+v %v for %s", entry, err, first.ID)
+	}
+	if entry, err := r.Get(second.ID[:len(second.ID)-1]); err != nil || entry.ID != second.ID {
+		t.Fatalf("got %+v %v for a prefix of %s", entry, err, second.ID)
+	}
+	if entry, err := r.Get("nosuch"); !errors.Is(err, registry.ErrNotFound) {
+		t.Fatalf("got %+v %v, expected not found", entry, err)
+	}
+	common := 0
+	for common < len(first.ID) && first.ID[common] == second.ID[common] {
+		common++
+	}
+	if _, err := r.Get(first.ID[:common]); err == nil || errors.Is(err, registry.ErrNotFound) || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("got %v for the prefix of both models", err)
+	}
+
+	loaded, err := r.Load(nil, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Instrument != testInstrument || loaded.Params().Architecture != m.Params().Architecture {
+		t.Fatalf("loaded a model of %s %s", loaded.Instrument, loaded.Params().Architecture)
+	}
+
+	if _, ok, err := r.Promoted(testInstrument); err != nil || ok {
+		t.Fatalf("promoted %v %v before any promotion", ok, err)
+	}
+	if _, err := r.Rollback(testInstrument); err == nil {
+		t.Fatal("rolled back without a promotion")
+	}
+
+	if _, err := r.Promote([]string{first.ID}); err != nil {
+		t.Fatal(err)
+	}
+	promotion, err := r.Promote([]string{first.ID, second.ID[:len(second.ID)-1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(promotion.IDs, []string{first.ID, second.ID}) {
+		t.Fatalf("promoted %v, expected the full IDs", promotion.IDs)
+	}
+	if promoted, ok, err := r.Promoted(testInstrument); err != nil || !ok || !slices.Equal(promoted.IDs, promotion.IDs) {
+		t.Fatalf("promoted %v %v %v, expected %v", promoted.IDs, ok, err, promotion.IDs)
+	}
+	if _, ok, _ := r.Promoted("BTC-USDT-SWAP"); ok {
+		t.Fatal("promoted models of another instrument")
+	}
+
+	if previous, err := r.Rollback(testInstrument); err != nil || !slices.Equal(previous.IDs, []string{first.ID}) {
+		t.Fatalf("rolled back to %v %v, expected %s", previous.IDs, err, first.ID)
+	}
+	if promoted, _, _ := r.Promoted(testInstrument); !slices.Equal(promoted.IDs, []string{first.ID}) {
+		t.Fatalf("promoted %v after rolling back", promoted.IDs)
+	}
+	if _, err := r.Rollback(testInstrument); err == nil {
+		t.Fatal("rolled back past the first promotion")
+	}
+	if _, err := r.Promote(nil); err == nil {
+		t.Fatal("promoted no models")
+	}
+}
+
+// Promotions from separate registries on the same directory, as from
+// separate processes, all keep their place in the history
+func TestConcurrentPromotions(t *testing.T) {
+	dir := t.TempDir()
+	r, err := registry.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := r.Add(testModel(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 50
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := registry.Open(dir)
+			if err == nil {
+				<-start
+				_, err = r.Promote([]string{entry.ID})
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	for i := range count - 1 {
+		if _, err := r.Rollback(testInstrument); err != nil {
+			t.Fatalf("rollback %d: %v, expected %d promotions", i+1, err, count)
+		}
+	}
+	if _, err := r.Rollback(testInstrument); err == nil {
+		t.Fatalf("rolled back more than %d promotions", count)
+	}
+}
+
+// Entries write their params and trade table to the writer rather than
+// stdout
+func TestWriteParams(t *testing.T) {
+	r, err := registry.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := r.Add(testModel(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	entry.Params.Write(&buf, "Model Config", true)
+	for _, want := range []string{"SIGNALS_ARCHITECTURE=", "Trade Info", "Take Profit"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("params don't contain %q", want)
+		}
+	}
+}
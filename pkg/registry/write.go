@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// WriteList writes a table of entries, marking the promoted models
+func WriteList(w io.Writer, entries []Entry, promoted []string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Models")
+	t.AppendHeader(table.Row{"", "ID", "CREATED", "INSTRUMENT", "PNL", "SHARPE", "SORTINO", "FITNESS", "TAGS"})
+	for _, entry := range entries {
+		marker := ""
+		if slices.Contains(promoted, entry.ID) {
+			marker = "*"
+		}
+		t.AppendRow(table.Row{
+			marker,
+			entry.ID,
+			entry.Created.Local().Format(time.DateTime),
+			entry.Instrument,
+			fmt.Sprintf("%6.2f%%", entry.Metrics.Backtest.Mean.PnL),
+			fmt.Sprintf("%6.2f", entry.Metrics.Backtest.Mean.SharpeRatio),
+			fmt.Sprintf("%6.2f", entry.Metrics.Backtest.Mean.SortinoRatio),
+			fmt.Sprintf("%.6f", entry.Metrics.Fitness()),
+			strings.Join(entry.Tags, ","),
+		})
+	}
+	t.Render()
+}
+
+// WriteEntry writes an entry's details, params and metrics
+func WriteEntry(w io.Writer, entry Entry) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Model")
+	t.AppendRows([]table.Row{
+		{"ID", entry.ID},
+		{"Created", entry.Created.Local().Format(time.DateTime)},
+		{"Instrument", entry.Instrument},
+		{"Trained", fmt.Sprintf("%s to %s", entry.From.Local().Format(time.DateTime), entry.To.Local().Format(time.DateTime))},
+		{"Tags", strings.Join(entry.Tags, ",")},
+	})
+	t.Render()
+	fmt.Fprintln(w)
+
+	entry.Params.Write(w, "Model Config", true)
+	fmt.Fprintln(w)
+	entry.Metrics.Write(w)
+}
+
+// paramLines returns the params as written by ModelParams.Write, keyed by
+// their environment variable
+func paramLines(entry Entry) (keys []string, values map[string]string) {
+	var buf bytes.Buffer
+	entry.Params.Write(&buf, "", false)
+
+	values = map[string]string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			keys = append(keys, key)
+			values[key] = value
+		}
+	}
+	return keys, values
+}
+
+// WriteDiff writes the params that differ between two entries and their
+// backtest metrics side by side
+func WriteDiff(w io.Writer, a, b Entry) {
+	keys, aValues := paramLines(a)
+	bKeys, bValues := paramLines(b)
+	for _, key := range bKeys {
+		if _, ok := aValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Params")
+	t.AppendHeader(table.Row{"", a.ID, b.ID})
+	for _, key := range keys {
+		if aValues[key] != bValues[key] {
+			t.AppendRow(table.Row{key, aValues[key], bValues[key]})
+		}
+	}
+	t.Render()
+
+	am, bm := a.Metrics, b.Metrics
+	t = table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Metrics")
+	t.AppendHeader(table.Row{"", a.ID, b.ID})
+	t.AppendRows([]table.Row{
+		{"Accuracy", fmt.Sprintf("%6.2f%%", am.Accuracy), fmt.Sprintf("%6.2f%%", bm.Accuracy)},
+		{"PnL", fmt.Sprintf("%6.2f%%", am.Backtest.Mean.PnL), fmt.Sprintf("%6.2f%%", bm.Backtest.Mean.PnL)},
+		{"Max Drawdown", fmt.Sprintf("%6.2f%%", am.Backtest.Mean.MaxDrawdown), fmt.Sprintf("%6.2f%%", bm.Backtest.Mean.MaxDrawdown)},
+		{"Sharpe Ratio", fmt.Sprintf("%6.2f", am.Backtest.Mean.SharpeRatio), fmt.Sprintf("%6.2f", bm.Backtest.Mean.SharpeRatio)},
+		{"Sortino Ratio", fmt.Sprintf("%6.2f", am.Backtest.Mean.SortinoRatio), fmt.Sprintf("%6.2f", bm.Backtest.Mean.SortinoRatio)},
+		{"Trades", fmt.Sprintf("%6.2f", am.Backtest.Mean.Trades), fmt.Sprintf("%6.2f", bm.Backtest.Mean.Trades)},
+	})
+	t.AppendSeparator()
+	t.AppendRow(table.Row{"Fitness", fmt.Sprintf("%.6f", am.Fitness()), fmt.Sprintf("%.6f", bm.Fitness())})
+	t.Render()
+}
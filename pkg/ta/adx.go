@@ -0,0 +1,41 @@
+package ta
+
+import "math"
+
+// ADX calculates Wilder's average directional index together with the
+// positive and negative directional indicators
+func ADX(highs, lows, closes []float64, period int) ([]float64, []float64, []float64) {
+	tr := make([]float64, len(closes))
+	plusDM, minusDM := make([]float64, len(closes)), make([]float64, len(closes))
+
+	for i := 1; i < len(closes); i++ {
+		up := highs[i] - highs[i-1]
+		down := lows[i-1] - lows[i]
+		if up > down && up > 0 {
+			plusDM[i] = up
+		}
+		if down > up && down > 0 {
+			minusDM[i] = down
+		}
+		tr[i] = math.Max(highs[i]-lows[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+	}
+
+	smoothedTR, first := smooth(tr, period, SmoothingWilder, 1)
+	smoothedPlusDM, _ := smooth(plusDM, period, SmoothingWilder, 1)
+	smoothedMinusDM, _ := smooth(minusDM, period, SmoothingWilder, 1)
+
+	plusDI, minusDI := make([]float64, len(closes)), make([]float64, len(closes))
+	dx := make([]float64, len(closes))
+	for i := first; i < len(closes); i++ {
+		if smoothedTR[i] != 0 {
+			plusDI[i] = 100 * smoothedPlusDM[i] / smoothedTR[i]
+			minusDI[i] = 100 * smoothedMinusDM[i] / smoothedTR[i]
+		}
+		if sum := plusDI[i] + minusDI[i]; sum != 0 {
+			dx[i] = 100 * math.Abs(plusDI[i]-minusDI[i]) / sum
+		}
+	}
+
+	adx, _ := smooth(dx, period, SmoothingWilder, first)
+	return adx, plusDI, minusDI
+}
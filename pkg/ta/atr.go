@@ -2,8 +2,8 @@ package ta
 
 import "math"
 
-// ATR calculation
-func ATR(highs, lows, closes []float64, period int) []float64 {
+// ATR calculation, SmoothingWilder gives Wilder's original ATR
+func ATR(highs, lows, closes []float64, period int, smoothing Smoothing) []float64 {
 	tr := make([]float64, len(highs))
 
 	// Calculate True Range
@@ -20,5 +20,26 @@ func ATR(highs, lows, closes []float64, period int) []float64 {
 	}
 
 	// Calculate Average True Range
-	return MovingAverage(tr, period)
+	return Smooth(tr, period, smoothing)
+}
+
+// ATRStream is the streaming equivalent of ATR
+type ATRStream struct {
+	n         int
+	prevClose float64
+	ma        Smoother
+}
+
+func NewATR(period int, smoothing Smoothing) *ATRStream {
+	return &ATRStream{ma: NewSmoother(period, smoothing)}
+}
+
+func (s *ATRStream) Update(c Candle) float64 {
+	tr := c.High - c.Low
+	if s.n > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-s.prevClose), math.Abs(c.Low-s.prevClose)))
+	}
+	s.n++
+	s.prevClose = c.Close
+	return s.ma.Update(tr)
 }
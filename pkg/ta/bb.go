@@ -2,22 +2,54 @@ package ta
 
 import "math"
 
-func BollingerBands(prices []float64, window int, multiplier float64) ([]float64, []float64, []float64) {
-	ma := MovingAverage(prices, window)
+// BollingerBands returns the middle, upper and lower bands. The middle band
+// uses the given smoothing while the band width is always the population
+// standard deviation of the window, as charting platforms do.
+func BollingerBands(prices []float64, window int, multiplier float64, smoothing Smoothing) ([]float64, []float64, []float64) {
+	ma, first := smooth(prices, window, smoothing, 0)
 	upper, lower := make([]float64, len(prices)), make([]float64, len(prices))
+	first = max(first, window)
 
-	for i := range prices {
-		if i < window {
-			upper[i], lower[i] = 0, 0
+	moments := newRollingMoments(window)
+	for i, price := range prices {
+		moments.Push(price)
+		if i < first {
 			continue
 		}
-		sum := 0.0
-		for j := i - window + 1; j <= i; j++ {
-			sum += math.Pow(prices[j]-ma[i], 2)
-		}
-		stdDev := math.Sqrt(sum / float64(window))
+		stdDev := math.Sqrt(moments.Variance())
 		upper[i] = ma[i] + multiplier*stdDev
 		lower[i] = ma[i] - multiplier*stdDev
 	}
 	return ma, upper, lower
 }
+
+// BollingerBandsStream is the streaming equivalent of BollingerBands
+type BollingerBandsStream struct {
+	first      int
+	multiplier float64
+	n          int
+	ma         Smoother
+	moments    *rollingMoments
+}
+
+func NewBollingerBands(window int, multiplier float64, smoothing Smoothing) *BollingerBandsStream {
+	return &BollingerBandsStream{
+		first:      max(smoothingWarmup(window, smoothing, 0), window),
+		multiplier: multiplier,
+		ma:         NewSmoother(window, smoothing),
+		moments:    newRollingMoments(window),
+	}
+}
+
+// Update returns the middle, upper and lower bands
+func (s *BollingerBandsStream) Update(c Candle) (float64, float64, float64) {
+	ma := s.ma.Update(c.Close)
+	s.moments.Push(c.Close)
+	i := s.n
+	s.n++
+	if i < s.first {
+		return ma, 0, 0
+	}
+	stdDev := math.Sqrt(s.moments.Variance())
+	return ma, ma + s.multiplier*stdDev, ma - s.multiplier*stdDev
+}
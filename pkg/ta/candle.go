@@ -0,0 +1,5 @@
+package ta
+
+import "github.com/grexie/signals/pkg/candles"
+
+type Candle = candles.Candle
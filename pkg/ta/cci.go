@@ -25,44 +25,71 @@ func CCI(highs, lows, closes []float64, period int) []float64 {
 		return cci
 	}
 
-	rollingSumTP := 0.0
-	rollingSumDev := 0.0
-	tpBuffer := make([]float64, 0, period)
-
-	// Precompute first rolling sum for Typical Price (TP)
-	for i := 0; i < period; i++ {
-		tp := (highs[i] + lows[i] + closes[i]) / 3
-		rollingSumTP += tp
-		tpBuffer = append(tpBuffer, tp)
+	tps := make([]float64, len(closes))
+	for i := range closes {
+		tps[i] = (highs[i] + lows[i] + closes[i]) / 3
 	}
 
-	for i := period; i < len(closes); i++ {
-		// Compute new Typical Price (TP)
-		tp := (highs[i] + lows[i] + closes[i]) / 3
-		tpBuffer = append(tpBuffer, tp)
-		rollingSumTP += tp
-
-		// Remove oldest TP from rolling sum
-		if len(tpBuffer) > period {
-			rollingSumTP -= tpBuffer[0]
-			tpBuffer = tpBuffer[1:]
+	// The mean deviation splits the window at its mean: values below it
+	// contribute mean-v and values above v-mean, so ranked sums of the window
+	// give it in O(log n) rather than a pass over the window.
+	window := newRankedSums(tps, period)
+	for i, tp := range tps {
+		window.Add(tp)
+		if i >= period {
+			window.Remove(tps[i-period])
+		}
+		if i < period {
+			continue
 		}
 
-		// Calculate Mean TP
-		meanTP := rollingSumTP / float64(period)
-
-		// Compute Mean Deviation efficiently
-		rollingSumDev = 0
-		for _, val := range tpBuffer {
-			rollingSumDev += math.Abs(val - meanTP)
+		n := float64(period)
+		sum := window.Sum()
+		if math.IsNaN(sum) {
+			cci[i] = math.NaN()
+			continue
 		}
-		meanDeviation := rollingSumDev / float64(period)
+		meanTP := sum / n
+		count, below := window.Below(meanTP)
+		meanDeviation := (meanTP*float64(count) - below + (sum - below) - meanTP*(n-float64(count))) / n
 
-		// Compute CCI
-		if meanDeviation != 0 {
+		if meanDeviation > 0 {
 			cci[i] = (tp - meanTP) / (0.015 * meanDeviation)
 		}
 	}
 
 	return cci
 }
+
+// CCIStream is the streaming equivalent of CCI. The rolling mean is O(1) per
+// candle, the mean deviation is inherently O(period).
+type CCIStream struct {
+	period int
+	n      int
+	tp     *rollingSum
+}
+
+func NewCCI(period int) *CCIStream {
+	return &CCIStream{period: period, tp: newRollingSum(period)}
+}
+
+func (s *CCIStream) Update(c Candle) float64 {
+	tp := (c.High + c.Low + c.Close) / 3
+	s.tp.Push(tp)
+	i := s.n
+	s.n++
+	if i < s.period {
+		return 0
+	}
+
+	meanTP := s.tp.Sum() / float64(s.period)
+	sumDev := 0.0
+	for j := range s.tp.ring.Len() {
+		sumDev += math.Abs(s.tp.ring.At(j) - meanTP)
+	}
+	meanDeviation := sumDev / float64(s.period)
+	if meanDeviation == 0 {
+		return 0
+	}
+	return (tp - meanTP) / (0.015 * meanDeviation)
+}
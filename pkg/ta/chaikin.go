@@ -2,38 +2,80 @@ package ta
 
 func ChaikinMoneyFlow(highs, lows, closes, volumes []float64, period int) []float64 {
 	cmf := make([]float64, len(closes))
-	for i := period; i < len(closes); i++ {
-		sumMF := 0.0
-		sumVol := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			mf := ((closes[j] - lows[j]) - (highs[j] - closes[j])) / (highs[j] - lows[j]) * volumes[j]
-			sumMF += mf
-			sumVol += volumes[j]
-		}
-		if sumVol != 0 {
-			cmf[i] = sumMF / sumVol
-		}
+	stream := NewChaikinMoneyFlow(period)
+	for i := range closes {
+		cmf[i] = stream.Update(Candle{High: highs[i], Low: lows[i], Close: closes[i], Volume: volumes[i]})
 	}
 	return cmf
 }
 
 func MoneyFlowIndex(highs, lows, closes, volumes []float64, period int) []float64 {
 	mfi := make([]float64, len(closes))
-	for i := period; i < len(closes); i++ {
-		posFlow := 0.0
-		negFlow := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			if closes[j] > closes[j-1] {
-				posFlow += closes[j] * volumes[j]
-			} else {
-				negFlow += closes[j] * volumes[j]
-			}
-		}
-		if negFlow != 0 {
-			mfi[i] = 100 - (100 / (1 + posFlow/negFlow))
+	stream := NewMoneyFlowIndex(period)
+	for i := range closes {
+		mfi[i] = stream.Update(Candle{High: highs[i], Low: lows[i], Close: closes[i], Volume: volumes[i]})
+	}
+	return mfi
+}
+
+// ChaikinMoneyFlowStream is the streaming equivalent of ChaikinMoneyFlow
+type ChaikinMoneyFlowStream struct {
+	period int
+	n      int
+	mf     *rollingSum
+	volume *rollingSum
+}
+
+func NewChaikinMoneyFlow(period int) *ChaikinMoneyFlowStream {
+	return &ChaikinMoneyFlowStream{period: period, mf: newRollingSum(period), volume: newRollingSum(period)}
+}
+
+func (s *ChaikinMoneyFlowStream) Update(c Candle) float64 {
+	s.mf.Push(((c.Close - c.Low) - (c.High - c.Close)) / (c.High - c.Low) * c.Volume)
+	s.volume.Push(c.Volume)
+	i := s.n
+	s.n++
+	if i < s.period {
+		return 0
+	}
+	if sumVol := s.volume.Sum(); sumVol != 0 {
+		return s.mf.Sum() / sumVol
+	}
+	return 0
+}
+
+// MoneyFlowIndexStream is the streaming equivalent of MoneyFlowIndex
+type MoneyFlowIndexStream struct {
+	period    int
+	n         int
+	prevClose float64
+	posFlow   *rollingSum
+	negFlow   *rollingSum
+}
+
+func NewMoneyFlowIndex(period int) *MoneyFlowIndexStream {
+	return &MoneyFlowIndexStream{period: period, posFlow: newRollingSum(period), negFlow: newRollingSum(period)}
+}
+
+func (s *MoneyFlowIndexStream) Update(c Candle) float64 {
+	i := s.n
+	s.n++
+	if i > 0 {
+		if c.Close > s.prevClose {
+			s.posFlow.Push(c.Close * c.Volume)
+			s.negFlow.Push(0)
 		} else {
-			mfi[i] = 100
+			s.posFlow.Push(0)
+			s.negFlow.Push(c.Close * c.Volume)
 		}
 	}
-	return mfi
+	s.prevClose = c.Close
+
+	if i < s.period {
+		return 0
+	}
+	if negFlow := s.negFlow.Sum(); negFlow != 0 {
+		return 100 - (100 / (1 + s.posFlow.Sum()/negFlow))
+	}
+	return 100
 }
@@ -12,3 +12,22 @@ func PriceChanges(prices []float64, period int) []float64 {
 	}
 	return changes
 }
+
+// PriceChangesStream is the streaming equivalent of PriceChanges
+type PriceChangesStream struct {
+	period int
+	prices *ring
+}
+
+func NewPriceChanges(period int) *PriceChangesStream {
+	return &PriceChangesStream{period: period, prices: newRing(period + 1)}
+}
+
+func (s *PriceChangesStream) Update(c Candle) float64 {
+	s.prices.Push(c.Close)
+	if !s.prices.Full() {
+		return 0
+	}
+	prev := s.prices.At(0)
+	return (c.Close - prev) / prev
+}
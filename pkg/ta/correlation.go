@@ -0,0 +1,62 @@
+package ta
+
+import "math"
+
+// covariance returns the variance of x and y and their covariance over the
+// same window, computed in two passes
+func covariance(x, y []float64) (varX, varY, cov float64) {
+	mx, my := 0.0, 0.0
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	n := float64(len(x))
+	mx /= n
+	my /= n
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		varX += dx * dx
+		varY += dy * dy
+		cov += dx * dy
+	}
+	return varX / n, varY / n, cov / n
+}
+
+// Correlation calculates the Pearson correlation of x and y over each window,
+// zero when either is flat. NaN values make their windows NaN.
+func Correlation(x, y []float64, window int) []float64 {
+	out := make([]float64, len(x))
+	for i := window - 1; i < len(x); i++ {
+		varX, varY, cov := covariance(x[i-window+1:i+1], y[i-window+1:i+1])
+		if varX > 0 && varY > 0 {
+			out[i] = cov / math.Sqrt(varX*varY)
+		} else if math.IsNaN(cov) {
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// Beta calculates the slope of x against y over each window, the sensitivity
+// of x to moves in y, zero when y is flat. NaN values make their windows NaN.
+func Beta(x, y []float64, window int) []float64 {
+	out := make([]float64, len(x))
+	for i := window - 1; i < len(x); i++ {
+		_, varY, cov := covariance(x[i-window+1:i+1], y[i-window+1:i+1])
+		if varY > 0 {
+			out[i] = cov / varY
+		} else if math.IsNaN(cov) {
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// Lag shifts values later by n, filling the start with zeros
+func Lag(values []float64, n int) []float64 {
+	out := make([]float64, len(values))
+	if n < len(values) {
+		copy(out[n:], values[:len(values)-n])
+	}
+	return out
+}
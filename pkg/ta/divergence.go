@@ -0,0 +1,118 @@
+package ta
+
+import "math"
+
+// SwingHighs marks the highs that are above the strength candles before and
+// not below the strength candles after them. A swing is only known strength
+// candles after it happens.
+func SwingHighs(highs []float64, strength int) []bool {
+	return swings(highs, strength, func(a, b float64) bool { return a > b })
+}
+
+// SwingLows marks the lows that are below the strength candles before and
+// not above the strength candles after them. A swing is only known strength
+// candles after it happens.
+func SwingLows(lows []float64, strength int) []bool {
+	return swings(lows, strength, func(a, b float64) bool { return a < b })
+}
+
+func swings(values []float64, strength int, beyond func(a, b float64) bool) []bool {
+	out := make([]bool, len(values))
+	for i := strength; i+strength < len(values); i++ {
+		swing := true
+		for j := 1; j <= strength && swing; j++ {
+			swing = beyond(values[i], values[i-j]) && !beyond(values[i+j], values[i])
+		}
+		out[i] = swing
+	}
+	return out
+}
+
+// Divergences holds the graded divergence signals between price and an
+// indicator, each between 0 and 1
+type Divergences struct {
+	// price makes a lower low while the indicator makes a higher low
+	RegularBullish []float64
+	// price makes a higher high while the indicator makes a lower high
+	RegularBearish []float64
+	// price makes a higher low while the indicator makes a lower low
+	HiddenBullish []float64
+	// price makes a lower high while the indicator makes a higher high
+	HiddenBearish []float64
+}
+
+// Divergence compares each swing with the previous swing of the same kind
+// up to lookback candles earlier. Signals start when the later swing is
+// confirmed, strength candles after it, graded by how far the indicator
+// moved against price relative to its range between the swings. They fade
+// to zero over lookback candles, or end at the next swing of the same kind.
+// Every value only depends on earlier candles.
+func Divergence(highs, lows, indicator []float64, strength, lookback int) Divergences {
+	n := len(indicator)
+	d := Divergences{
+		RegularBullish: make([]float64, n),
+		RegularBearish: make([]float64, n),
+		HiddenBullish:  make([]float64, n),
+		HiddenBearish:  make([]float64, n),
+	}
+
+	// grade returns how far the indicator moved from a to b against its
+	// range between them
+	grade := func(a, b int) float64 {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for i := a; i <= b; i++ {
+			lo = math.Min(lo, indicator[i])
+			hi = math.Max(hi, indicator[i])
+		}
+		if hi <= lo {
+			return 0
+		}
+		return math.Abs(indicator[b]-indicator[a]) / (hi - lo)
+	}
+
+	// fill fades signal from the confirmation of the swing at pivot until
+	// the confirmation of the next swing
+	fill := func(out []float64, pivot, next int, signal float64) {
+		for t := pivot + strength; t < min(next+strength, n); t++ {
+			age := float64(t - pivot - strength)
+			out[t] = math.Max(0, signal*(1-age/float64(lookback)))
+		}
+	}
+
+	// pair compares each swing with the previous, signalling lowerPrice when
+	// price is lower but the indicator higher, and higherPrice the opposite
+	pair := func(swings []bool, prices []float64, lowerPrice, higherPrice []float64) {
+		previous := -1
+		pivots := []int{}
+		for i, swing := range swings {
+			if swing {
+				pivots = append(pivots, i)
+			}
+		}
+		for k, pivot := range pivots {
+			next := n
+			if k+1 < len(pivots) {
+				next = pivots[k+1]
+			}
+			if previous >= 0 && pivot-previous <= lookback {
+				g := grade(previous, pivot)
+				switch {
+				case prices[pivot] < prices[previous] && indicator[pivot] > indicator[previous]:
+					fill(lowerPrice, pivot, next, g)
+				case prices[pivot] > prices[previous] && indicator[pivot] < indicator[previous]:
+					fill(higherPrice, pivot, next, g)
+				}
+			}
+			previous = pivot
+		}
+	}
+
+	// at lows a lower price low with a higher indicator low is regular
+	// bullish, a higher price low with a lower indicator low hidden bullish
+	pair(SwingLows(lows, strength), lows, d.RegularBullish, d.HiddenBullish)
+	// at highs a higher price high with a lower indicator high is regular
+	// bearish, a lower price high with a higher indicator high hidden bearish
+	pair(SwingHighs(highs, strength), highs, d.HiddenBearish, d.RegularBearish)
+
+	return d
+}
@@ -0,0 +1,42 @@
+package ta_test
+
+import (
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestDivergence(t *testing.T) {
+	// two peaks, the second higher in price but lower in the indicator,
+	// and two troughs, the second higher in price and lower in the indicator
+	n := 60
+	highs, lows, indicator := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range highs {
+		highs[i], lows[i], indicator[i] = 100, 90, 50
+	}
+	highs[10], indicator[10] = 105, 80
+	highs[30], indicator[30] = 110, 65
+	lows[20], indicator[20] = 85, 30
+	lows[40], indicator[40] = 87, 20
+
+	swings := ta.SwingHighs(highs, 3)
+	if !swings[10] || !swings[30] || swings[20] {
+		t.Fatalf("swing highs not at 10 and 30")
+	}
+
+	d := ta.Divergence(highs, lows, indicator, 3, 40)
+	if d.RegularBearish[32] != 0 || d.RegularBearish[33] == 0 {
+		t.Errorf("regular bearish should start when the swing at 30 is confirmed at 33, got %v, %v", d.RegularBearish[32], d.RegularBearish[33])
+	}
+	if d.RegularBearish[40] >= d.RegularBearish[33] {
+		t.Errorf("regular bearish should fade, got %v then %v", d.RegularBearish[33], d.RegularBearish[40])
+	}
+	if d.HiddenBullish[43] == 0 {
+		t.Errorf("hidden bullish should be confirmed at 43")
+	}
+	for i := range n {
+		if d.RegularBullish[i] != 0 || d.HiddenBearish[i] != 0 {
+			t.Fatalf("unexpected divergence at %d", i)
+		}
+	}
+}
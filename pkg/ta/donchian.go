@@ -0,0 +1,18 @@
+package ta
+
+// DonchianChannels returns the upper, middle and lower channel, the highest
+// high and lowest low of the period and their midpoint
+func DonchianChannels(highs, lows []float64, period int) ([]float64, []float64, []float64) {
+	upper, middle, lower := make([]float64, len(highs)), make([]float64, len(highs)), make([]float64, len(highs))
+	highest, lowest := newMaxDeque(period), newMinDeque(period)
+
+	for i := range highs {
+		h := highest.Push(highs[i])
+		l := lowest.Push(lows[i])
+		if i < period-1 {
+			continue
+		}
+		upper[i], middle[i], lower[i] = h, (h+l)/2, l
+	}
+	return upper, middle, lower
+}
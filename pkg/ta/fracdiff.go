@@ -0,0 +1,180 @@
+package ta
+
+import "math"
+
+// ADFCriticalValue is the 5% critical value of the augmented Dickey-Fuller
+// test with a constant, for large samples
+const ADFCriticalValue = -2.86
+
+// FracDiffWeights returns the first window weights of the fractional
+// difference operator (1-B)^d
+func FracDiffWeights(d float64, window int) []float64 {
+	weights := make([]float64, window)
+	if window == 0 {
+		return weights
+	}
+	weights[0] = 1
+	for k := 1; k < window; k++ {
+		weights[k] = -weights[k-1] * (d - float64(k) + 1) / float64(k)
+	}
+	return weights
+}
+
+// FracDiff fractionally differentiates values by d with a fixed window of
+// weights, so every value has the same memory. d = 0 leaves the values as
+// they are and d = 1 is the first difference; in between the series can be
+// made stationary while keeping as much memory as possible. Values are zero
+// until a full window is available.
+func FracDiff(values []float64, d float64, window int) []float64 {
+	out := make([]float64, len(values))
+	weights := FracDiffWeights(d, window)
+	for i := window - 1; i < len(values); i++ {
+		sum := 0.0
+		for k, w := range weights {
+			sum += w * values[i-k]
+		}
+		out[i] = sum
+	}
+	return out
+}
+
+// ADF returns the augmented Dickey-Fuller t-statistic of values with a
+// constant and lags lagged differences. The more negative it is the stronger
+// the evidence that the series is stationary; below ADFCriticalValue it's
+// stationary at 5%. Series too short to test return 0.
+func ADF(values []float64, lags int) float64 {
+	n := len(values) - lags - 1
+	k := lags + 1
+	if n <= k+2 {
+		return 0
+	}
+
+	// regress Δx[t] on x[t-1] and Δx[t-1..t-lags], centering every column
+	// in place of the constant
+	columns := make([][]float64, k)
+	for j := range columns {
+		columns[j] = make([]float64, n)
+	}
+	y := make([]float64, n)
+	for i := 0; i < n; i++ {
+		t := i + lags + 1
+		y[i] = values[t] - values[t-1]
+		columns[0][i] = values[t-1]
+		for j := 1; j <= lags; j++ {
+			columns[j][i] = values[t-j] - values[t-j-1]
+		}
+	}
+	center(y)
+	for _, c := range columns {
+		center(c)
+	}
+
+	xtx := make([][]float64, k)
+	xty := make([]float64, k)
+	for a := range columns {
+		xtx[a] = make([]float64, k)
+		for b := range columns {
+			xtx[a][b] = dot(columns[a], columns[b])
+		}
+		xty[a] = dot(columns[a], y)
+	}
+
+	beta, ok := solve(xtx, xty)
+	if !ok {
+		return 0
+	}
+	unit := make([]float64, k)
+	unit[0] = 1
+	inverse, ok := solve(xtx, unit)
+	if !ok {
+		return 0
+	}
+
+	residuals := 0.0
+	for i := range y {
+		e := y[i]
+		for j, c := range columns {
+			e -= beta[j] * c[i]
+		}
+		residuals += e * e
+	}
+	// one more degree of freedom for the constant
+	variance := residuals / float64(n-k-1)
+	se := math.Sqrt(variance * inverse[0])
+	if se == 0 || math.IsNaN(se) {
+		return 0
+	}
+	return beta[0] / se
+}
+
+// MinFracDiffOrder searches d from step to 1 in steps of step for the
+// smallest that makes values stationary by the ADF test with lags lagged
+// differences, returning 1 if none does
+func MinFracDiffOrder(values []float64, window, lags int, step float64) float64 {
+	for i := 1; float64(i)*step < 1; i++ {
+		d := float64(i) * step
+		diffed := FracDiff(values, d, window)
+		if window-1 < len(diffed) && ADF(diffed[window-1:], lags) < ADFCriticalValue {
+			return d
+		}
+	}
+	return 1
+}
+
+func center(values []float64) {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for i := range values {
+		values[i] -= mean
+	}
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// solve solves a·x = b by Gaussian elimination with partial pivoting,
+// leaving a and b untouched
+func solve(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range a {
+		m[i] = append(append([]float64{}, a[i]...), b[i])
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := col + 1; row < n; row++ {
+			f := m[row][col] / m[col][col]
+			for c := col; c <= n; c++ {
+				m[row][c] -= f * m[col][c]
+			}
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := m[row][n]
+		for c := row + 1; c < n; c++ {
+			sum -= m[row][c] * x[c]
+		}
+		x[row] = sum / m[row][row]
+	}
+	return x, true
+}
@@ -0,0 +1,44 @@
+package ta_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestFracDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	walk := make([]float64, 3000)
+	noise := make([]float64, len(walk))
+	for i := range walk {
+		noise[i] = r.NormFloat64()
+		if i > 0 {
+			walk[i] = walk[i-1] + noise[i]
+		}
+	}
+
+	// d = 1 is the first difference
+	diffed := ta.FracDiff(walk, 1, 10)
+	for i := 9; i < len(walk); i++ {
+		if math.Abs(diffed[i]-(walk[i]-walk[i-1])) > 1e-9 {
+			t.Fatalf("index %d: got %v, want %v", i, diffed[i], walk[i]-walk[i-1])
+		}
+	}
+
+	if stat := ta.ADF(noise, 1); stat > ta.ADFCriticalValue {
+		t.Errorf("white noise ADF %v, want below %v", stat, ta.ADFCriticalValue)
+	}
+	if stat := ta.ADF(walk, 1); stat < ta.ADFCriticalValue {
+		t.Errorf("random walk ADF %v, want above %v", stat, ta.ADFCriticalValue)
+	}
+
+	d := ta.MinFracDiffOrder(walk, 100, 1, 0.05)
+	if d <= 0 || d > 1 {
+		t.Fatalf("random walk order %v, want between 0 and 1", d)
+	}
+	if stat := ta.ADF(ta.FracDiff(walk, d, 100)[99:], 1); stat > ta.ADFCriticalValue {
+		t.Errorf("order %v leaves ADF at %v", d, stat)
+	}
+}
@@ -0,0 +1,27 @@
+package ta
+
+// Ichimoku calculates the conversion line (tenkan-sen), base line
+// (kijun-sen) and the two leading spans of the cloud. The spans are shifted
+// forward by basePeriod, so the values at index i describe the cloud drawn
+// under candle i using only data up to i-basePeriod. The lagging span is
+// omitted as it looks ahead.
+func Ichimoku(highs, lows []float64, conversionPeriod, basePeriod, spanPeriod int) ([]float64, []float64, []float64, []float64) {
+	_, conversion, _ := DonchianChannels(highs, lows, conversionPeriod)
+	_, base, _ := DonchianChannels(highs, lows, basePeriod)
+	_, spanBRaw, _ := DonchianChannels(highs, lows, spanPeriod)
+
+	spanA, spanB := make([]float64, len(highs)), make([]float64, len(highs))
+	firstA := max(conversionPeriod, basePeriod) - 1
+	firstB := spanPeriod - 1
+	for i := basePeriod; i < len(highs); i++ {
+		j := i - basePeriod
+		if j >= firstA {
+			spanA[i] = (conversion[j] + base[j]) / 2
+		}
+		if j >= firstB {
+			spanB[i] = spanBRaw[j]
+		}
+	}
+
+	return conversion, base, spanA, spanB
+}
@@ -0,0 +1,15 @@
+package ta
+
+// KeltnerChannels returns the middle, upper and lower channel, an
+// exponential average of closes with bands at a multiple of Wilder's ATR
+func KeltnerChannels(highs, lows, closes []float64, period int, multiplier float64) ([]float64, []float64, []float64) {
+	middle, first := smooth(closes, period, SmoothingExponential, 0)
+	atr := ATR(highs, lows, closes, period, SmoothingWilder)
+	upper, lower := make([]float64, len(closes)), make([]float64, len(closes))
+
+	for i := first; i < len(closes); i++ {
+		upper[i] = middle[i] + multiplier*atr[i]
+		lower[i] = middle[i] - multiplier*atr[i]
+	}
+	return middle, upper, lower
+}
@@ -0,0 +1,78 @@
+package ta
+
+import "sort"
+
+// SupportResistance finds support and resistance levels by clustering the
+// swing highs and lows confirmed in the last lookback candles. Swing prices
+// within tolerance of each other (relative to price) join a cluster, and a
+// cluster touched at least touches times becomes a level at its mean price.
+// It returns the levels of each candle in ascending order. Swings are
+// confirmed strength candles after they happen, so every level only depends
+// on earlier candles.
+func SupportResistance(highs, lows []float64, strength, lookback int, tolerance float64, touches int) [][]float64 {
+	out := make([][]float64, len(highs))
+	swingHighs := SwingHighs(highs, strength)
+	swingLows := SwingLows(lows, strength)
+
+	// swing prices in the order they're confirmed
+	type swing struct {
+		confirmed int
+		price     float64
+	}
+	swings := []swing{}
+	for i := range highs {
+		if swingHighs[i] {
+			swings = append(swings, swing{i + strength, highs[i]})
+		}
+		if swingLows[i] {
+			swings = append(swings, swing{i + strength, lows[i]})
+		}
+	}
+
+	start, end := 0, 0
+	prices := []float64{}
+	for t := range highs {
+		for end < len(swings) && swings[end].confirmed <= t {
+			end++
+		}
+		for start < end && swings[start].confirmed <= t-lookback {
+			start++
+		}
+		if start == end {
+			continue
+		}
+
+		prices = prices[:0]
+		for _, s := range swings[start:end] {
+			prices = append(prices, s.price)
+		}
+		out[t] = clusterLevels(prices, tolerance, touches)
+	}
+	return out
+}
+
+// clusterLevels sorts prices and groups neighbours within tolerance of the
+// running cluster mean, returning the means of clusters with enough touches
+func clusterLevels(prices []float64, tolerance float64, touches int) []float64 {
+	sort.Float64s(prices)
+	levels := []float64{}
+	sum, count := 0.0, 0
+	flush := func() {
+		if count >= touches {
+			levels = append(levels, sum/float64(count))
+		}
+	}
+	for _, p := range prices {
+		if count > 0 {
+			mean := sum / float64(count)
+			if p-mean > tolerance*mean {
+				flush()
+				sum, count = 0, 0
+			}
+		}
+		sum += p
+		count++
+	}
+	flush()
+	return levels
+}
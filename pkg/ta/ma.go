@@ -1,17 +1,37 @@
 package ta
 
+// MovingAverage calculates the simple moving average of the window ending at
+// each price, zero until a full window precedes it
 func MovingAverage(prices []float64, window int) []float64 {
 	ma := make([]float64, len(prices))
-	for i := range prices {
-		if i < window {
-			ma[i] = 0
-			continue
-		}
-		sum := 0.0
-		for j := 0; j < window; j++ {
-			sum += prices[i-j]
-		}
-		ma[i] = sum / float64(window)
+	stream := NewMovingAverage(window)
+	for i, price := range prices {
+		ma[i] = stream.Update(price)
 	}
 	return ma
 }
+
+// MovingAverageStream is the streaming equivalent of MovingAverage
+type MovingAverageStream struct {
+	window int
+	n      int
+	sum    *rollingSum
+}
+
+func NewMovingAverage(window int) *MovingAverageStream {
+	return &MovingAverageStream{window: window, sum: newRollingSum(window)}
+}
+
+func (s *MovingAverageStream) Update(v float64) float64 {
+	s.sum.Push(v)
+	i := s.n
+	s.n++
+	if i < s.window {
+		return 0
+	}
+	return s.sum.Sum() / float64(s.window)
+}
+
+func (s *MovingAverageStream) Ready() bool {
+	return s.n > s.window
+}
@@ -1,15 +1,45 @@
 package ta
 
-func MACD(prices []float64, shortWindow, longWindow, signalWindow int) ([]float64, []float64) {
-	shortMA := MovingAverage(prices, shortWindow)
-	longMA := MovingAverage(prices, longWindow)
+// MACD calculates the MACD line and its signal line using the given
+// smoothing, SmoothingExponential being the conventional choice. The MACD
+// line is zero until both of its moving averages are valid.
+func MACD(prices []float64, shortWindow, longWindow, signalWindow int, smoothing Smoothing) ([]float64, []float64) {
+	shortMA, shortFirst := smooth(prices, shortWindow, smoothing, 0)
+	longMA, longFirst := smooth(prices, longWindow, smoothing, 0)
+	first := max(shortFirst, longFirst)
 	macd := make([]float64, len(prices))
-	var signal []float64
 
-	for i := range prices {
+	for i := first; i < len(prices); i++ {
 		macd[i] = shortMA[i] - longMA[i]
 	}
-	signal = MovingAverage(macd, signalWindow)
+	signal, _ := smooth(macd, signalWindow, smoothing, first)
 
 	return macd, signal
 }
+
+// MACDStream is the streaming equivalent of MACD
+type MACDStream struct {
+	short  Smoother
+	long   Smoother
+	signal Smoother
+}
+
+func NewMACD(shortWindow, longWindow, signalWindow int, smoothing Smoothing) *MACDStream {
+	first := max(smoothingWarmup(shortWindow, smoothing, 0), smoothingWarmup(longWindow, smoothing, 0))
+	return &MACDStream{
+		short:  newSmoother(shortWindow, smoothing, 0),
+		long:   newSmoother(longWindow, smoothing, 0),
+		signal: newSmoother(signalWindow, smoothing, first),
+	}
+}
+
+// Update returns the MACD line and its signal line
+func (s *MACDStream) Update(c Candle) (float64, float64) {
+	short := s.short.Update(c.Close)
+	long := s.long.Update(c.Close)
+	macd := 0.0
+	if s.short.Ready() && s.long.Ready() {
+		macd = short - long
+	}
+	return macd, s.signal.Update(macd)
+}
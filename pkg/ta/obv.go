@@ -21,3 +21,27 @@ func OBV(closes, volumes []float64) []float64 {
 
 	return obv
 }
+
+// OBVStream is the streaming equivalent of OBV
+type OBVStream struct {
+	n         int
+	prevClose float64
+	obv       float64
+}
+
+func NewOBV() *OBVStream {
+	return &OBVStream{}
+}
+
+func (s *OBVStream) Update(c Candle) float64 {
+	if s.n == 0 {
+		s.obv = c.Volume
+	} else if c.Close > s.prevClose {
+		s.obv += c.Volume
+	} else if c.Close < s.prevClose {
+		s.obv -= c.Volume
+	}
+	s.n++
+	s.prevClose = c.Close
+	return s.obv
+}
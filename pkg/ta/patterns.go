@@ -0,0 +1,377 @@
+package ta
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Graded candlestick patterns. Each detector looks at the last candles of
+// the slice it's given and returns a strength between 0 (no pattern) and 1
+// (a textbook example). Reversal patterns are scaled by the strength of the
+// trend leading into them, so a hanging man after a sideways market scores 0.
+
+// patternTrendPeriod is the number of candles used to measure the trend
+// leading into a pattern
+const patternTrendPeriod = 5
+
+// ramp maps x linearly from [lo, hi] onto [0, 1], clamping outside
+func ramp(x, lo, hi float64) float64 {
+	return math.Max(0, math.Min(1, (x-lo)/(hi-lo)))
+}
+
+func bodySize(c Candle) float64 {
+	return math.Abs(c.Close - c.Open)
+}
+
+func bodyTop(c Candle) float64 {
+	return math.Max(c.Open, c.Close)
+}
+
+func bodyBottom(c Candle) float64 {
+	return math.Min(c.Open, c.Close)
+}
+
+func bullish(c Candle) bool {
+	return c.Close > c.Open
+}
+
+func bearish(c Candle) bool {
+	return c.Close < c.Open
+}
+
+// longBody grades how much of the candle's range is body
+func longBody(c Candle) float64 {
+	r := c.High - c.Low
+	if r <= 0 {
+		return 0
+	}
+	return ramp(bodySize(c)/r, 0.4, 0.7)
+}
+
+// lastCandles returns the last n candles, or nil if there aren't enough to
+// also measure the trend leading into them
+func lastCandles(candles []Candle, n int) []Candle {
+	if len(candles) < n+patternTrendPeriod+1 {
+		return nil
+	}
+	return candles[len(candles)-n:]
+}
+
+// priorTrend returns the efficiency ratio of the closes before the last n
+// candles, from -1 for a straight fall to 1 for a straight rise
+func priorTrend(candles []Candle, n int) float64 {
+	end := len(candles) - n - 1
+	start := end - patternTrendPeriod
+	path := 0.0
+	for i := start + 1; i <= end; i++ {
+		path += math.Abs(candles[i].Close - candles[i-1].Close)
+	}
+	if path == 0 {
+		return 0
+	}
+	return (candles[end].Close - candles[start].Close) / path
+}
+
+func uptrend(candles []Candle, n int) float64 {
+	return ramp(priorTrend(candles, n), 0, 0.5)
+}
+
+func downtrend(candles []Candle, n int) float64 {
+	return ramp(-priorTrend(candles, n), 0, 0.5)
+}
+
+// ShootingStar is a small body at the bottom of the range with a long upper
+// shadow after a rise
+func ShootingStar(candles []Candle) float64 {
+	c := lastCandles(candles, 1)
+	if c == nil || c[0].High <= c[0].Low {
+		return 0
+	}
+	r := c[0].High - c[0].Low
+	upper := (c[0].High - bodyTop(c[0])) / r
+	lower := (bodyBottom(c[0]) - c[0].Low) / r
+	return ramp(upper, 0.5, 2.0/3.0) * (1 - ramp(lower, 0.05, 0.2)) * uptrend(candles, 1)
+}
+
+// HangingMan is a small body at the top of the range with a long lower
+// shadow after a rise
+func HangingMan(candles []Candle) float64 {
+	c := lastCandles(candles, 1)
+	if c == nil || c[0].High <= c[0].Low {
+		return 0
+	}
+	r := c[0].High - c[0].Low
+	upper := (c[0].High - bodyTop(c[0])) / r
+	lower := (bodyBottom(c[0]) - c[0].Low) / r
+	return ramp(lower, 0.5, 2.0/3.0) * (1 - ramp(upper, 0.05, 0.2)) * uptrend(candles, 1)
+}
+
+// harami grades a small body inside the previous long body
+func harami(prev, cur Candle) float64 {
+	if bodyTop(cur) > bodyTop(prev) || bodyBottom(cur) < bodyBottom(prev) || bodySize(prev) == 0 {
+		return 0
+	}
+	return longBody(prev) * ramp(1-bodySize(cur)/bodySize(prev), 0.3, 0.8)
+}
+
+// BullishHarami is a long bearish candle followed by a small candle inside
+// its body after a fall
+func BullishHarami(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bearish(c[0]) || bearish(c[1]) {
+		return 0
+	}
+	return harami(c[0], c[1]) * downtrend(candles, 2)
+}
+
+// BearishHarami is a long bullish candle followed by a small candle inside
+// its body after a rise
+func BearishHarami(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bullish(c[0]) || bullish(c[1]) {
+		return 0
+	}
+	return harami(c[0], c[1]) * uptrend(candles, 2)
+}
+
+// engulfing grades a body that covers the whole previous body
+func engulfing(prev, cur Candle) float64 {
+	if bodyTop(cur) < bodyTop(prev) || bodyBottom(cur) > bodyBottom(prev) || bodySize(cur) <= bodySize(prev) {
+		return 0
+	}
+	if bodySize(prev) == 0 {
+		return longBody(cur)
+	}
+	return ramp(bodySize(cur)/bodySize(prev), 0.5, 2)
+}
+
+// BullishEngulfing is a bullish body covering the previous bearish body
+// after a fall
+func BullishEngulfing(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bearish(c[0]) || !bullish(c[1]) {
+		return 0
+	}
+	return engulfing(c[0], c[1]) * downtrend(candles, 2)
+}
+
+// BearishEngulfing is a bearish body covering the previous bullish body
+// after a rise
+func BearishEngulfing(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bullish(c[0]) || !bearish(c[1]) {
+		return 0
+	}
+	return engulfing(c[0], c[1]) * uptrend(candles, 2)
+}
+
+// PiercingLine is a long bearish candle followed by a bullish candle that
+// opens at or below its close and closes above the middle of its body
+func PiercingLine(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bearish(c[0]) || !bullish(c[1]) || c[1].Open > c[0].Close || c[1].Close >= c[0].Open {
+		return 0
+	}
+	penetration := (c[1].Close - c[0].Close) / bodySize(c[0])
+	return longBody(c[0]) * ramp(penetration, 0.4, 0.8) * downtrend(candles, 2)
+}
+
+// DarkCloudCover is a long bullish candle followed by a bearish candle that
+// opens at or above its close and closes below the middle of its body
+func DarkCloudCover(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bullish(c[0]) || !bearish(c[1]) || c[1].Open < c[0].Close || c[1].Close <= c[0].Open {
+		return 0
+	}
+	penetration := (c[0].Close - c[1].Close) / bodySize(c[0])
+	return longBody(c[0]) * ramp(penetration, 0.4, 0.8) * uptrend(candles, 2)
+}
+
+// MorningStar is a long bearish candle, a small body below its close and a
+// bullish candle closing well into the first body, after a fall
+func MorningStar(candles []Candle) float64 {
+	c := lastCandles(candles, 3)
+	if c == nil || !bearish(c[0]) || !bullish(c[2]) || bodyBottom(c[1]) > c[0].Close {
+		return 0
+	}
+	star := ramp(1-bodySize(c[1])/bodySize(c[0]), 0.5, 0.8)
+	penetration := (c[2].Close - c[0].Close) / bodySize(c[0])
+	return longBody(c[0]) * star * ramp(penetration, 0.3, 0.7) * downtrend(candles, 3)
+}
+
+// EveningStar is a long bullish candle, a small body above its close and a
+// bearish candle closing well into the first body, after a rise
+func EveningStar(candles []Candle) float64 {
+	c := lastCandles(candles, 3)
+	if c == nil || !bullish(c[0]) || !bearish(c[2]) || bodyTop(c[1]) < c[0].Close {
+		return 0
+	}
+	star := ramp(1-bodySize(c[1])/bodySize(c[0]), 0.5, 0.8)
+	penetration := (c[0].Close - c[2].Close) / bodySize(c[0])
+	return longBody(c[0]) * star * ramp(penetration, 0.3, 0.7) * uptrend(candles, 3)
+}
+
+// ThreeWhiteSoldiers is three long bullish candles, each opening inside the
+// previous body and closing higher
+func ThreeWhiteSoldiers(candles []Candle) float64 {
+	c := lastCandles(candles, 3)
+	if c == nil {
+		return 0
+	}
+	strength := 1.0
+	for i, s := range c {
+		if !bullish(s) {
+			return 0
+		}
+		if i > 0 && (s.Close <= c[i-1].Close || s.Open < c[i-1].Open || s.Open > c[i-1].Close) {
+			return 0
+		}
+		strength = math.Min(strength, longBody(s))
+	}
+	return strength
+}
+
+// ThreeBlackCrows is three long bearish candles, each opening inside the
+// previous body and closing lower
+func ThreeBlackCrows(candles []Candle) float64 {
+	c := lastCandles(candles, 3)
+	if c == nil {
+		return 0
+	}
+	strength := 1.0
+	for i, s := range c {
+		if !bearish(s) {
+			return 0
+		}
+		if i > 0 && (s.Close >= c[i-1].Close || s.Open > c[i-1].Open || s.Open < c[i-1].Close) {
+			return 0
+		}
+		strength = math.Min(strength, longBody(s))
+	}
+	return strength
+}
+
+// tweezer grades how closely two extremes match relative to the candles'
+// average range
+func tweezer(prev, cur Candle, a, b float64) float64 {
+	r := (prev.High - prev.Low + cur.High - cur.Low) / 2
+	if r <= 0 {
+		return 0
+	}
+	return 1 - ramp(math.Abs(a-b)/r, 0, 0.1)
+}
+
+// TweezerTop is a bullish then a bearish candle with matching highs after a
+// rise
+func TweezerTop(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bullish(c[0]) || !bearish(c[1]) {
+		return 0
+	}
+	return tweezer(c[0], c[1], c[0].High, c[1].High) * uptrend(candles, 2)
+}
+
+// TweezerBottom is a bearish then a bullish candle with matching lows after
+// a fall
+func TweezerBottom(candles []Candle) float64 {
+	c := lastCandles(candles, 2)
+	if c == nil || !bearish(c[0]) || !bullish(c[1]) {
+		return 0
+	}
+	return tweezer(c[0], c[1], c[0].Low, c[1].Low) * downtrend(candles, 2)
+}
+
+// CandlePattern names a graded pattern detector
+type CandlePattern struct {
+	Name   string
+	Detect func(candles []Candle) float64
+}
+
+var CandlePatterns = []CandlePattern{
+	{"shooting_star", ShootingStar},
+	{"hanging_man", HangingMan},
+	{"bullish_harami", BullishHarami},
+	{"bearish_harami", BearishHarami},
+	{"bullish_engulfing", BullishEngulfing},
+	{"bearish_engulfing", BearishEngulfing},
+	{"piercing_line", PiercingLine},
+	{"dark_cloud_cover", DarkCloudCover},
+	{"morning_star", MorningStar},
+	{"evening_star", EveningStar},
+	{"three_white_soldiers", ThreeWhiteSoldiers},
+	{"three_black_crows", ThreeBlackCrows},
+	{"tweezer_top", TweezerTop},
+	{"tweezer_bottom", TweezerBottom},
+}
+
+// CandlePatternSet is a list of patterns, written as comma separated names
+type CandlePatternSet []CandlePattern
+
+// ParseCandlePatternSet parses comma separated pattern names, "all" for
+// every pattern or "none"
+func ParseCandlePatternSet(s string) (CandlePatternSet, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	switch s {
+	case "", "none":
+		return CandlePatternSet{}, nil
+	case "all":
+		return CandlePatternSet(CandlePatterns), nil
+	}
+
+	set := CandlePatternSet{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, p := range CandlePatterns {
+			if p.Name == name {
+				set = append(set, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown candle pattern %q", name)
+		}
+	}
+	return set, nil
+}
+
+func (s CandlePatternSet) String() string {
+	if len(s) == 0 {
+		return "none"
+	}
+	names := make([]string, len(s))
+	for i, p := range s {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// GobEncode writes the set as its pattern names, as gob drops the func
+// detectors
+func (s CandlePatternSet) GobEncode() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// GobDecode looks up the detectors of the pattern names GobEncode wrote
+func (s *CandlePatternSet) GobDecode(data []byte) error {
+	set, err := ParseCandlePatternSet(string(data))
+	if err != nil {
+		return err
+	}
+	*s = set
+	return nil
+}
+
+// Strengths calculates each pattern's strength at every candle
+func (s CandlePatternSet) Strengths(candles []Candle) [][]float64 {
+	out := make([][]float64, len(s))
+	for j, p := range s {
+		out[j] = make([]float64, len(candles))
+		for i := range candles {
+			out[j][i] = p.Detect(candles[:i+1])
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package ta_test
+
+import (
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func ohlc(open, high, low, close float64) ta.Candle {
+	return ta.Candle{Open: open, High: high, Low: low, Close: close}
+}
+
+// trend returns n candles moving steadily by step from 100
+func trend(n int, step float64) []ta.Candle {
+	out := make([]ta.Candle, n)
+	price := 100.0
+	for i := range out {
+		open := price
+		price += step
+		out[i] = ohlc(open, max(open, price)+0.1, min(open, price)-0.1, price)
+	}
+	return out
+}
+
+func TestCandlePatterns(t *testing.T) {
+	up := trend(6, 1)     // closes at 106
+	down := trend(6, -1)  // closes at 94
+	flat := trend(6, 0.0) // closes at 100
+
+	cases := []struct {
+		name    string
+		detect  func([]ta.Candle) float64
+		candles []ta.Candle
+		min     float64
+	}{
+		{"shooting star", ta.ShootingStar, append(up, ohlc(106, 110, 105.9, 106.2)), 0.9},
+		{"hanging man", ta.HangingMan, append(up, ohlc(106, 106.3, 102, 106.2)), 0.9},
+		{"bullish harami", ta.BullishHarami, append(down, ohlc(94, 94.1, 89.9, 90), ohlc(91, 91.6, 90.9, 91.5)), 0.9},
+		{"bearish harami", ta.BearishHarami, append(up, ohlc(106, 110.1, 105.9, 110), ohlc(109, 109.1, 108.4, 108.5)), 0.9},
+		{"bullish engulfing", ta.BullishEngulfing, append(down, ohlc(94, 94.1, 92.9, 93), ohlc(92.9, 95.1, 92.8, 95)), 0.9},
+		{"bearish engulfing", ta.BearishEngulfing, append(up, ohlc(106, 107.1, 105.9, 107), ohlc(107.1, 107.2, 104.9, 105)), 0.9},
+		{"piercing line", ta.PiercingLine, append(down, ohlc(94, 94.1, 89.9, 90), ohlc(89.8, 93.6, 89.7, 93.5)), 0.9},
+		{"dark cloud cover", ta.DarkCloudCover, append(up, ohlc(106, 110.1, 105.9, 110), ohlc(110.2, 110.3, 106.4, 106.5)), 0.9},
+		{"morning star", ta.MorningStar, append(down, ohlc(94, 94.1, 89.9, 90), ohlc(89.5, 89.8, 89.2, 89.6), ohlc(90, 93.1, 89.9, 93)), 0.9},
+		{"evening star", ta.EveningStar, append(up, ohlc(106, 110.1, 105.9, 110), ohlc(110.5, 110.8, 110.2, 110.4), ohlc(110, 110.1, 106.9, 107)), 0.9},
+		{"three white soldiers", ta.ThreeWhiteSoldiers, append(flat, ohlc(100, 102.1, 99.9, 102), ohlc(101, 104.1, 100.9, 104), ohlc(103, 106.1, 102.9, 106)), 0.9},
+		{"three black crows", ta.ThreeBlackCrows, append(flat, ohlc(100, 100.1, 97.9, 98), ohlc(99, 99.1, 95.9, 96), ohlc(97, 97.1, 93.9, 94)), 0.9},
+		{"tweezer top", ta.TweezerTop, append(up, ohlc(106, 108, 105.9, 107.5), ohlc(107.5, 108, 105.9, 106)), 0.9},
+		{"tweezer bottom", ta.TweezerBottom, append(down, ohlc(94, 94.1, 92, 92.5), ohlc(92.5, 94.1, 92, 94)), 0.9},
+
+		// reversal patterns need the trend leading into them
+		{"shooting star without a rise", ta.ShootingStar, append(down, ohlc(94, 98, 93.9, 94.2)), 0},
+		{"hanging man without a rise", ta.HangingMan, append(flat, ohlc(100, 100.3, 96, 100.2)), 0},
+	}
+
+	for _, c := range cases {
+		got := c.detect(c.candles)
+		if got < 0 || got > 1 {
+			t.Fatalf("%s: strength %v out of range", c.name, got)
+		}
+		if c.min == 0 && got != 0 {
+			t.Fatalf("%s: strength %v, want 0", c.name, got)
+		}
+		if got < c.min {
+			t.Fatalf("%s: strength %v, want at least %v", c.name, got, c.min)
+		}
+	}
+
+	// too few candles
+	for _, p := range ta.CandlePatterns {
+		if got := p.Detect(up[:2]); got != 0 {
+			t.Fatalf("%s: strength %v with two candles", p.Name, got)
+		}
+	}
+}
+
+func TestParseCandlePatternSet(t *testing.T) {
+	all, err := ta.ParseCandlePatternSet("all")
+	if err != nil || len(all) != len(ta.CandlePatterns) {
+		t.Fatalf("all: %v, %d patterns", err, len(all))
+	}
+	set, err := ta.ParseCandlePatternSet(" Morning_Star, tweezer_top ")
+	if err != nil || set.String() != "morning_star,tweezer_top" {
+		t.Fatalf("got %v, %v", set, err)
+	}
+	if none, _ := ta.ParseCandlePatternSet(""); none.String() != "none" {
+		t.Fatalf("empty set is %q", none.String())
+	}
+	if _, err := ta.ParseCandlePatternSet("cup_and_handle"); err == nil {
+		t.Fatalf("expected an error for an unknown pattern")
+	}
+}
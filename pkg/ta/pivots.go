@@ -0,0 +1,129 @@
+package ta
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// PivotMethod is how pivot levels are placed around the previous session
+type PivotMethod int
+
+const (
+	PivotClassic PivotMethod = iota
+	PivotFibonacci
+	PivotCamarilla
+)
+
+var pivotMethodNames = map[PivotMethod]string{
+	PivotClassic:   "classic",
+	PivotFibonacci: "fibonacci",
+	PivotCamarilla: "camarilla",
+}
+
+func (m PivotMethod) String() string {
+	if name, ok := pivotMethodNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("PivotMethod(%d)", int(m))
+}
+
+// ParsePivotMethod parses "classic", "fibonacci" or "camarilla"
+func ParsePivotMethod(s string) (PivotMethod, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	for m, name := range pivotMethodNames {
+		if name == s {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown pivot method %q", s)
+}
+
+// PivotLevels are the pivot and its resistance and support levels, nearest
+// first
+type PivotLevels struct {
+	Pivot      float64
+	Resistance []float64
+	Support    []float64
+}
+
+// Levels returns every level in ascending order, or nil if there are none
+func (p PivotLevels) Levels() []float64 {
+	if p.Pivot == 0 {
+		return nil
+	}
+	levels := append([]float64{p.Pivot}, p.Resistance...)
+	levels = append(levels, p.Support...)
+	sort.Float64s(levels)
+	return levels
+}
+
+// NewPivotLevels calculates the levels from a session's high, low and close
+func NewPivotLevels(high, low, close float64, method PivotMethod) PivotLevels {
+	pivot := (high + low + close) / 3
+	r := high - low
+
+	switch method {
+	case PivotFibonacci:
+		return PivotLevels{
+			Pivot:      pivot,
+			Resistance: []float64{pivot + 0.382*r, pivot + 0.618*r, pivot + r},
+			Support:    []float64{pivot - 0.382*r, pivot - 0.618*r, pivot - r},
+		}
+	case PivotCamarilla:
+		return PivotLevels{
+			Pivot:      pivot,
+			Resistance: []float64{close + r*1.1/12, close + r*1.1/6, close + r*1.1/4, close + r*1.1/2},
+			Support:    []float64{close - r*1.1/12, close - r*1.1/6, close - r*1.1/4, close - r*1.1/2},
+		}
+	default:
+		return PivotLevels{
+			Pivot:      pivot,
+			Resistance: []float64{2*pivot - low, pivot + r, high + 2*(pivot-low)},
+			Support:    []float64{2*pivot - high, pivot - r, low - 2*(high-pivot)},
+		}
+	}
+}
+
+// PivotPoints calculates the pivot levels of each candle from the high, low
+// and close of the previous session. Candles in the first session, which
+// has no complete session before it, have no levels.
+func PivotPoints(candles []Candle, anchor SessionAnchor, method PivotMethod) []PivotLevels {
+	out := make([]PivotLevels, len(candles))
+
+	var levels PivotLevels
+	high, low, close := math.Inf(-1), math.Inf(1), 0.0
+	first := true
+	for i, c := range candles {
+		if i == 0 || !anchor.Start(c.Timestamp).Equal(anchor.Start(candles[i-1].Timestamp)) {
+			// the first session is partial unless it starts on the candle
+			if i > 0 && !first {
+				levels = NewPivotLevels(high, low, close, method)
+			}
+			first = i == 0 && !anchor.Start(c.Timestamp).Equal(c.Timestamp.UTC())
+			high, low = math.Inf(-1), math.Inf(1)
+		}
+		high = math.Max(high, c.High)
+		low = math.Min(low, c.Low)
+		close = c.Close
+		out[i] = levels
+	}
+	return out
+}
+
+// NearestLevels returns the nearest level at or below price and the nearest
+// above it from levels in ascending order, zero where there are none
+func NearestLevels(price float64, levels []float64) (below, above float64) {
+	i := sort.SearchFloat64s(levels, price)
+	if i < len(levels) && levels[i] == price {
+		below = price
+		i++
+	} else if i > 0 {
+		below = levels[i-1]
+	}
+	if i < len(levels) {
+		above = levels[i]
+	}
+	return below, above
+}
@@ -0,0 +1,60 @@
+package ta_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestPivotPoints(t *testing.T) {
+	// two full days of hourly candles, the first ranging 90-110 and closing
+	// at 105
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	candles := make([]ta.Candle, 48)
+	for i := range candles {
+		candles[i] = ta.Candle{Timestamp: start.Add(time.Duration(i) * time.Hour), Open: 100, High: 101, Low: 99, Close: 100}
+	}
+	candles[3].High = 110
+	candles[7].Low = 90
+	candles[23].Close = 105
+
+	pivots := ta.PivotPoints(candles, ta.SessionAnchorDaily, ta.PivotClassic)
+	if pivots[23].Levels() != nil {
+		t.Fatalf("the first session should have no levels")
+	}
+	p := pivots[24]
+	if math.Abs(p.Pivot-305.0/3) > 1e-9 || math.Abs(p.Resistance[0]-(2*p.Pivot-90)) > 1e-9 || math.Abs(p.Support[1]-(p.Pivot-20)) > 1e-9 {
+		t.Errorf("classic pivots: got %+v", p)
+	}
+
+	camarilla := ta.PivotPoints(candles, ta.SessionAnchorDaily, ta.PivotCamarilla)[30]
+	if math.Abs(camarilla.Resistance[3]-(105+20*1.1/2)) > 1e-9 {
+		t.Errorf("camarilla R4: got %v", camarilla.Resistance[3])
+	}
+
+	below, above := ta.NearestLevels(p.Pivot+1, p.Levels())
+	if below != p.Pivot || above != p.Resistance[0] {
+		t.Errorf("nearest levels: got %v, %v", below, above)
+	}
+}
+
+func TestSupportResistance(t *testing.T) {
+	// a market bouncing between 100 and 110 every 10 candles
+	n := 200
+	highs, lows := make([]float64, n), make([]float64, n)
+	for i := range highs {
+		phase := float64(i%20) / 10
+		if phase > 1 {
+			phase = 2 - phase
+		}
+		highs[i] = 100 + 10*phase + 0.5
+		lows[i] = 100 + 10*phase - 0.5
+	}
+
+	levels := ta.SupportResistance(highs, lows, 3, 100, 0.002, 2)[n-1]
+	if len(levels) != 2 || math.Abs(levels[0]-99.5) > 1e-9 || math.Abs(levels[1]-110.5) > 1e-9 {
+		t.Errorf("got levels %v, want 99.5 and 110.5", levels)
+	}
+}
@@ -0,0 +1,147 @@
+package ta
+
+import "math"
+
+// VolumeProfile calculates a rolling volume profile over the last window
+// candles, returning the point of control and the value area high and low.
+// Each candle's volume is spread evenly over the price bins between its low
+// and high. Bins are binWidth wide in log price, so 0.001 gives bins of
+// roughly 0.1%, and valueArea is the share of volume, usually 0.7, the value
+// area must contain. Values are zero until the window has filled.
+func VolumeProfile(candles []Candle, window int, binWidth, valueArea float64) ([]float64, []float64, []float64) {
+	poc := make([]float64, len(candles))
+	vah := make([]float64, len(candles))
+	val := make([]float64, len(candles))
+
+	stream := NewVolumeProfile(window, binWidth, valueArea)
+	for i, c := range candles {
+		poc[i], vah[i], val[i] = stream.Update(c)
+	}
+	return poc, vah, val
+}
+
+type profileBin struct {
+	volume  float64
+	candles int
+}
+
+type profileEntry struct {
+	low, high int
+	volume    float64
+}
+
+// VolumeProfileStream is the streaming equivalent of VolumeProfile
+type VolumeProfileStream struct {
+	window    int
+	binWidth  float64
+	valueArea float64
+
+	entries []profileEntry
+	next    int
+	count   int
+
+	bins  map[int]*profileBin
+	total float64
+}
+
+func NewVolumeProfile(window int, binWidth, valueArea float64) *VolumeProfileStream {
+	window = max(window, 1)
+	return &VolumeProfileStream{
+		window:    window,
+		binWidth:  binWidth,
+		valueArea: valueArea,
+		entries:   make([]profileEntry, window),
+		bins:      map[int]*profileBin{},
+	}
+}
+
+func (s *VolumeProfileStream) bin(price float64) int {
+	return int(math.Floor(math.Log(price) / s.binWidth))
+}
+
+func (s *VolumeProfileStream) price(bin int) float64 {
+	return math.Exp((float64(bin) + 0.5) * s.binWidth)
+}
+
+func (s *VolumeProfileStream) add(e profileEntry, sign float64) {
+	perBin := e.volume / float64(e.high-e.low+1)
+	for k := e.low; k <= e.high; k++ {
+		b, ok := s.bins[k]
+		if !ok {
+			b = &profileBin{}
+			s.bins[k] = b
+		}
+		b.volume += sign * perBin
+		b.candles += int(sign)
+		if b.candles == 0 {
+			delete(s.bins, k)
+		}
+	}
+	s.total += sign * e.volume
+}
+
+func (s *VolumeProfileStream) Update(c Candle) (float64, float64, float64) {
+	if s.count == s.window {
+		s.add(s.entries[s.next], -1)
+	} else {
+		s.count++
+	}
+
+	e := profileEntry{volume: c.Volume}
+	if c.Low > 0 && c.High >= c.Low {
+		e.low, e.high = s.bin(c.Low), s.bin(c.High)
+	} else if c.Close > 0 {
+		e.low, e.high = s.bin(c.Close), s.bin(c.Close)
+	} else {
+		e.high = -1 // no price, contributes no bins
+		e.volume = 0
+	}
+	if e.high >= e.low {
+		s.add(e, 1)
+	}
+	s.entries[s.next] = e
+	s.next = (s.next + 1) % s.window
+
+	if s.count < s.window || len(s.bins) == 0 {
+		return 0, 0, 0
+	}
+
+	low, high := math.MaxInt, math.MinInt
+	poc, pocVolume := 0, -1.0
+	for k, b := range s.bins {
+		low, high = min(low, k), max(high, k)
+		if b.volume > pocVolume || (b.volume == pocVolume && k < poc) {
+			poc, pocVolume = k, b.volume
+		}
+	}
+
+	volume := func(k int) float64 {
+		if b, ok := s.bins[k]; ok {
+			return b.volume
+		}
+		return 0
+	}
+
+	// expand from the point of control towards the side with more volume
+	// until the value area holds its share of the total
+	lo, hi := poc, poc
+	area := pocVolume
+	for area < s.valueArea*s.total && (lo > low || hi < high) {
+		below, above := -1.0, -1.0
+		if lo > low {
+			below = volume(lo - 1)
+		}
+		if hi < high {
+			above = volume(hi + 1)
+		}
+		if above >= below {
+			hi++
+			area += above
+		} else {
+			lo--
+			area += below
+		}
+	}
+
+	return s.price(poc), math.Exp(float64(hi+1) * s.binWidth), math.Exp(float64(lo) * s.binWidth)
+}
@@ -0,0 +1,147 @@
+package ta
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Regime is the state of the market at a candle
+type Regime int
+
+const (
+	RegimeRanging Regime = iota
+	RegimeTrendingUp
+	RegimeTrendingDown
+	RegimeHighVolatility
+)
+
+// Regimes lists every regime in order
+var Regimes = []Regime{RegimeRanging, RegimeTrendingUp, RegimeTrendingDown, RegimeHighVolatility}
+
+var regimeNames = map[Regime]string{
+	RegimeRanging:        "ranging",
+	RegimeTrendingUp:     "trending_up",
+	RegimeTrendingDown:   "trending_down",
+	RegimeHighVolatility: "high_volatility",
+}
+
+func (r Regime) String() string {
+	if name, ok := regimeNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Regime(%d)", int(r))
+}
+
+// ParseRegime parses a regime name such as "trending_up"
+func ParseRegime(s string) (Regime, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	for _, r := range Regimes {
+		if r.String() == s {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown regime %q", s)
+}
+
+// RegimeSet is a set of regimes, such as the regimes not to trade in
+type RegimeSet []Regime
+
+// ParseRegimeSet parses comma separated regime names, or "none"
+func ParseRegimeSet(s string) (RegimeSet, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" || s == "none" {
+		return RegimeSet{}, nil
+	}
+	set := RegimeSet{}
+	for _, name := range strings.Split(s, ",") {
+		r, err := ParseRegime(name)
+		if err != nil {
+			return nil, err
+		}
+		if !set.Contains(r) {
+			set = append(set, r)
+		}
+	}
+	return set, nil
+}
+
+func (s RegimeSet) Contains(r Regime) bool {
+	for _, v := range s {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (s RegimeSet) String() string {
+	if len(s) == 0 {
+		return "none"
+	}
+	names := make([]string, len(s))
+	for i, r := range s {
+		names[i] = r.String()
+	}
+	return strings.Join(names, ",")
+}
+
+// EfficiencyRatio calculates the net change of each window over the total
+// distance travelled, from -1 for a straight fall to 1 for a straight rise
+func EfficiencyRatio(prices []float64, window int) []float64 {
+	out := make([]float64, len(prices))
+	moves := make([]float64, len(prices))
+	for i := 1; i < len(prices); i++ {
+		moves[i] = math.Abs(prices[i] - prices[i-1])
+	}
+	path := newRollingSum(window)
+	for i := 1; i < len(prices); i++ {
+		path.Push(moves[i])
+		if i >= window && path.Sum() > 0 {
+			out[i] = (prices[i] - prices[i-window]) / path.Sum()
+		}
+	}
+	return out
+}
+
+// ClassifyRegimes labels each candle from the volatility and trend of the
+// last window closes. A candle is high volatility when the log of its
+// realized volatility is more than volatilityThreshold standard deviations
+// above its mean over the lookback, otherwise trending when the absolute
+// efficiency ratio is at least trendThreshold, otherwise ranging. The
+// thresholds adapt to the instrument through the lookback, and every label
+// only depends on earlier candles. Candles before window + lookback are
+// ranging.
+func ClassifyRegimes(closes []float64, window, lookback int, trendThreshold, volatilityThreshold float64) []Regime {
+	regimes := make([]Regime, len(closes))
+
+	returns := LogReturns(closes)
+	volatility := make([]float64, len(closes))
+	moments := newRollingMoments(window)
+	for i := 1; i < len(closes); i++ {
+		moments.Push(returns[i])
+		if i >= window {
+			volatility[i] = math.Log(math.Sqrt(moments.Variance()) + 1e-12)
+		}
+	}
+	// z-scores of the log volatility from its first full window
+	surprise := make([]float64, len(closes))
+	if window < len(closes) {
+		copy(surprise[window:], ZScore(volatility[window:], lookback))
+	}
+	efficiency := EfficiencyRatio(closes, window)
+
+	for i := max(window+lookback-1, 0); i < len(closes); i++ {
+		switch {
+		case surprise[i] > volatilityThreshold:
+			regimes[i] = RegimeHighVolatility
+		case efficiency[i] >= trendThreshold:
+			regimes[i] = RegimeTrendingUp
+		case efficiency[i] <= -trendThreshold:
+			regimes[i] = RegimeTrendingDown
+		default:
+			regimes[i] = RegimeRanging
+		}
+	}
+	return regimes
+}
@@ -0,0 +1,44 @@
+package ta_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestClassifyRegimes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	window, lookback := 30, 300
+	closes := []float64{100}
+	step := func(drift, vol float64, n int) {
+		for range n {
+			closes = append(closes, closes[len(closes)-1]*math.Exp(drift+vol*r.NormFloat64()))
+		}
+	}
+	step(0, 0.001, 600)       // ranging
+	step(0.002, 0.0005, 100)  // trending up
+	step(-0.002, 0.0005, 100) // trending down
+	step(0, 0.01, 40)         // high volatility
+
+	regimes := ta.ClassifyRegimes(closes, window, lookback, 0.4, 2)
+	for i, want := range map[int]ta.Regime{
+		600: ta.RegimeRanging,
+		700: ta.RegimeTrendingUp,
+		800: ta.RegimeTrendingDown,
+		820: ta.RegimeHighVolatility,
+	} {
+		if regimes[i] != want {
+			t.Errorf("candle %d: got %s, want %s", i, regimes[i], want)
+		}
+	}
+
+	set, err := ta.ParseRegimeSet("high_volatility, ranging")
+	if err != nil || !set.Contains(ta.RegimeRanging) || set.Contains(ta.RegimeTrendingUp) || set.String() != "high_volatility,ranging" {
+		t.Errorf("ParseRegimeSet: got %v, %v", set, err)
+	}
+	if _, err := ta.ParseRegimeSet("sideways"); err == nil {
+		t.Errorf("ParseRegimeSet should reject unknown regimes")
+	}
+}
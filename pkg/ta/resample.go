@@ -0,0 +1,46 @@
+package ta
+
+import (
+	"math"
+	"time"
+)
+
+// Resample aggregates candles of the given interval into bars of the
+// anchor's period. A partial first bar, where the candles start part way
+// through a period, is skipped so the bars don't depend on where the
+// candles start. index[i] is the last bar complete at candle i, or -1, so
+// values computed on the bars can be used at candle i without looking
+// ahead. A bar is complete at its last candle, or at the first candle of
+// the next bar if its last candles are missing.
+func Resample(candles []Candle, anchor SessionAnchor, interval time.Duration) (bars []Candle, index []int) {
+	index = make([]int, len(candles))
+	complete := -1
+	skipping := false
+	var bucket time.Time
+
+	for i, c := range candles {
+		if start := anchor.Start(c.Timestamp); i == 0 || !start.Equal(bucket) {
+			if i > 0 && !skipping {
+				complete = len(bars) - 1
+			}
+			bucket = start
+			skipping = i == 0 && !start.Equal(c.Timestamp)
+			if !skipping {
+				bars = append(bars, Candle{Timestamp: start, Open: c.Open, High: math.Inf(-1), Low: math.Inf(1)})
+			}
+		}
+
+		if !skipping {
+			bar := &bars[len(bars)-1]
+			bar.High = math.Max(bar.High, c.High)
+			bar.Low = math.Min(bar.Low, c.Low)
+			bar.Close = c.Close
+			bar.Volume += c.Volume
+			if !c.Timestamp.Add(interval).Before(bucket.Add(anchor.Period)) {
+				complete = len(bars) - 1
+			}
+		}
+		index[i] = complete
+	}
+	return bars, index
+}
@@ -0,0 +1,45 @@
+package ta_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestResample(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 3, 0, 0, time.UTC)
+	candles := []ta.Candle{}
+	for i := 0; i < 40; i++ {
+		if i == 25 {
+			// a missing candle at the end of the 00:15 bar
+			continue
+		}
+		price := float64(100 + i)
+		candles = append(candles, ta.Candle{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Open:      price, High: price + 1, Low: price - 1, Close: price, Volume: 1,
+		})
+	}
+
+	bars, index := ta.Resample(candles, ta.SessionAnchor{Period: 15 * time.Minute}, time.Minute)
+	// 00:03-00:14 is a partial bar, leaving 00:15 (missing 00:28) and 00:30
+	if len(bars) != 2 {
+		t.Fatalf("expected 2 bars, got %d", len(bars))
+	}
+	if b := bars[0]; !b.Timestamp.Equal(start.Add(12*time.Minute)) || b.Open != 112 || b.Close != 126 || b.High != 127 || b.Low != 111 || b.Volume != 14 {
+		t.Errorf("unexpected first bar %+v", b)
+	}
+
+	for i, c := range candles {
+		// the first bar completes at its last candle, 00:29, and the second
+		// never does
+		expected := -1
+		if !c.Timestamp.Before(start.Add(26 * time.Minute)) {
+			expected = 0
+		}
+		if index[i] != expected {
+			t.Errorf("candle %s: expected bar %d, got %d", c.Timestamp.Format("15:04"), expected, index[i])
+		}
+	}
+}
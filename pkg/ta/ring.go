@@ -0,0 +1,292 @@
+package ta
+
+import (
+	"math"
+	"slices"
+)
+
+// ring is a fixed capacity FIFO buffer used by the streaming indicators
+type ring struct {
+	values []float64
+	head   int
+	count  int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{values: make([]float64, max(capacity, 1))}
+}
+
+// Push appends v, returning the evicted value once the ring is full
+func (r *ring) Push(v float64) (float64, bool) {
+	if r.count < len(r.values) {
+		r.values[(r.head+r.count)%len(r.values)] = v
+		r.count++
+		return 0, false
+	}
+	evicted := r.values[r.head]
+	r.values[r.head] = v
+	r.head = (r.head + 1) % len(r.values)
+	return evicted, true
+}
+
+// At returns the i-th oldest value in the ring
+func (r *ring) At(i int) float64 {
+	return r.values[(r.head+i)%len(r.values)]
+}
+
+// Last returns the most recently pushed value
+func (r *ring) Last() float64 {
+	return r.At(r.count - 1)
+}
+
+func (r *ring) Len() int {
+	return r.count
+}
+
+func (r *ring) Cap() int {
+	return len(r.values)
+}
+
+func (r *ring) Full() bool {
+	return r.count == len(r.values)
+}
+
+// rollingSum keeps the sum of the last n values in O(1) per push.
+//
+// NaN values are counted separately so that a single NaN only poisons the
+// windows containing it, matching the behaviour of the batch functions. The
+// sum is recomputed from the buffer once per full rotation so floating point
+// drift from the add/subtract updates stays bounded.
+type rollingSum struct {
+	ring      *ring
+	sum       float64
+	nans      int
+	nonzero   int
+	evictions int
+}
+
+func newRollingSum(window int) *rollingSum {
+	return &rollingSum{ring: newRing(window)}
+}
+
+func (s *rollingSum) Push(v float64) {
+	s.add(v, 1)
+	if evicted, ok := s.ring.Push(v); ok {
+		s.add(evicted, -1)
+		s.evictions++
+		if s.evictions == s.ring.Cap() {
+			s.evictions = 0
+			s.sum = 0
+			for i := range s.ring.Len() {
+				if v := s.ring.At(i); !math.IsNaN(v) {
+					s.sum += v
+				}
+			}
+		}
+	}
+}
+
+func (s *rollingSum) add(v float64, sign int) {
+	if math.IsNaN(v) {
+		s.nans += sign
+		return
+	}
+	if v != 0 {
+		s.nonzero += sign
+	}
+	s.sum += float64(sign) * v
+}
+
+func (s *rollingSum) Sum() float64 {
+	if s.nans > 0 {
+		return math.NaN()
+	}
+	if s.nonzero == 0 {
+		return 0
+	}
+	return s.sum
+}
+
+func (s *rollingSum) Full() bool {
+	return s.ring.Full()
+}
+
+// rollingMoments keeps the mean and population variance of the last n
+// values using Welford's algorithm, extended to remove the value leaving the
+// window. This avoids the catastrophic cancellation of sum-of-squares. Like
+// rollingSum the moments are recomputed from the buffer once per full
+// rotation, which keeps the drift of values far from zero bounded.
+type rollingMoments struct {
+	ring      *ring
+	mean      float64
+	m2        float64
+	evictions int
+}
+
+func newRollingMoments(window int) *rollingMoments {
+	return &rollingMoments{ring: newRing(window)}
+}
+
+func (m *rollingMoments) Push(v float64) {
+	evicted, ok := m.ring.Push(v)
+	if !ok {
+		delta := v - m.mean
+		m.mean += delta / float64(m.ring.Len())
+		m.m2 += delta * (v - m.mean)
+		return
+	}
+	mean := m.mean + (v-evicted)/float64(m.ring.Cap())
+	m.m2 += (v - evicted) * (v - mean + evicted - m.mean)
+	m.mean = mean
+	if m.m2 < 0 {
+		m.m2 = 0
+	}
+
+	m.evictions++
+	if m.evictions == m.ring.Cap() {
+		m.evictions = 0
+		m.mean, m.m2 = 0, 0
+		for i := range m.ring.Len() {
+			m.mean += m.ring.At(i)
+		}
+		m.mean /= float64(m.ring.Len())
+		for i := range m.ring.Len() {
+			d := m.ring.At(i) - m.mean
+			m.m2 += d * d
+		}
+	}
+}
+
+func (m *rollingMoments) Mean() float64 {
+	return m.mean
+}
+
+// Variance returns the population variance of the window
+func (m *rollingMoments) Variance() float64 {
+	if m.ring.Len() == 0 {
+		return 0
+	}
+	return m.m2 / float64(m.ring.Len())
+}
+
+func (m *rollingMoments) Full() bool {
+	return m.ring.Full()
+}
+
+// monotonicDeque tracks the minimum or maximum of the last n values in
+// amortised O(1) per push.
+type monotonicDeque struct {
+	window  int
+	n       int
+	indices []int
+	values  []float64
+	keep    func(back, v float64) bool
+}
+
+func newMinDeque(window int) *monotonicDeque {
+	return &monotonicDeque{window: max(window, 1), keep: func(back, v float64) bool { return back < v }}
+}
+
+func newMaxDeque(window int) *monotonicDeque {
+	return &monotonicDeque{window: max(window, 1), keep: func(back, v float64) bool { return back > v }}
+}
+
+// Push adds v and returns the extreme of the current window
+func (d *monotonicDeque) Push(v float64) float64 {
+	for len(d.values) > 0 && !d.keep(d.values[len(d.values)-1], v) {
+		d.values = d.values[:len(d.values)-1]
+		d.indices = d.indices[:len(d.indices)-1]
+	}
+	d.values = append(d.values, v)
+	d.indices = append(d.indices, d.n)
+	for d.indices[0] <= d.n-d.window {
+		d.values = d.values[1:]
+		d.indices = d.indices[1:]
+	}
+	d.n++
+	return d.values[0]
+}
+
+// rankedSums keeps the count and sum of a sliding window of values, indexed
+// by each value's rank within the whole series, so the count and sum of the
+// values below any threshold are available in O(log n). Sums are held in
+// fixed point, scaled so a full window can't overflow, which makes removing
+// a value cancel its addition exactly however long the series is.
+type rankedSums struct {
+	sorted []float64
+	scale  float64
+	counts []int
+	sums   []int64
+	total  int64
+	n      int
+	nans   int
+}
+
+func newRankedSums(values []float64, window int) *rankedSums {
+	sorted := make([]float64, 0, len(values))
+	maxAbs := 0.0
+	for _, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			sorted = append(sorted, v)
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+	}
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	scale := 1.0
+	if maxAbs > 0 {
+		scale = math.Exp2(61) / (maxAbs * float64(max(window, 1)+1))
+	}
+
+	return &rankedSums{
+		sorted: sorted,
+		scale:  scale,
+		counts: make([]int, len(sorted)+1),
+		sums:   make([]int64, len(sorted)+1),
+	}
+}
+
+func (r *rankedSums) update(v float64, sign int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		r.nans += sign
+		return
+	}
+	fixed := int64(math.Round(v*r.scale)) * int64(sign)
+	r.total += fixed
+	r.n += sign
+	i, _ := slices.BinarySearch(r.sorted, v)
+	for i++; i < len(r.counts); i += i & -i {
+		r.counts[i] += sign
+		r.sums[i] += fixed
+	}
+}
+
+// Add adds v, which must be one of the values the window was created with
+func (r *rankedSums) Add(v float64) {
+	r.update(v, 1)
+}
+
+// Remove removes a value previously added
+func (r *rankedSums) Remove(v float64) {
+	r.update(v, -1)
+}
+
+// Sum returns the sum of the window, NaN if it holds a NaN or infinity
+func (r *rankedSums) Sum() float64 {
+	if r.nans > 0 {
+		return math.NaN()
+	}
+	return float64(r.total) / r.scale
+}
+
+// Below returns the count and sum of the values less than threshold
+func (r *rankedSums) Below(threshold float64) (int, float64) {
+	count, sum := 0, int64(0)
+	i, _ := slices.BinarySearch(r.sorted, threshold)
+	for ; i > 0; i -= i & -i {
+		count += r.counts[i]
+		sum += r.sums[i]
+	}
+	return count, float64(sum) / r.scale
+}
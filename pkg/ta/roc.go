@@ -7,3 +7,16 @@ func RateOfChange(prices []float64, period int) []float64 {
 	}
 	return roc
 }
+
+// RateOfChangeStream is the streaming equivalent of RateOfChange
+type RateOfChangeStream struct {
+	changes *PriceChangesStream
+}
+
+func NewRateOfChange(period int) *RateOfChangeStream {
+	return &RateOfChangeStream{changes: NewPriceChanges(period)}
+}
+
+func (s *RateOfChangeStream) Update(c Candle) float64 {
+	return s.changes.Update(c) * 100
+}
@@ -0,0 +1,244 @@
+package ta_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+// The naive implementations below are the original O(n·w) versions of the
+// batch indicators, kept as the reference for the rolling implementations.
+// naiveCCI recomputes the mean of each window rather than carrying the
+// running sum the original version did.
+
+func naiveMovingAverage(prices []float64, window int) []float64 {
+	ma := make([]float64, len(prices))
+	for i := range prices {
+		if i < window {
+			ma[i] = 0
+			continue
+		}
+		sum := 0.0
+		for j := 0; j < window; j++ {
+			sum += prices[i-j]
+		}
+		ma[i] = sum / float64(window)
+	}
+	return ma
+}
+
+func naiveRSI(prices []float64, window int) []float64 {
+	rsi := make([]float64, len(prices))
+	gains, losses := make([]float64, len(prices)), make([]float64, len(prices))
+	for i := 1; i < len(prices); i++ {
+		change := prices[i] - prices[i-1]
+		if change > 0 {
+			gains[i] = change
+		} else {
+			losses[i] = -change
+		}
+	}
+
+	avgGains := naiveMovingAverage(gains, window)
+	avgLosses := naiveMovingAverage(losses, window)
+
+	for i := window; i < len(prices); i++ {
+		if avgLosses[i] == 0 {
+			rsi[i] = 100
+		} else {
+			rs := avgGains[i] / avgLosses[i]
+			rsi[i] = 100 - (100 / (1 + rs))
+		}
+	}
+	return rsi
+}
+
+func naiveBollingerBands(prices []float64, window int, multiplier float64) ([]float64, []float64, []float64) {
+	ma := naiveMovingAverage(prices, window)
+	upper, lower := make([]float64, len(prices)), make([]float64, len(prices))
+
+	for i := window; i < len(prices); i++ {
+		mean := 0.0
+		for j := 0; j < window; j++ {
+			mean += prices[i-j]
+		}
+		mean /= float64(window)
+		sum := 0.0
+		for j := i - window + 1; j <= i; j++ {
+			sum += math.Pow(prices[j]-mean, 2)
+		}
+		stdDev := math.Sqrt(sum / float64(window))
+		upper[i] = ma[i] + multiplier*stdDev
+		lower[i] = ma[i] - multiplier*stdDev
+	}
+	return ma, upper, lower
+}
+
+func naiveStochasticOscillator(closes, lows, highs []float64, window int) ([]float64, []float64) {
+	kValues := make([]float64, len(closes))
+
+	for i := range closes {
+		if i < window {
+			continue
+		}
+		low, high := lows[i], highs[i]
+		for j := i - window + 1; j <= i; j++ {
+			low = math.Min(low, lows[j])
+			high = math.Max(high, highs[j])
+		}
+		kValues[i] = 100 * (closes[i] - low) / (high - low)
+	}
+
+	return kValues, naiveMovingAverage(kValues, 3)
+}
+
+func naiveWilliamsR(highs, lows, closes []float64, period int) []float64 {
+	williamsr := make([]float64, len(closes))
+	for i := period; i < len(closes); i++ {
+		highest := highs[i]
+		lowest := lows[i]
+		for j := i - period + 1; j <= i; j++ {
+			if highs[j] > highest {
+				highest = highs[j]
+			}
+			if lows[j] < lowest {
+				lowest = lows[j]
+			}
+		}
+		if highest != lowest {
+			williamsr[i] = ((highest - closes[i]) / (highest - lowest)) * -100
+		}
+	}
+	return williamsr
+}
+
+func naiveChaikinMoneyFlow(highs, lows, closes, volumes []float64, period int) []float64 {
+	cmf := make([]float64, len(closes))
+	for i := period; i < len(closes); i++ {
+		sumMF := 0.0
+		sumVol := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			mf := ((closes[j] - lows[j]) - (highs[j] - closes[j])) / (highs[j] - lows[j]) * volumes[j]
+			sumMF += mf
+			sumVol += volumes[j]
+		}
+		if sumVol != 0 {
+			cmf[i] = sumMF / sumVol
+		}
+	}
+	return cmf
+}
+
+func naiveMoneyFlowIndex(highs, lows, closes, volumes []float64, period int) []float64 {
+	mfi := make([]float64, len(closes))
+	for i := period; i < len(closes); i++ {
+		posFlow := 0.0
+		negFlow := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			if closes[j] > closes[j-1] {
+				posFlow += closes[j] * volumes[j]
+			} else {
+				negFlow += closes[j] * volumes[j]
+			}
+		}
+		if negFlow != 0 {
+			mfi[i] = 100 - (100 / (1 + posFlow/negFlow))
+		} else {
+			mfi[i] = 100
+		}
+	}
+	return mfi
+}
+
+func naiveCCI(highs, lows, closes []float64, period int) []float64 {
+	cci := make([]float64, len(closes))
+	for i := period; i < len(closes); i++ {
+		tp := (highs[i] + lows[i] + closes[i]) / 3
+		mean := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			mean += (highs[j] + lows[j] + closes[j]) / 3
+		}
+		mean /= float64(period)
+		sum := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			sum += math.Abs((highs[j]+lows[j]+closes[j])/3 - mean)
+		}
+		if meanDeviation := sum / float64(period); meanDeviation != 0 {
+			cci[i] = (tp - mean) / (0.015 * meanDeviation)
+		}
+	}
+	return cci
+}
+
+// assertNearSeries compares series with an absolute tolerance scaled to the
+// indicator's range, as the naive sums carry their own rounding error
+func assertNearSeries(t *testing.T, name string, got, want []float64, tolerance float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: length %d, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.IsNaN(want[i]) || math.IsInf(want[i], 0) {
+			if !almostEqual(got[i], want[i]) {
+				t.Fatalf("%s: index %d got %v, want %v", name, i, got[i], want[i])
+			}
+			continue
+		}
+		if math.Abs(got[i]-want[i]) > tolerance*math.Max(1, math.Abs(want[i])) {
+			t.Fatalf("%s: index %d got %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func TestRollingMatchesNaive(t *testing.T) {
+	_, highs, lows, closes, volumes := columns(randomCandles(20000, 3))
+
+	for _, window := range []int{1, 3, 14, 50, 200} {
+		assertSeries(t, "MovingAverage", ta.MovingAverage(closes, window), naiveMovingAverage(closes, window))
+		assertSeries(t, "RSI", ta.RSI(closes, window, ta.SmoothingSimple), naiveRSI(closes, window))
+
+		ma, upper, lower := ta.BollingerBands(closes, window, 2, ta.SmoothingSimple)
+		wantMA, wantUpper, wantLower := naiveBollingerBands(closes, window, 2)
+		assertSeries(t, "BollingerBands middle", ma, wantMA)
+		assertSeries(t, "BollingerBands upper", upper, wantUpper)
+		assertSeries(t, "BollingerBands lower", lower, wantLower)
+
+		k, d := ta.StochasticOscillator(closes, lows, highs, window)
+		wantK, wantD := naiveStochasticOscillator(closes, lows, highs, window)
+		assertSeries(t, "StochasticOscillator %K", k, wantK)
+		assertSeries(t, "StochasticOscillator %D", d, wantD)
+
+		assertSeries(t, "WilliamsR", ta.WilliamsR(highs, lows, closes, window), naiveWilliamsR(highs, lows, closes, window))
+		assertSeries(t, "ChaikinMoneyFlow", ta.ChaikinMoneyFlow(highs, lows, closes, volumes, window), naiveChaikinMoneyFlow(highs, lows, closes, volumes, window))
+		assertSeries(t, "MoneyFlowIndex", ta.MoneyFlowIndex(highs, lows, closes, volumes, window), naiveMoneyFlowIndex(highs, lows, closes, volumes, window))
+
+		if window > 1 {
+			assertNearSeries(t, "CCI", ta.CCI(highs, lows, closes, window), naiveCCI(highs, lows, closes, window), 1e-8)
+		}
+	}
+}
+
+// A long series with a large offset stresses the drift of the running sums
+func TestRollingDrift(t *testing.T) {
+	candles := randomCandles(200000, 4)
+	for i := range candles {
+		candles[i].High += 1e4
+		candles[i].Low += 1e4
+		candles[i].Close += 1e4
+	}
+	_, highs, lows, closes, _ := columns(candles)
+	n := len(closes)
+
+	tail := func(values []float64) []float64 {
+		return values[n-1000:]
+	}
+
+	assertSeries(t, "MovingAverage", tail(ta.MovingAverage(closes, 20)), tail(naiveMovingAverage(closes, 20)))
+
+	_, upper, _ := ta.BollingerBands(closes, 20, 2, ta.SmoothingSimple)
+	_, wantUpper, _ := naiveBollingerBands(closes, 20, 2)
+	assertSeries(t, "BollingerBands upper", tail(upper), tail(wantUpper))
+
+	assertNearSeries(t, "CCI", tail(ta.CCI(highs, lows, closes, 20)), tail(naiveCCI(highs, lows, closes, 20)), 1e-6)
+}
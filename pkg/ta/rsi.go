@@ -1,28 +1,63 @@
 package ta
 
-func RSI(prices []float64, window int) []float64 {
+import "math"
+
+// RSI calculates the relative strength index, averaging gains and losses with
+// the given smoothing. SmoothingWilder gives the classic Wilder RSI.
+func RSI(prices []float64, window int, smoothing Smoothing) []float64 {
 	rsi := make([]float64, len(prices))
-	for i := range prices {
-		if i < window {
-			continue
-		}
-		gains, losses := 0.0, 0.0
-		for j := 0; j < window; j++ {
-			change := prices[i-j] - prices[i-j-1]
-			if change > 0 {
-				gains += change
-			} else {
-				losses -= change
-			}
+	gains, losses := make([]float64, len(prices)), make([]float64, len(prices))
+	for i := 1; i < len(prices); i++ {
+		change := prices[i] - prices[i-1]
+		if change > 0 {
+			gains[i] = change
+		} else {
+			losses[i] = -change
 		}
-		avgGain := gains / float64(window)
-		avgLoss := losses / float64(window)
-		if avgLoss == 0 {
+	}
+
+	avgGains, first := smooth(gains, window, smoothing, 1)
+	avgLosses, _ := smooth(losses, window, smoothing, 1)
+
+	for i := first; i < len(prices); i++ {
+		if avgLosses[i] == 0 {
 			rsi[i] = 100
 		} else {
-			rs := avgGain / avgLoss
+			rs := avgGains[i] / avgLosses[i]
 			rsi[i] = 100 - (100 / (1 + rs))
 		}
 	}
 	return rsi
 }
+
+// RSIStream is the streaming equivalent of RSI
+type RSIStream struct {
+	n      int
+	prev   float64
+	gains  Smoother
+	losses Smoother
+}
+
+func NewRSI(window int, smoothing Smoothing) *RSIStream {
+	return &RSIStream{gains: newSmoother(window, smoothing, 1), losses: newSmoother(window, smoothing, 1)}
+}
+
+func (s *RSIStream) Update(c Candle) float64 {
+	change := 0.0
+	if s.n > 0 {
+		change = c.Close - s.prev
+	}
+	s.n++
+	s.prev = c.Close
+
+	avgGain := s.gains.Update(math.Max(change, 0))
+	avgLoss := s.losses.Update(math.Max(-change, 0))
+	if !s.gains.Ready() {
+		return 0
+	}
+	if avgLoss == 0 {
+		return 100
+	}
+	rs := avgGain / avgLoss
+	return 100 - (100 / (1 + rs))
+}
@@ -0,0 +1,51 @@
+package ta
+
+import "math"
+
+// ParabolicSAR calculates Wilder's parabolic stop and reverse. The second
+// slice holds the trend direction, 1 for long and -1 for short.
+func ParabolicSAR(highs, lows []float64, step, maxStep float64) ([]float64, []float64) {
+	sar, trend := make([]float64, len(highs)), make([]float64, len(highs))
+	if len(highs) < 2 {
+		return sar, trend
+	}
+
+	long := highs[1]+lows[1] >= highs[0]+lows[0]
+	af := step
+	var ep float64
+	if long {
+		ep = math.Max(highs[0], highs[1])
+		sar[1], trend[1] = lows[0], 1
+	} else {
+		ep = math.Min(lows[0], lows[1])
+		sar[1], trend[1] = highs[0], -1
+	}
+
+	for i := 2; i < len(highs); i++ {
+		s := sar[i-1] + af*(ep-sar[i-1])
+		if long {
+			s = math.Min(s, math.Min(lows[i-1], lows[i-2]))
+			if lows[i] < s {
+				long, s, ep, af = false, ep, lows[i], step
+			} else if highs[i] > ep {
+				ep, af = highs[i], math.Min(af+step, maxStep)
+			}
+		} else {
+			s = math.Max(s, math.Max(highs[i-1], highs[i-2]))
+			if highs[i] > s {
+				long, s, ep, af = true, ep, highs[i], step
+			} else if lows[i] < ep {
+				ep, af = lows[i], math.Min(af+step, maxStep)
+			}
+		}
+
+		sar[i] = s
+		if long {
+			trend[i] = 1
+		} else {
+			trend[i] = -1
+		}
+	}
+
+	return sar, trend
+}
@@ -0,0 +1,21 @@
+package ta
+
+import "math"
+
+// Series is an indicator series with a mask of the values that are valid.
+// The batch functions fill their warm-up with zeros, which look like real
+// values, so a Series marks them, along with any NaN or infinite results,
+// as invalid.
+type Series struct {
+	Values []float64
+	Valid  []bool
+}
+
+// NewSeries wraps values whose first warmup entries are still warming up
+func NewSeries(values []float64, warmup int) Series {
+	valid := make([]bool, len(values))
+	for i, v := range values {
+		valid[i] = i >= warmup && !math.IsNaN(v) && !math.IsInf(v, 0)
+	}
+	return Series{Values: values, Valid: valid}
+}
@@ -0,0 +1,25 @@
+package ta_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestSeries(t *testing.T) {
+	s := ta.NewSeries([]float64{0, 0, 1, math.NaN(), math.Inf(1), 2}, 2)
+
+	want := []bool{false, false, true, false, false, true}
+	for i := range want {
+		if s.Valid[i] != want[i] {
+			t.Fatalf("index %d valid %t, want %t", i, s.Valid[i], want[i])
+		}
+	}
+
+	for i, valid := range ta.NewSeries([]float64{1, 2}, 5).Valid {
+		if valid {
+			t.Fatalf("index %d valid, a series still warming up has no valid values", i)
+		}
+	}
+}
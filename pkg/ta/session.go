@@ -0,0 +1,121 @@
+package ta
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// SessionAnchor splits time into sessions of a fixed period, starting at
+// Offset past UTC midnight of the first day of the calendar (a Monday), so
+// a 24h period starts every day at 00:00 UTC and a 168h period every Monday.
+type SessionAnchor struct {
+	Period time.Duration
+	Offset time.Duration
+}
+
+var (
+	SessionAnchorDaily  = SessionAnchor{Period: 24 * time.Hour}
+	SessionAnchorWeekly = SessionAnchor{Period: 7 * 24 * time.Hour}
+)
+
+// ParseSessionAnchor parses "daily", "weekly" or a custom period with an
+// optional offset, e.g. "8h" or "24h@13h30m"
+func ParseSessionAnchor(s string) (SessionAnchor, error) {
+	switch strings.ToLower(s) {
+	case "daily":
+		return SessionAnchorDaily, nil
+	case "weekly":
+		return SessionAnchorWeekly, nil
+	}
+
+	period, offset, _ := strings.Cut(s, "@")
+
+	var anchor SessionAnchor
+	var err error
+	if anchor.Period, err = time.ParseDuration(period); err != nil {
+		return SessionAnchor{}, fmt.Errorf("invalid session anchor %q: %w", s, err)
+	}
+	if anchor.Period <= 0 {
+		return SessionAnchor{}, fmt.Errorf("invalid session anchor %q: period must be positive", s)
+	}
+	if offset != "" {
+		if anchor.Offset, err = time.ParseDuration(offset); err != nil {
+			return SessionAnchor{}, fmt.Errorf("invalid session anchor %q: %w", s, err)
+		}
+	}
+	return anchor, nil
+}
+
+func (a SessionAnchor) String() string {
+	switch a {
+	case SessionAnchorDaily:
+		return "daily"
+	case SessionAnchorWeekly:
+		return "weekly"
+	}
+	if a.Offset == 0 {
+		return a.Period.String()
+	}
+	return fmt.Sprintf("%s@%s", a.Period, a.Offset)
+}
+
+// Start returns the start of the session containing t
+func (a SessionAnchor) Start(t time.Time) time.Time {
+	return t.UTC().Add(-a.Offset).Truncate(a.Period).Add(a.Offset)
+}
+
+// AnchoredVWAP calculates the volume weighted average of the typical price,
+// reset at the start of every session, with bands at multiplier volume
+// weighted standard deviations. Unlike VWAP the values don't depend on where
+// the slice of candles starts, other than in the first session.
+func AnchoredVWAP(candles []Candle, anchor SessionAnchor, multiplier float64) ([]float64, []float64, []float64) {
+	vwap := make([]float64, len(candles))
+	upper := make([]float64, len(candles))
+	lower := make([]float64, len(candles))
+
+	stream := NewAnchoredVWAP(anchor, multiplier)
+	for i, c := range candles {
+		vwap[i], upper[i], lower[i] = stream.Update(c)
+	}
+	return vwap, upper, lower
+}
+
+// AnchoredVWAPStream is the streaming equivalent of AnchoredVWAP
+type AnchoredVWAPStream struct {
+	anchor     SessionAnchor
+	multiplier float64
+
+	session time.Time
+	volume  float64
+	mean    float64
+	m2      float64
+}
+
+func NewAnchoredVWAP(anchor SessionAnchor, multiplier float64) *AnchoredVWAPStream {
+	return &AnchoredVWAPStream{anchor: anchor, multiplier: multiplier}
+}
+
+func (s *AnchoredVWAPStream) Update(c Candle) (float64, float64, float64) {
+	if session := s.anchor.Start(c.Timestamp); !session.Equal(s.session) {
+		s.session = session
+		s.volume, s.mean, s.m2 = 0, 0, 0
+	}
+
+	// weighted incremental mean and variance (West, 1979)
+	price := (c.High + c.Low + c.Close) / 3
+	if c.Volume > 0 {
+		s.volume += c.Volume
+		delta := price - s.mean
+		s.mean += delta * c.Volume / s.volume
+		s.m2 += c.Volume * delta * (price - s.mean)
+	}
+
+	if s.volume == 0 {
+		return 0, 0, 0
+	}
+
+	band := s.multiplier * math.Sqrt(math.Max(0, s.m2/s.volume))
+	return s.mean, s.mean + band, s.mean - band
+}
@@ -0,0 +1,109 @@
+package ta_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestParseSessionAnchor(t *testing.T) {
+	cases := map[string]ta.SessionAnchor{
+		"daily":      ta.SessionAnchorDaily,
+		"weekly":     ta.SessionAnchorWeekly,
+		"8h":         {Period: 8 * time.Hour},
+		"24h@13h30m": {Period: 24 * time.Hour, Offset: 13*time.Hour + 30*time.Minute},
+	}
+	for s, want := range cases {
+		got, err := ta.ParseSessionAnchor(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if got != want {
+			t.Fatalf("%s: got %+v, want %+v", s, got, want)
+		}
+		if again, _ := ta.ParseSessionAnchor(got.String()); again != got {
+			t.Fatalf("%s: %q doesn't round trip", s, got.String())
+		}
+	}
+	for _, s := range []string{"", "hourly", "-1h", "1h@x"} {
+		if _, err := ta.ParseSessionAnchor(s); err == nil {
+			t.Fatalf("%q: expected an error", s)
+		}
+	}
+
+	// 2025-01-01 is a Wednesday
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if got := ta.SessionAnchorWeekly.Start(now); !got.Equal(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("weekly session starts %v", got)
+	}
+	if got := (ta.SessionAnchor{Period: 24 * time.Hour, Offset: 13 * time.Hour}).Start(now); !got.Equal(time.Date(2024, 12, 31, 13, 0, 0, 0, time.UTC)) {
+		t.Fatalf("offset session starts %v", got)
+	}
+}
+
+func TestAnchoredVWAP(t *testing.T) {
+	candles := randomCandles(3*1440, 5)
+	vwap, upper, lower := ta.AnchoredVWAP(candles, ta.SessionAnchorDaily, 2)
+
+	// naive volume weighted mean and deviation from the start of each day
+	start := 0
+	for i, c := range candles {
+		if c.Timestamp.Hour() == 0 && c.Timestamp.Minute() == 0 {
+			start = i
+		}
+		volume, value := 0.0, 0.0
+		for _, s := range candles[start : i+1] {
+			volume += s.Volume
+			value += s.Volume * (s.High + s.Low + s.Close) / 3
+		}
+		mean := value / volume
+		variance := 0.0
+		for _, s := range candles[start : i+1] {
+			d := (s.High+s.Low+s.Close)/3 - mean
+			variance += s.Volume * d * d
+		}
+		band := 2 * math.Sqrt(variance/volume)
+
+		if !almostEqual(vwap[i], mean) || !almostEqual(upper[i], mean+band) || !almostEqual(lower[i], mean-band) {
+			t.Fatalf("index %d: got %v/%v/%v, want %v/%v/%v", i, vwap[i], upper[i], lower[i], mean, mean+band, mean-band)
+		}
+	}
+
+	// values after the first session don't depend on where the slice starts
+	offset, _, _ := ta.AnchoredVWAP(candles[700:], ta.SessionAnchorDaily, 2)
+	assertSeries(t, "offset", offset[1440-700:], vwap[1440:])
+}
+
+func TestVolumeProfile(t *testing.T) {
+	candle := func(price, volume float64) ta.Candle {
+		return ta.Candle{Open: price, High: price, Low: price, Close: price, Volume: volume}
+	}
+	candles := []ta.Candle{
+		candle(100, 10),
+		candle(101, 50),
+		candle(102, 20),
+		candle(103, 15),
+		candle(104, 5),
+		candle(90, 1000), // falls out of the window
+	}
+
+	poc, vah, val := ta.VolumeProfile(candles[:5], 5, 0.001, 0.7)
+	if poc[3] != 0 {
+		t.Fatalf("profile should be zero before the window fills")
+	}
+	if math.Abs(poc[4]-101) > 0.1 {
+		t.Fatalf("point of control %v, want 101", poc[4])
+	}
+	// 101 + 102 hold 70% of the volume
+	if val[4] > 101 || val[4] < 100.5 || vah[4] < 102 || vah[4] > 102.5 {
+		t.Fatalf("value area %v-%v, want 101-102", val[4], vah[4])
+	}
+
+	reversed := append([]ta.Candle{candles[5]}, candles[:5]...)
+	poc, _, _ = ta.VolumeProfile(reversed, 5, 0.001, 0.7)
+	if math.Abs(poc[5]-101) > 0.1 {
+		t.Fatalf("point of control %v after rolling, want 101", poc[5])
+	}
+}
@@ -0,0 +1,311 @@
+package ta
+
+import (
+	"fmt"
+	"math"
+)
+
+// Smoothing selects the moving average used by the smoothed indicators
+type Smoothing string
+
+const (
+	SmoothingSimple      Smoothing = "sma"
+	SmoothingExponential Smoothing = "ema"
+	SmoothingWeighted    Smoothing = "wma"
+	SmoothingHull        Smoothing = "hma"
+	SmoothingDouble      Smoothing = "dema"
+	SmoothingTriple      Smoothing = "tema"
+	SmoothingWilder      Smoothing = "wilder"
+)
+
+var Smoothings = []Smoothing{
+	SmoothingSimple,
+	SmoothingExponential,
+	SmoothingWeighted,
+	SmoothingHull,
+	SmoothingDouble,
+	SmoothingTriple,
+	SmoothingWilder,
+}
+
+func ParseSmoothing(s string) (Smoothing, error) {
+	for _, smoothing := range Smoothings {
+		if string(smoothing) == s {
+			return smoothing, nil
+		}
+	}
+	return "", fmt.Errorf("unknown smoothing: %s", s)
+}
+
+// Smoother is a streaming moving average
+type Smoother interface {
+	Update(v float64) float64
+	Ready() bool
+}
+
+func NewSmoother(window int, smoothing Smoothing) Smoother {
+	return newSmoother(window, smoothing, 0)
+}
+
+// newSmoother creates a smoother whose input only becomes valid after offset
+// values. Simple moving averages ignore the offset to keep the historical
+// behaviour of MovingAverage, which averages over the zero warm-up values.
+func newSmoother(window int, smoothing Smoothing, offset int) Smoother {
+	switch smoothing {
+	case SmoothingExponential:
+		return newEMAStream(window, 2/float64(window+1), offset)
+	case SmoothingWilder:
+		return newEMAStream(window, 1/float64(window), offset)
+	case SmoothingWeighted:
+		return newWMAStream(window, offset)
+	case SmoothingHull:
+		return newHMAStream(window, offset)
+	case SmoothingDouble:
+		return newDEMAStream(window, offset)
+	case SmoothingTriple:
+		return newTEMAStream(window, offset)
+	default:
+		return NewMovingAverage(window)
+	}
+}
+
+// smooth applies the smoothing to values whose first valid index is offset,
+// returning the smoothed series and its first valid index
+func smooth(values []float64, window int, smoothing Smoothing, offset int) ([]float64, int) {
+	if smoothing == SmoothingSimple || smoothing == "" {
+		return MovingAverage(values, window), max(window, offset+window-1)
+	}
+
+	s := newSmoother(window, smoothing, offset)
+	out := make([]float64, len(values))
+	first := len(values)
+	for i, v := range values {
+		out[i] = s.Update(v)
+		if first == len(values) && s.Ready() {
+			first = i
+		}
+	}
+	return out, first
+}
+
+func Smooth(values []float64, window int, smoothing Smoothing) []float64 {
+	out, _ := smooth(values, window, smoothing, 0)
+	return out
+}
+
+func ExponentialMovingAverage(values []float64, window int) []float64 {
+	return Smooth(values, window, SmoothingExponential)
+}
+
+func WeightedMovingAverage(values []float64, window int) []float64 {
+	return Smooth(values, window, SmoothingWeighted)
+}
+
+func HullMovingAverage(values []float64, window int) []float64 {
+	return Smooth(values, window, SmoothingHull)
+}
+
+func DoubleExponentialMovingAverage(values []float64, window int) []float64 {
+	return Smooth(values, window, SmoothingDouble)
+}
+
+func TripleExponentialMovingAverage(values []float64, window int) []float64 {
+	return Smooth(values, window, SmoothingTriple)
+}
+
+// WilderMovingAverage is the running moving average used by Wilder for RSI
+// and ATR, an exponential average with alpha = 1/window
+func WilderMovingAverage(values []float64, window int) []float64 {
+	return Smooth(values, window, SmoothingWilder)
+}
+
+// emaStream is an exponential average seeded with the simple average of the
+// first window values
+type emaStream struct {
+	window int
+	alpha  float64
+	skip   int
+	n      int
+	value  float64
+}
+
+func newEMAStream(window int, alpha float64, offset int) *emaStream {
+	return &emaStream{window: max(window, 1), alpha: alpha, skip: offset}
+}
+
+func (s *emaStream) Update(v float64) float64 {
+	if s.skip > 0 {
+		s.skip--
+		return 0
+	}
+	s.n++
+	if s.n < s.window {
+		s.value += v
+		return 0
+	}
+	if s.n == s.window {
+		s.value = (s.value + v) / float64(s.window)
+		return s.value
+	}
+	s.value = s.alpha*v + (1-s.alpha)*s.value
+	return s.value
+}
+
+func (s *emaStream) Ready() bool {
+	return s.n >= s.window
+}
+
+// wmaStream is a linearly weighted average, the newest value having weight
+// window and the oldest weight 1
+type wmaStream struct {
+	window    int
+	skip      int
+	sum       *rollingSum
+	numerator float64
+	prevSum   float64
+	updates   int
+}
+
+func newWMAStream(window int, offset int) *wmaStream {
+	return &wmaStream{window: max(window, 1), skip: offset, sum: newRollingSum(max(window, 1))}
+}
+
+func (s *wmaStream) Update(v float64) float64 {
+	if s.skip > 0 {
+		s.skip--
+		return 0
+	}
+	if !s.sum.Full() {
+		s.numerator += float64(s.sum.ring.Len()+1) * v
+	} else {
+		s.numerator += float64(s.window)*v - s.prevSum
+	}
+	s.sum.Push(v)
+	s.prevSum = s.sum.Sum()
+	if !s.sum.Full() {
+		return 0
+	}
+
+	// recompute the numerator once per rotation to bound floating point drift
+	s.updates++
+	if s.updates == s.window {
+		s.updates = 0
+		s.numerator = 0
+		for i := range s.window {
+			s.numerator += float64(i+1) * s.sum.ring.At(i)
+		}
+	}
+	return s.numerator / float64(s.window*(s.window+1)/2)
+}
+
+func (s *wmaStream) Ready() bool {
+	return s.sum.Full()
+}
+
+// hmaStream is Alan Hull's moving average,
+// WMA(2*WMA(n/2) - WMA(n), sqrt(n))
+type hmaStream struct {
+	half  *wmaStream
+	full  *wmaStream
+	outer *wmaStream
+}
+
+func newHMAStream(window int, offset int) *hmaStream {
+	return &hmaStream{
+		half:  newWMAStream(max(window/2, 1), offset),
+		full:  newWMAStream(window, offset),
+		outer: newWMAStream(int(math.Sqrt(float64(window))), 0),
+	}
+}
+
+func (s *hmaStream) Update(v float64) float64 {
+	half := s.half.Update(v)
+	full := s.full.Update(v)
+	if !s.full.Ready() || !s.half.Ready() {
+		return 0
+	}
+	return s.outer.Update(2*half - full)
+}
+
+func (s *hmaStream) Ready() bool {
+	return s.outer.Ready()
+}
+
+type demaStream struct {
+	e1 *emaStream
+	e2 *emaStream
+}
+
+func newDEMAStream(window int, offset int) *demaStream {
+	alpha := 2 / float64(window+1)
+	return &demaStream{e1: newEMAStream(window, alpha, offset), e2: newEMAStream(window, alpha, 0)}
+}
+
+func (s *demaStream) Update(v float64) float64 {
+	e1 := s.e1.Update(v)
+	if !s.e1.Ready() {
+		return 0
+	}
+	e2 := s.e2.Update(e1)
+	if !s.e2.Ready() {
+		return 0
+	}
+	return 2*e1 - e2
+}
+
+func (s *demaStream) Ready() bool {
+	return s.e2.Ready()
+}
+
+type temaStream struct {
+	e1 *emaStream
+	e2 *emaStream
+	e3 *emaStream
+}
+
+func newTEMAStream(window int, offset int) *temaStream {
+	alpha := 2 / float64(window+1)
+	return &temaStream{
+		e1: newEMAStream(window, alpha, offset),
+		e2: newEMAStream(window, alpha, 0),
+		e3: newEMAStream(window, alpha, 0),
+	}
+}
+
+func (s *temaStream) Update(v float64) float64 {
+	e1 := s.e1.Update(v)
+	if !s.e1.Ready() {
+		return 0
+	}
+	e2 := s.e2.Update(e1)
+	if !s.e2.Ready() {
+		return 0
+	}
+	e3 := s.e3.Update(e2)
+	if !s.e3.Ready() {
+		return 0
+	}
+	return 3*e1 - 3*e2 + e3
+}
+
+func (s *temaStream) Ready() bool {
+	return s.e3.Ready()
+}
+
+// smoothingWarmup returns the first valid index of a smoothed series whose
+// input becomes valid at offset
+func smoothingWarmup(window int, smoothing Smoothing, offset int) int {
+	window = max(window, 1)
+	switch smoothing {
+	case SmoothingExponential, SmoothingWilder, SmoothingWeighted:
+		return offset + window - 1
+	case SmoothingHull:
+		return offset + window - 1 + max(int(math.Sqrt(float64(window))), 1) - 1
+	case SmoothingDouble:
+		return offset + 2*(window-1)
+	case SmoothingTriple:
+		return offset + 3*(window-1)
+	default:
+		return max(window, offset+window-1)
+	}
+}
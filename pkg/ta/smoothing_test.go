@@ -0,0 +1,34 @@
+package ta_test
+
+import (
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestMovingAverages(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	assertSeries(t, "ExponentialMovingAverage", ta.ExponentialMovingAverage(values, 3), []float64{0, 0, 2, 3, 4, 5, 6, 7})
+	assertSeries(t, "WilderMovingAverage", ta.WilderMovingAverage(values, 3), []float64{0, 0, 2, 8.0 / 3, 31.0 / 9, 4.296296296296297, 5.197530864197531, 6.131687242798354})
+	assertSeries(t, "WeightedMovingAverage", ta.WeightedMovingAverage(values, 3), []float64{0, 0, 14.0 / 6, 20.0 / 6, 26.0 / 6, 32.0 / 6, 38.0 / 6, 44.0 / 6})
+
+	// on a linear series the lag of DEMA, TEMA and HMA is removed entirely
+	assertSeries(t, "DoubleExponentialMovingAverage", ta.DoubleExponentialMovingAverage(values, 3), []float64{0, 0, 0, 0, 5, 6, 7, 8})
+	assertSeries(t, "TripleExponentialMovingAverage", ta.TripleExponentialMovingAverage(values, 3), []float64{0, 0, 0, 0, 0, 0, 7, 8})
+	assertSeries(t, "HullMovingAverage", ta.HullMovingAverage(values, 4), []float64{0, 0, 0, 0, 5, 6, 7, 8})
+}
+
+func TestWilderRSI(t *testing.T) {
+	// closes from Wilder's New Concepts in Technical Trading Systems
+	closes := []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	}
+	rsi := ta.RSI(closes, 14, ta.SmoothingWilder)
+	for i, want := range map[int]float64{14: 70.46, 15: 66.25, 16: 66.48, 17: 69.35, 18: 66.29, 19: 57.92} {
+		if rsi[i] < want-0.01 || rsi[i] > want+0.01 {
+			t.Fatalf("RSI[%d] = %0.4f, want %0.2f", i, rsi[i], want)
+		}
+	}
+}
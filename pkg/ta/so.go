@@ -1,26 +1,45 @@
 package ta
 
-import "math"
-
 func StochasticOscillator(closes, lows, highs []float64, window int) ([]float64, []float64) {
 	kValues := make([]float64, len(closes))
 	dValues := make([]float64, len(closes))
 
+	stream := NewStochasticOscillator(window)
 	for i := range closes {
-		if i < window {
-			kValues[i], dValues[i] = 0, 0
-			continue
-		}
-		low, high := lows[i], highs[i]
-		for j := i - window + 1; j <= i; j++ {
-			low = math.Min(low, lows[j])
-			high = math.Max(high, highs[j])
-		}
-		kValues[i] = 100 * (closes[i] - low) / (high - low)
+		kValues[i], dValues[i] = stream.Update(Candle{High: highs[i], Low: lows[i], Close: closes[i]})
 	}
 
-	// Calculate %D as a 3-period moving average of %K
-	dValues = MovingAverage(kValues, 3)
-
 	return kValues, dValues
 }
+
+// StochasticOscillatorStream is the streaming equivalent of StochasticOscillator
+type StochasticOscillatorStream struct {
+	window int
+	n      int
+	low    *monotonicDeque
+	high   *monotonicDeque
+	d      *MovingAverageStream
+}
+
+func NewStochasticOscillator(window int) *StochasticOscillatorStream {
+	return &StochasticOscillatorStream{
+		window: window,
+		low:    newMinDeque(window),
+		high:   newMaxDeque(window),
+		d:      NewMovingAverage(3),
+	}
+}
+
+// Update returns %K and %D
+func (s *StochasticOscillatorStream) Update(c Candle) (float64, float64) {
+	low := s.low.Push(c.Low)
+	high := s.high.Push(c.High)
+	i := s.n
+	s.n++
+
+	k := 0.0
+	if i >= s.window {
+		k = 100 * (c.Close - low) / (high - low)
+	}
+	return k, s.d.Update(k)
+}
@@ -0,0 +1,326 @@
+package ta
+
+import "math"
+
+// Rolling statistics over a window ending at each value. Values are zero
+// until a full window is available. The volatility estimators are per
+// candle, not annualised.
+
+// LogReturns calculates ln(p[i]/p[i-1]), zero for the first price
+func LogReturns(prices []float64) []float64 {
+	returns := make([]float64, len(prices))
+	for i := 1; i < len(prices); i++ {
+		if prices[i] > 0 && prices[i-1] > 0 {
+			returns[i] = math.Log(prices[i] / prices[i-1])
+		}
+	}
+	return returns
+}
+
+// rollingMean returns the mean of each window of values, from index
+// first+window-1 onwards
+func rollingMean(values []float64, window, first int) []float64 {
+	out := make([]float64, len(values))
+	sum := newRollingSum(window)
+	for i := first; i < len(values); i++ {
+		sum.Push(values[i])
+		if i >= first+window-1 {
+			out[i] = sum.Sum() / float64(window)
+		}
+	}
+	return out
+}
+
+// ParkinsonVolatility estimates volatility from the high-low range
+func ParkinsonVolatility(highs, lows []float64, window int) []float64 {
+	terms := make([]float64, len(highs))
+	for i := range highs {
+		if highs[i] > 0 && lows[i] > 0 {
+			hl := math.Log(highs[i] / lows[i])
+			terms[i] = hl * hl / (4 * math.Ln2)
+		}
+	}
+	return sqrtSeries(rollingMean(terms, window, 0))
+}
+
+// GarmanKlassVolatility estimates volatility from the open, high, low and
+// close, ignoring the gap from the previous close
+func GarmanKlassVolatility(opens, highs, lows, closes []float64, window int) []float64 {
+	terms := make([]float64, len(highs))
+	for i := range highs {
+		if opens[i] > 0 && highs[i] > 0 && lows[i] > 0 && closes[i] > 0 {
+			hl := math.Log(highs[i] / lows[i])
+			co := math.Log(closes[i] / opens[i])
+			terms[i] = 0.5*hl*hl - (2*math.Ln2-1)*co*co
+		}
+	}
+	return sqrtSeries(rollingMean(terms, window, 0))
+}
+
+// YangZhangVolatility combines the overnight (previous close to open),
+// open to close and Rogers-Satchell variances, which makes it robust to
+// both drift and gaps. The first value is at index window as it needs the
+// previous close.
+func YangZhangVolatility(opens, highs, lows, closes []float64, window int) []float64 {
+	out := make([]float64, len(closes))
+	if window < 2 {
+		return out
+	}
+
+	overnight := newRollingMoments(window)
+	openClose := newRollingMoments(window)
+	rs := newRollingSum(window)
+	n := float64(window)
+	k := 0.34 / (1.34 + (n+1)/(n-1))
+
+	for i := 1; i < len(closes); i++ {
+		o, h, l, c, prev := opens[i], highs[i], lows[i], closes[i], closes[i-1]
+		if o <= 0 || h <= 0 || l <= 0 || c <= 0 || prev <= 0 {
+			o, h, l, c, prev = 1, 1, 1, 1, 1
+		}
+		overnight.Push(math.Log(o / prev))
+		openClose.Push(math.Log(c / o))
+		rs.Push(math.Log(h/c)*math.Log(h/o) + math.Log(l/c)*math.Log(l/o))
+
+		if i < window {
+			continue
+		}
+		// sample variances
+		variance := (overnight.Variance()+k*openClose.Variance())*n/(n-1) + (1-k)*rs.Sum()/n
+		out[i] = math.Sqrt(math.Max(0, variance))
+	}
+	return out
+}
+
+func sqrtSeries(values []float64) []float64 {
+	for i, v := range values {
+		values[i] = math.Sqrt(math.Max(0, v))
+	}
+	return values
+}
+
+// ZScore calculates how many standard deviations each value is from the
+// mean of its window, zero when the window is flat
+func ZScore(values []float64, window int) []float64 {
+	out := make([]float64, len(values))
+	moments := newRollingMoments(window)
+	for i, v := range values {
+		moments.Push(v)
+		if i < window-1 {
+			continue
+		}
+		if std := math.Sqrt(moments.Variance()); std > 0 {
+			out[i] = (v - moments.Mean()) / std
+		}
+	}
+	return out
+}
+
+// windowMoments returns the mean and second to fourth central moments of
+// values, computed in two passes to avoid cancellation
+func windowMoments(values []float64) (mean, m2, m3, m4 float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		d := v - mean
+		d2 := d * d
+		m2 += d2
+		m3 += d2 * d
+		m4 += d2 * d2
+	}
+	n := float64(len(values))
+	return mean, m2 / n, m3 / n, m4 / n
+}
+
+// Skewness calculates the skewness of each window, zero when it's flat
+func Skewness(values []float64, window int) []float64 {
+	out := make([]float64, len(values))
+	for i := window - 1; i < len(values); i++ {
+		_, m2, m3, _ := windowMoments(values[i-window+1 : i+1])
+		if m2 > 0 {
+			out[i] = m3 / math.Pow(m2, 1.5)
+		}
+	}
+	return out
+}
+
+// Kurtosis calculates the excess kurtosis of each window, zero when it's
+// flat
+func Kurtosis(values []float64, window int) []float64 {
+	out := make([]float64, len(values))
+	for i := window - 1; i < len(values); i++ {
+		_, m2, _, m4 := windowMoments(values[i-window+1 : i+1])
+		if m2 > 0 {
+			out[i] = m4/(m2*m2) - 3
+		}
+	}
+	return out
+}
+
+// Autocorrelation calculates the correlation of each window with itself lag
+// values earlier
+func Autocorrelation(values []float64, window, lag int) []float64 {
+	out := make([]float64, len(values))
+	if lag < 1 || window <= lag {
+		return out
+	}
+	for i := window - 1; i < len(values); i++ {
+		w := values[i-window+1 : i+1]
+		mean, m2, _, _ := windowMoments(w)
+		if m2 == 0 {
+			continue
+		}
+		cov := 0.0
+		for j := lag; j < len(w); j++ {
+			cov += (w[j] - mean) * (w[j-lag] - mean)
+		}
+		out[i] = cov / (m2 * float64(len(w)))
+	}
+	return out
+}
+
+// Hurst estimates the Hurst exponent of the prices in each window from how
+// the spread of k-candle log returns grows with k, for k = 1, 2, 4 ... up to
+// an eighth of the window. 0.5 is a random walk, above it trending and below
+// mean reverting. Windows too short for two scales are zero.
+func Hurst(prices []float64, window int) []float64 {
+	out := make([]float64, len(prices))
+	logs := make([]float64, len(prices))
+	for i, p := range prices {
+		if p > 0 {
+			logs[i] = math.Log(p)
+		}
+	}
+
+	scales := []int{}
+	for k := 1; k <= window/8; k *= 2 {
+		scales = append(scales, k)
+	}
+	if len(scales) < 2 {
+		return out
+	}
+
+	x := make([]float64, len(scales))
+	for j, k := range scales {
+		x[j] = math.Log(float64(k))
+	}
+	y := make([]float64, len(scales))
+	diffs := make([]float64, 0, window)
+
+	for i := window - 1; i < len(prices); i++ {
+		valid := true
+		for j, k := range scales {
+			diffs = diffs[:0]
+			for t := i - window + 1 + k; t <= i; t++ {
+				diffs = append(diffs, logs[t]-logs[t-k])
+			}
+			_, m2, _, _ := windowMoments(diffs)
+			if m2 <= 0 {
+				valid = false
+				break
+			}
+			y[j] = 0.5 * math.Log(m2)
+		}
+		if valid {
+			out[i] = slope(x, y)
+		}
+	}
+	return out
+}
+
+// slope returns the least squares slope of y against x
+func slope(x, y []float64) float64 {
+	mx, my := 0.0, 0.0
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(len(x))
+	my /= float64(len(y))
+	num, den := 0.0, 0.0
+	for i := range x {
+		num += (x[i] - mx) * (y[i] - my)
+		den += (x[i] - mx) * (x[i] - mx)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+// SampleEntropy calculates the sample entropy of each window, the negative
+// log of the chance that sequences matching for m values within tolerance
+// also match on the next. Low values are regular, high values noisy. The
+// tolerance is absolute, so values should be standardised first. Match
+// counts are updated as templates enter and leave the window, O(window·m)
+// per value. A window with no matches of length m+1 gets the upper bound
+// ln(matches of length m).
+func SampleEntropy(values []float64, window, m int, tolerance float64) []float64 {
+	out := make([]float64, len(values))
+	if m < 1 || window <= m+1 {
+		return out
+	}
+
+	// match returns the length of the matching prefix of the templates
+	// starting at a and b, up to m+1
+	match := func(a, b int) int {
+		for k := 0; k <= m; k++ {
+			if math.Abs(values[a+k]-values[b+k]) > tolerance {
+				return k
+			}
+		}
+		return m + 1
+	}
+
+	// templates of length m+1 starting in [first, last] fit in the window
+	first, last := 0, -1
+	shorter, longer := 0, 0
+	count := func(a, sign int) {
+		for b := first; b <= last; b++ {
+			if b == a {
+				continue
+			}
+			if l := match(a, b); l >= m {
+				shorter += sign
+				if l > m {
+					longer += sign
+				}
+			}
+		}
+	}
+
+	for i := m; i < len(values); i++ {
+		// the template ending at i enters the window
+		last = i - m
+		count(last, 1)
+		// the template starting before the window leaves it
+		if start := i - window + 1; start > first {
+			count(first, -1)
+			first++
+		}
+
+		if i < window-1 || shorter == 0 {
+			continue
+		}
+		pairs := float64(longer)
+		if longer == 0 {
+			pairs = 1
+		}
+		out[i] = -math.Log(pairs / float64(shorter))
+	}
+	return out
+}
+
+// AmihudIlliquidity calculates the mean absolute return per unit of traded
+// value over each window. Candles without volume add nothing.
+func AmihudIlliquidity(closes, volumes []float64, window int) []float64 {
+	terms := make([]float64, len(closes))
+	for i := 1; i < len(closes); i++ {
+		if value := closes[i] * volumes[i]; value > 0 && closes[i-1] > 0 {
+			terms[i] = math.Abs(closes[i]/closes[i-1]-1) / value
+		}
+	}
+	return rollingMean(terms, window, 1)
+}
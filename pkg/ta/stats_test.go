@@ -0,0 +1,137 @@
+package ta_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+// naiveSampleEntropy counts template matches from scratch for each window
+func naiveSampleEntropy(values []float64, window, m int, tolerance float64) []float64 {
+	out := make([]float64, len(values))
+	for i := window - 1; i < len(values); i++ {
+		w := values[i-window+1 : i+1]
+		shorter, longer := 0, 0
+		for a := 0; a+m < len(w); a++ {
+			for b := a + 1; b+m < len(w); b++ {
+				l := 0
+				for l <= m && math.Abs(w[a+l]-w[b+l]) <= tolerance {
+					l++
+				}
+				if l >= m {
+					shorter++
+				}
+				if l > m {
+					longer++
+				}
+			}
+		}
+		if shorter == 0 {
+			continue
+		}
+		out[i] = -math.Log(math.Max(1, float64(longer)) / float64(shorter))
+	}
+	return out
+}
+
+func TestSampleEntropyMatchesNaive(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]float64, 500)
+	for i := range values {
+		values[i] = r.NormFloat64()
+	}
+
+	for _, m := range []int{1, 2, 3} {
+		got := ta.SampleEntropy(values, 50, m, 0.3)
+		want := naiveSampleEntropy(values, 50, m, 0.3)
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1e-12 {
+				t.Fatalf("m=%d index %d: got %v, want %v", m, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	n, window := 5000, 256
+	prices := make([]float64, n)
+	trend := make([]float64, n)
+	alternating := make([]float64, n)
+	prices[0], trend[0] = 100, 100
+	persistent := 0.0
+	for i := 1; i < n; i++ {
+		prices[i] = prices[i-1] * math.Exp(0.001*r.NormFloat64())
+		persistent = 0.8*persistent + 0.001*r.NormFloat64()
+		trend[i] = trend[i-1] * math.Exp(persistent)
+		alternating[i] = float64(i%2*2 - 1)
+	}
+
+	if h := ta.Hurst(prices, window)[n-1]; math.Abs(h-0.5) > 0.15 {
+		t.Errorf("random walk Hurst %v, want about 0.5", h)
+	}
+	if h := ta.Hurst(trend, window)[n-1]; h < 0.65 {
+		t.Errorf("persistent Hurst %v, want above 0.5", h)
+	}
+	if ac := ta.Autocorrelation(alternating, window, 1)[n-1]; ac > -0.99 {
+		t.Errorf("alternating autocorrelation %v, want -1", ac)
+	}
+	if z := ta.ZScore(alternating, window)[n-1]; math.Abs(math.Abs(z)-1) > 1e-9 {
+		t.Errorf("alternating z-score %v, want ±1", z)
+	}
+	if s := ta.Skewness(alternating, window)[n-1]; math.Abs(s) > 1e-9 {
+		t.Errorf("alternating skewness %v, want 0", s)
+	}
+	if k := ta.Kurtosis(alternating, window)[n-1]; math.Abs(k+2) > 1e-9 {
+		t.Errorf("alternating kurtosis %v, want -2", k)
+	}
+
+	// every estimator should recover the volatility of a gapless random
+	// walk sampled within each candle
+	const sigma = 0.001
+	opens, highs, lows, closes := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	price := 100.0
+	for i := range closes {
+		opens[i], highs[i], lows[i] = price, price, price
+		for j := 0; j < 100; j++ {
+			price *= math.Exp(sigma / 10 * r.NormFloat64())
+			highs[i] = math.Max(highs[i], price)
+			lows[i] = math.Min(lows[i], price)
+		}
+		closes[i] = price
+	}
+	for name, vol := range map[string][]float64{
+		"parkinson":    ta.ParkinsonVolatility(highs, lows, n-1),
+		"garman_klass": ta.GarmanKlassVolatility(opens, highs, lows, closes, n-1),
+		"yang_zhang":   ta.YangZhangVolatility(opens, highs, lows, closes, n-1),
+	} {
+		// discrete sampling understates the range slightly
+		if v := vol[n-1]; math.Abs(v-sigma)/sigma > 0.15 {
+			t.Errorf("%s volatility %v, want about %v", name, v, sigma)
+		}
+		if vol[n-3] != 0 {
+			t.Errorf("%s should be zero before a full window", name)
+		}
+	}
+}
+
+func TestCorrelationAndBeta(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	x, y := make([]float64, 200), make([]float64, 200)
+	for i := range y {
+		y[i] = r.NormFloat64()
+		x[i] = 2*y[i] + 1
+	}
+
+	if b := ta.Beta(x, y, 50)[199]; math.Abs(b-2) > 1e-9 {
+		t.Errorf("beta %v, want 2", b)
+	}
+	if c := ta.Correlation(x, y, 50)[199]; math.Abs(c-1) > 1e-9 {
+		t.Errorf("correlation %v, want 1", c)
+	}
+	if c := ta.Correlation(ta.Lag(x, 1), y, 50)[199]; math.Abs(c) > 0.5 {
+		t.Errorf("lagged correlation of noise %v, want about 0", c)
+	}
+}
@@ -0,0 +1,71 @@
+package ta_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func TestStreamsMatchBatch(t *testing.T) {
+	candles := randomCandles(3000, 1)
+	_, highs, lows, closes, volumes := columns(candles)
+
+	stream := func(update func(c ta.Candle) float64) []float64 {
+		out := make([]float64, len(candles))
+		for i, c := range candles {
+			out[i] = update(c)
+		}
+		return out
+	}
+
+	ma := ta.NewMovingAverage(20)
+	assertSeries(t, "MovingAverage", stream(func(c ta.Candle) float64 { return ma.Update(c.Close) }), ta.MovingAverage(closes, 20))
+	assertSeries(t, "OBV", stream(ta.NewOBV().Update), ta.OBV(closes, volumes))
+	assertSeries(t, "CCI", stream(ta.NewCCI(20).Update), ta.CCI(highs, lows, closes, 20))
+	assertSeries(t, "WilliamsR", stream(ta.NewWilliamsR(14).Update), ta.WilliamsR(highs, lows, closes, 14))
+	assertSeries(t, "ChaikinMoneyFlow", stream(ta.NewChaikinMoneyFlow(20).Update), ta.ChaikinMoneyFlow(highs, lows, closes, volumes, 20))
+	assertSeries(t, "MoneyFlowIndex", stream(ta.NewMoneyFlowIndex(14).Update), ta.MoneyFlowIndex(highs, lows, closes, volumes, 14))
+	assertSeries(t, "RateOfChange", stream(ta.NewRateOfChange(14).Update), ta.RateOfChange(closes, 14))
+	assertSeries(t, "PriceChanges", stream(ta.NewPriceChanges(60).Update), ta.PriceChanges(closes, 60))
+	assertSeries(t, "VWAP", stream(ta.NewVWAP().Update), ta.VWAP(closes, volumes))
+
+	for _, smoothing := range ta.Smoothings {
+		name := func(indicator string) string {
+			return fmt.Sprintf("%s (%s)", indicator, smoothing)
+		}
+
+		smoother := ta.NewSmoother(20, smoothing)
+		assertSeries(t, name("Smooth"), stream(func(c ta.Candle) float64 { return smoother.Update(c.Close) }), ta.Smooth(closes, 20, smoothing))
+		assertSeries(t, name("RSI"), stream(ta.NewRSI(14, smoothing).Update), ta.RSI(closes, 14, smoothing))
+		assertSeries(t, name("ATR"), stream(ta.NewATR(14, smoothing).Update), ta.ATR(highs, lows, closes, 14, smoothing))
+
+		macdStream := ta.NewMACD(12, 26, 9, smoothing)
+		macd, signal := make([]float64, len(candles)), make([]float64, len(candles))
+		for i, c := range candles {
+			macd[i], signal[i] = macdStream.Update(c)
+		}
+		wantMACD, wantSignal := ta.MACD(closes, 12, 26, 9, smoothing)
+		assertSeries(t, name("MACD"), macd, wantMACD)
+		assertSeries(t, name("MACD signal"), signal, wantSignal)
+
+		bbStream := ta.NewBollingerBands(20, 2, smoothing)
+		middle, upper, lower := make([]float64, len(candles)), make([]float64, len(candles)), make([]float64, len(candles))
+		for i, c := range candles {
+			middle[i], upper[i], lower[i] = bbStream.Update(c)
+		}
+		wantMiddle, wantUpper, wantLower := ta.BollingerBands(closes, 20, 2, smoothing)
+		assertSeries(t, name("BollingerBands middle"), middle, wantMiddle)
+		assertSeries(t, name("BollingerBands upper"), upper, wantUpper)
+		assertSeries(t, name("BollingerBands lower"), lower, wantLower)
+	}
+
+	soStream := ta.NewStochasticOscillator(14)
+	k, d := make([]float64, len(candles)), make([]float64, len(candles))
+	for i, c := range candles {
+		k[i], d[i] = soStream.Update(c)
+	}
+	wantK, wantD := ta.StochasticOscillator(closes, lows, highs, 14)
+	assertSeries(t, "StochasticOscillator %K", k, wantK)
+	assertSeries(t, "StochasticOscillator %D", d, wantD)
+}
@@ -0,0 +1,42 @@
+package ta
+
+// SuperTrend calculates the SuperTrend line from Wilder's ATR. The second
+// slice holds the trend direction, 1 for long and -1 for short.
+func SuperTrend(highs, lows, closes []float64, period int, multiplier float64) ([]float64, []float64) {
+	supertrend, direction := make([]float64, len(closes)), make([]float64, len(closes))
+	atr := ATR(highs, lows, closes, period, SmoothingWilder)
+	first := max(period, 1) - 1
+
+	var upper, lower float64
+	long := true
+	for i := first; i < len(closes); i++ {
+		hl2 := (highs[i] + lows[i]) / 2
+		basicUpper := hl2 + multiplier*atr[i]
+		basicLower := hl2 - multiplier*atr[i]
+
+		if i == first {
+			upper, lower = basicUpper, basicLower
+			long = closes[i] >= hl2
+		} else {
+			if basicUpper < upper || closes[i-1] > upper {
+				upper = basicUpper
+			}
+			if basicLower > lower || closes[i-1] < lower {
+				lower = basicLower
+			}
+			if long && closes[i] < lower {
+				long = false
+			} else if !long && closes[i] > upper {
+				long = true
+			}
+		}
+
+		if long {
+			supertrend[i], direction[i] = lower, 1
+		} else {
+			supertrend[i], direction[i] = upper, -1
+		}
+	}
+
+	return supertrend, direction
+}
@@ -0,0 +1,72 @@
+package ta_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+// randomCandles generates a reproducible random walk of 1m candles
+func randomCandles(n int, seed int64) []ta.Candle {
+	r := rand.New(rand.NewSource(seed))
+	out := make([]ta.Candle, n)
+	price := 0.3
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range out {
+		open := price
+		price *= 1 + (r.Float64()*2-1)*0.002
+		high := math.Max(open, price) * (1 + r.Float64()*0.001)
+		low := math.Min(open, price) * (1 - r.Float64()*0.001)
+		volume := 1000 + r.Float64()*5000
+		if i%97 == 0 {
+			// flat candles exercise the zero-range edge cases
+			open, high, low = price, price, price
+		}
+		out[i] = ta.Candle{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     price,
+			Volume:    volume,
+		}
+	}
+	return out
+}
+
+func columns(candles []ta.Candle) (opens, highs, lows, closes, volumes []float64) {
+	opens = make([]float64, len(candles))
+	highs = make([]float64, len(candles))
+	lows = make([]float64, len(candles))
+	closes = make([]float64, len(candles))
+	volumes = make([]float64, len(candles))
+	for i, c := range candles {
+		opens[i], highs[i], lows[i], closes[i], volumes[i] = c.Open, c.High, c.Low, c.Close, c.Volume
+	}
+	return
+}
+
+func almostEqual(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return a == b
+	}
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func assertSeries(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: length %d, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if !almostEqual(got[i], want[i]) {
+			t.Fatalf("%s: index %d got %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
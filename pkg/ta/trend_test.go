@@ -0,0 +1,61 @@
+package ta_test
+
+import (
+	"testing"
+
+	"github.com/grexie/signals/pkg/ta"
+)
+
+func trendingSeries(n int, slope float64) (highs, lows, closes []float64) {
+	highs, lows, closes = make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range n {
+		closes[i] = 100 + slope*float64(i)
+		highs[i] = closes[i] + 0.5
+		lows[i] = closes[i] - 0.5
+	}
+	return
+}
+
+func TestDonchianChannels(t *testing.T) {
+	highs := []float64{3, 5, 4, 2, 6}
+	lows := []float64{1, 2, 0, 1, 3}
+	upper, middle, lower := ta.DonchianChannels(highs, lows, 3)
+	assertSeries(t, "upper", upper, []float64{0, 0, 5, 5, 6})
+	assertSeries(t, "middle", middle, []float64{0, 0, 2.5, 2.5, 3})
+	assertSeries(t, "lower", lower, []float64{0, 0, 0, 0, 0})
+}
+
+func TestTrendIndicatorsFollowTrend(t *testing.T) {
+	for _, slope := range []float64{1, -1} {
+		highs, lows, closes := trendingSeries(200, slope)
+		last := len(closes) - 1
+
+		adx, plusDI, minusDI := ta.ADX(highs, lows, closes, 14)
+		if adx[last] < 50 {
+			t.Fatalf("slope %v: ADX %0.2f should show a strong trend", slope, adx[last])
+		}
+		if (plusDI[last] > minusDI[last]) != (slope > 0) {
+			t.Fatalf("slope %v: +DI %0.2f, -DI %0.2f", slope, plusDI[last], minusDI[last])
+		}
+
+		sar, sarTrend := ta.ParabolicSAR(highs, lows, 0.02, 0.2)
+		if sarTrend[last] != slope || (sar[last] < lows[last]) != (slope > 0) {
+			t.Fatalf("slope %v: SAR %0.2f trend %v at close %0.2f", slope, sar[last], sarTrend[last], closes[last])
+		}
+
+		supertrend, direction := ta.SuperTrend(highs, lows, closes, 10, 3)
+		if direction[last] != slope || (supertrend[last] < closes[last]) != (slope > 0) {
+			t.Fatalf("slope %v: SuperTrend %0.2f direction %v at close %0.2f", slope, supertrend[last], direction[last], closes[last])
+		}
+
+		_, _, spanA, spanB := ta.Ichimoku(highs, lows, 9, 26, 52)
+		if (closes[last] > spanA[last] && closes[last] > spanB[last]) != (slope > 0) {
+			t.Fatalf("slope %v: close %0.2f should be on the trend side of the cloud %0.2f/%0.2f", slope, closes[last], spanA[last], spanB[last])
+		}
+
+		middle, upper, lower := ta.KeltnerChannels(highs, lows, closes, 20, 2)
+		if !(lower[last] < middle[last] && middle[last] < upper[last]) {
+			t.Fatalf("slope %v: Keltner channels out of order %0.2f %0.2f %0.2f", slope, lower[last], middle[last], upper[last])
+		}
+	}
+}
@@ -15,3 +15,22 @@ func VWAP(closes, volumes []float64) []float64 {
 	}
 	return vwap
 }
+
+// VWAPStream is the streaming equivalent of VWAP
+type VWAPStream struct {
+	cumulativeVolume float64
+	cumulativeValue  float64
+}
+
+func NewVWAP() *VWAPStream {
+	return &VWAPStream{}
+}
+
+func (s *VWAPStream) Update(c Candle) float64 {
+	s.cumulativeVolume += c.Volume
+	s.cumulativeValue += c.Close * c.Volume
+	if s.cumulativeVolume == 0 {
+		return 0
+	}
+	return s.cumulativeValue / s.cumulativeVolume
+}
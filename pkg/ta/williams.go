@@ -2,20 +2,32 @@ package ta
 
 func WilliamsR(highs, lows, closes []float64, period int) []float64 {
 	williamsr := make([]float64, len(closes))
-	for i := period; i < len(closes); i++ {
-		highest := highs[i]
-		lowest := lows[i]
-		for j := i - period + 1; j <= i; j++ {
-			if highs[j] > highest {
-				highest = highs[j]
-			}
-			if lows[j] < lowest {
-				lowest = lows[j]
-			}
-		}
-		if highest != lowest {
-			williamsr[i] = ((highest - closes[i]) / (highest - lowest)) * -100
-		}
+	stream := NewWilliamsR(period)
+	for i := range closes {
+		williamsr[i] = stream.Update(Candle{High: highs[i], Low: lows[i], Close: closes[i]})
 	}
 	return williamsr
 }
+
+// WilliamsRStream is the streaming equivalent of WilliamsR
+type WilliamsRStream struct {
+	period int
+	n      int
+	low    *monotonicDeque
+	high   *monotonicDeque
+}
+
+func NewWilliamsR(period int) *WilliamsRStream {
+	return &WilliamsRStream{period: period, low: newMinDeque(period), high: newMaxDeque(period)}
+}
+
+func (s *WilliamsRStream) Update(c Candle) float64 {
+	lowest := s.low.Push(c.Low)
+	highest := s.high.Push(c.High)
+	i := s.n
+	s.n++
+	if i < s.period || highest == lowest {
+		return 0
+	}
+	return ((highest - c.Close) / (highest - lowest)) * -100
+}